@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/duolacloud/microbase/multitenancy"
+	breflect "github.com/duolacloud/microbase/reflect"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Operation 写操作的类型
+type Operation string
+
+const (
+	OperationCreate     Operation = "CREATE"
+	OperationUpdate     Operation = "UPDATE"
+	OperationUpsert     Operation = "UPSERT"
+	OperationDelete     Operation = "DELETE"      // 软删除或者没有软删除字段时的删除
+	OperationRestore    Operation = "RESTORE"     // 恢复软删除的记录
+	OperationHardDelete Operation = "HARD_DELETE" // 物理删除
+)
+
+// Change 一个字段的变化, 字段名为 json 名称, 新建时 Before 为空, 删除时 After 为空
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Changes 字段的变化, 在数据库中按 json 存储为一列
+type Changes []*Change
+
+func (c Changes) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *Changes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return errors.New(fmt.Sprintf("unexpected changes value %v", src))
+	}
+}
+
+// Record 一条审计记录, 和普通实体一样通过 repository.BaseRepository 存储,
+// 使用 Connection 按 ctime 分页查询, 例如按 entityType 和 entityId 过滤查询一个实体的变更历史
+type Record struct {
+	ID         string    `json:"id" gorm:"primary_key;size:36" bson:"id"`
+	Operation  Operation `json:"op" gorm:"column:op;size:16" bson:"op"`
+	EntityType string    `json:"entityType" gorm:"size:64;index:idx_audit_entity" bson:"entityType"`
+	EntityID   string    `json:"entityId" gorm:"size:255;index:idx_audit_entity" bson:"entityId"`
+	TenantID   string    `json:"tenantId" gorm:"size:64" bson:"tenantId"`
+	Actor      string    `json:"actor" gorm:"size:128;index" bson:"actor"`
+	Filter     string    `json:"filter,omitempty" gorm:"type:text" bson:"filter,omitempty"` // 批量更新和删除的过滤条件
+	Changes    Changes   `json:"changes" gorm:"type:text" bson:"changes"`
+	CreateTime time.Time `json:"ctime" gorm:"column:ctime;index" bson:"ctime"`
+}
+
+func (r *Record) TableName() string {
+	return "audit_records"
+}
+
+func (r *Record) Unique() interface{} {
+	return map[string]interface{}{
+		"id": r.ID,
+	}
+}
+
+// NewRecord 从 ctx 中获取租户和操作人
+func NewRecord(ctx context.Context, op Operation, entityType, entityID string, changes Changes) *Record {
+	tenantId, _ := multitenancy.FromContext(ctx)
+
+	return &Record{
+		ID:         uuid.NewV4().String(),
+		Operation:  op,
+		EntityType: entityType,
+		EntityID:   entityID,
+		TenantID:   tenantId,
+		Actor:      ActorFromContext(ctx),
+		Changes:    changes,
+		CreateTime: time.Now(),
+	}
+}
+
+// Recorder 保存审计记录
+type Recorder interface {
+	Record(ctx context.Context, records ...*Record) error
+}
+
+// EntityType 实体类型名称, 与缓存和索引使用的命名一致, 例如 User 为 users
+func EntityType(m interface{}) string {
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return breflect.TheNamingStrategy.Table(t.Name())
+}
+
+// EntityID 主键只有一个字段时为字段的值, 否则为按字段名排序的 json
+func EntityID(keys map[string]interface{}) string {
+	if len(keys) == 1 {
+		for _, v := range keys {
+			return fmt.Sprint(v)
+		}
+	}
+
+	// json 序列化 map 时按 key 排序
+	b, _ := json.Marshal(keys)
+	return string(b)
+}
+
+// Diff 比较两个版本的字段, 只返回有变化的字段, 按字段名排序; before 为空表示新建, after 为空表示删除
+func Diff(before, after map[string]interface{}) Changes {
+	names := make(map[string]bool, len(before)+len(after))
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	fields := make([]string, 0, len(names))
+	for name := range names {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	changes := make(Changes, 0)
+	for _, field := range fields {
+		b, a := before[field], after[field]
+		if equal(b, a) {
+			continue
+		}
+		changes = append(changes, &Change{Field: field, Before: b, After: a})
+	}
+	return changes
+}
+
+// equal 时间按时刻比较, 数据库读出的时间和写入的时间时区可能不同
+func equal(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Equal(tb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Fields 实体按 json 序列化后的字段, 用于比较没有钩子的后端写入前后的实体
+func Fields(m interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
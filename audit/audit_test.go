@@ -0,0 +1,61 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duolacloud/microbase/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+type UserProfile struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+
+	changes := audit.Diff(map[string]interface{}{
+		"id":    1,
+		"name":  "tom",
+		"age":   18,
+		"utime": now,
+	}, map[string]interface{}{
+		"id":    1,
+		"name":  "jerry",
+		"utime": now.UTC(),
+		"email": "jerry@example.com",
+	})
+
+	assert.Equal(t, audit.Changes{
+		{Field: "age", Before: 18, After: nil},
+		{Field: "email", Before: nil, After: "jerry@example.com"},
+		{Field: "name", Before: "tom", After: "jerry"},
+	}, changes)
+
+	assert.Empty(t, audit.Diff(map[string]interface{}{"id": 1}, map[string]interface{}{"id": 1}))
+}
+
+func TestChanges(t *testing.T) {
+	changes := audit.Changes{{Field: "name", Before: "tom", After: "jerry"}}
+
+	v, err := changes.Value()
+	assert.NoError(t, err)
+
+	var scanned audit.Changes
+	assert.NoError(t, scanned.Scan([]byte(v.(string))))
+	assert.Equal(t, changes, scanned)
+}
+
+func TestRecord(t *testing.T) {
+	assert.Equal(t, "1", audit.EntityID(map[string]interface{}{"id": 1}))
+	assert.Equal(t, `{"a":1,"b":"x"}`, audit.EntityID(map[string]interface{}{"b": "x", "a": 1}))
+	assert.Equal(t, "user_profiles", audit.EntityType(&UserProfile{}))
+
+	ctx := audit.WithActor(context.Background(), "job:cleanup")
+	record := audit.NewRecord(ctx, audit.OperationUpdate, "user_profiles", "1", nil)
+	assert.Equal(t, "job:cleanup", record.Actor)
+	assert.NotEmpty(t, record.ID)
+}
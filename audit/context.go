@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/auth"
+)
+
+type actorKey struct{}
+
+// WithActor 指定操作人, 例如后台任务没有登录账号时使用任务名
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 操作人, 优先使用 WithActor 指定的值, 否则为 go-micro auth 认证的账号
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+
+	if account, ok := auth.AccountFromContext(ctx); ok && account != nil {
+		return account.ID
+	}
+	return ""
+}
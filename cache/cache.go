@@ -1,5 +1,12 @@
 package cache
 
+import (
+	"context"
+)
+
+// Loader 缓存未命中时加载数据, 返回值会被写入缓存
+type Loader func(ctx context.Context) (interface{}, error)
+
 type Cache interface {
 	Init(opts ...Option) error
 	Options() Options
@@ -7,4 +14,17 @@ type Cache interface {
 	Set(key string, value interface{}, opts ...WriteOption) error
 	Delete(key string, opts ...DeleteOption) error
 	Exists(key string) bool
+	// GetOrLoad 读取缓存, 未命中时调用 loader 加载并写入缓存
+	// 同一个 key 的并发加载会被合并, 只有一个 loader 会被执行
+	GetOrLoad(ctx context.Context, key string, resultPtr interface{}, loader Loader, opts ...WriteOption) error
+	// MGet 批量读取, resultPtr 为 *map[string]T, 只有命中的 key 会被写入
+	MGet(keys []string, resultPtr interface{}, opts ...ReadOption) error
+	// MSet 批量写入
+	MSet(values map[string]interface{}, opts ...WriteOption) error
+	// DeleteByPrefix 删除所有以 prefix 开头的 key
+	DeleteByPrefix(prefix string) error
+	// InvalidateTags 删除所有带有这些标签的 key, 标签通过 WriteTags 设置
+	InvalidateTags(tags ...string) error
+
+	ContextCache
 }
@@ -0,0 +1,8 @@
+package cache
+
+// Codec 缓存值的编解码
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	String() string
+}
@@ -0,0 +1,31 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/duolacloud/microbase/cache"
+)
+
+type gobCodec struct{}
+
+// NewGobCodec gob 编解码, 接口类型的值需要先 gob.Register
+func NewGobCodec() cache.Codec {
+	return &gobCodec{}
+}
+
+func (c *gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (c *gobCodec) String() string {
+	return "gob"
+}
@@ -0,0 +1,29 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/duolacloud/microbase/cache"
+)
+
+type jsonCodec struct{}
+
+// NewJSONCodec json 编解码, 数字解码为 json.Number 以免 int64 丢失精度
+func NewJSONCodec() cache.Codec {
+	return &jsonCodec{}
+}
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func (c *jsonCodec) String() string {
+	return "json"
+}
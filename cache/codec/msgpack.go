@@ -0,0 +1,35 @@
+package codec
+
+import (
+	"bytes"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackCodec struct{}
+
+// NewMsgpackCodec msgpack 编解码, 保留 time.Time 精度和整数类型, 字段名沿用 json tag
+func NewMsgpackCodec() cache.Codec {
+	return &msgpackCodec{}
+}
+
+func (c *msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+func (c *msgpackCodec) String() string {
+	return "msgpack"
+}
@@ -0,0 +1,53 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/golang/protobuf/proto"
+)
+
+type protoCodec struct{}
+
+// NewProtoCodec protobuf 编解码, 值必须是 proto.Message
+func NewProtoCodec() cache.Codec {
+	return &protoCodec{}
+}
+
+func (c *protoCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("proto codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal 支持 *T 和 **T 两种形式, T 为 proto 消息
+func (c *protoCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("proto codec: %T is not a proto.Message pointer", v)
+	}
+
+	elem := reflect.New(rv.Elem().Type().Elem())
+	m, ok := elem.Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("proto codec: %T is not a proto.Message pointer", v)
+	}
+
+	if err := proto.Unmarshal(data, m); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(elem)
+	return nil
+}
+
+func (c *protoCodec) String() string {
+	return "proto"
+}
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/duolacloud/microbase/multitenancy"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// ContextCache 接受 context 的缓存操作
+// ctx 结束后不再执行操作; 存在 opentracing span 时记录子 span;
+// 开启 WithTenantScope 时 key 和标签按 multitenancy.FromContext 的租户隔离
+type ContextCache interface {
+	GetContext(ctx context.Context, key string, resultPtr interface{}, opts ...ReadOption) bool
+	SetContext(ctx context.Context, key string, value interface{}, opts ...WriteOption) error
+	DeleteContext(ctx context.Context, key string, opts ...DeleteOption) error
+	ExistsContext(ctx context.Context, key string) bool
+	GetOrLoadContext(ctx context.Context, key string, resultPtr interface{}, loader Loader, opts ...WriteOption) error
+	MGetContext(ctx context.Context, keys []string, resultPtr interface{}, opts ...ReadOption) error
+	MSetContext(ctx context.Context, values map[string]interface{}, opts ...WriteOption) error
+	DeleteByPrefixContext(ctx context.Context, prefix string) error
+	InvalidateTagsContext(ctx context.Context, tags ...string) error
+}
+
+// Contextual 基于 Cache 的方法实现 ContextCache, 后端嵌入它并在构造时设置 Cache
+type Contextual struct {
+	Cache Cache
+}
+
+// scope 开启租户隔离时为 key 加上租户前缀
+func (c Contextual) scope(ctx context.Context, key string) string {
+	if !c.Cache.Options().TenantScoped {
+		return key
+	}
+
+	tenantId, _ := multitenancy.FromContext(ctx)
+	if tenantId == "" {
+		return key
+	}
+	return fmt.Sprintf("%s:%s", tenantId, key)
+}
+
+// scopeTags 写入时标签同样按租户隔离
+func (c Contextual) scopeTags(ctx context.Context, opts []WriteOption) []WriteOption {
+	return append(opts, func(o *WriteOptions) {
+		for i, tag := range o.Tags {
+			o.Tags[i] = c.scope(ctx, tag)
+		}
+	})
+}
+
+// startSpan 在 ctx 已有 span 时创建子 span, 否则返回 nil
+func (c Contextual) startSpan(ctx context.Context, operation string, key string) opentracing.Span {
+	parent := opentracing.SpanFromContext(ctx)
+	if parent == nil {
+		return nil
+	}
+
+	sp := parent.Tracer().StartSpan(fmt.Sprintf("cache.%s", operation), opentracing.ChildOf(parent.Context()))
+	ext.DBType.Set(sp, "cache")
+	if s, ok := c.Cache.(fmt.Stringer); ok {
+		sp.SetTag("cache.driver", s.String())
+	}
+	sp.SetTag("cache.key", key)
+	return sp
+}
+
+func finishSpan(sp opentracing.Span, err error) {
+	if sp == nil {
+		return
+	}
+	if err != nil {
+		ext.Error.Set(sp, true)
+		sp.SetTag("cache.err", err.Error())
+	}
+	sp.Finish()
+}
+
+func finishSpanHit(sp opentracing.Span, hit bool) {
+	if sp == nil {
+		return
+	}
+	sp.SetTag("cache.hit", hit)
+	sp.Finish()
+}
+
+func (c Contextual) GetContext(ctx context.Context, key string, resultPtr interface{}, opts ...ReadOption) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	key = c.scope(ctx, key)
+	sp := c.startSpan(ctx, "get", key)
+	ok := c.Cache.Get(key, resultPtr, append(opts, ReadContext(ctx))...)
+	finishSpanHit(sp, ok)
+	return ok
+}
+
+func (c Contextual) SetContext(ctx context.Context, key string, value interface{}, opts ...WriteOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	key = c.scope(ctx, key)
+	sp := c.startSpan(ctx, "set", key)
+	err := c.Cache.Set(key, value, c.scopeTags(ctx, opts)...)
+	finishSpan(sp, err)
+	return err
+}
+
+func (c Contextual) DeleteContext(ctx context.Context, key string, opts ...DeleteOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	key = c.scope(ctx, key)
+	sp := c.startSpan(ctx, "delete", key)
+	err := c.Cache.Delete(key, opts...)
+	finishSpan(sp, err)
+	return err
+}
+
+func (c Contextual) ExistsContext(ctx context.Context, key string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	key = c.scope(ctx, key)
+	sp := c.startSpan(ctx, "exists", key)
+	ok := c.Cache.Exists(key)
+	finishSpanHit(sp, ok)
+	return ok
+}
+
+func (c Contextual) GetOrLoadContext(ctx context.Context, key string, resultPtr interface{}, loader Loader, opts ...WriteOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	key = c.scope(ctx, key)
+	sp := c.startSpan(ctx, "get_or_load", key)
+	err := c.Cache.GetOrLoad(ctx, key, resultPtr, loader, c.scopeTags(ctx, opts)...)
+	finishSpan(sp, err)
+	return err
+}
+
+func (c Contextual) MGetContext(ctx context.Context, keys []string, resultPtr interface{}, opts ...ReadOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sp := c.startSpan(ctx, "mget", strings.Join(keys, ","))
+	err := c.mget(ctx, keys, resultPtr, opts...)
+	finishSpan(sp, err)
+	return err
+}
+
+// mget 带租户前缀读取, 再把结果的 key 还原为调用方传入的 key
+func (c Contextual) mget(ctx context.Context, keys []string, resultPtr interface{}, opts ...ReadOption) error {
+	opts = append(opts, ReadContext(ctx))
+
+	scoped := make([]string, len(keys))
+	origin := make(map[string]string, len(keys))
+	for i, key := range keys {
+		scoped[i] = c.scope(ctx, key)
+		origin[scoped[i]] = key
+	}
+
+	rv := reflect.ValueOf(resultPtr)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Map {
+		return c.Cache.MGet(scoped, resultPtr, opts...)
+	}
+
+	tmp := reflect.New(rv.Elem().Type())
+	if err := c.Cache.MGet(scoped, tmp.Interface(), opts...); err != nil {
+		return err
+	}
+
+	result := rv.Elem()
+	if result.IsNil() {
+		result.Set(reflect.MakeMap(result.Type()))
+	}
+	iter := tmp.Elem().MapRange()
+	for iter.Next() {
+		result.SetMapIndex(reflect.ValueOf(origin[iter.Key().String()]), iter.Value())
+	}
+	return nil
+}
+
+func (c Contextual) MSetContext(ctx context.Context, values map[string]interface{}, opts ...WriteOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	scoped := make(map[string]interface{}, len(values))
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		scoped[c.scope(ctx, key)] = value
+		keys = append(keys, key)
+	}
+
+	sp := c.startSpan(ctx, "mset", strings.Join(keys, ","))
+	err := c.Cache.MSet(scoped, c.scopeTags(ctx, opts)...)
+	finishSpan(sp, err)
+	return err
+}
+
+func (c Contextual) DeleteByPrefixContext(ctx context.Context, prefix string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	prefix = c.scope(ctx, prefix)
+	sp := c.startSpan(ctx, "delete_by_prefix", prefix)
+	err := c.Cache.DeleteByPrefix(prefix)
+	finishSpan(sp, err)
+	return err
+}
+
+func (c Contextual) InvalidateTagsContext(ctx context.Context, tags ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	scoped := make([]string, len(tags))
+	for i, tag := range tags {
+		scoped[i] = c.scope(ctx, tag)
+	}
+
+	sp := c.startSpan(ctx, "invalidate_tags", strings.Join(scoped, ","))
+	err := c.Cache.InvalidateTags(scoped...)
+	finishSpan(sp, err)
+	return err
+}
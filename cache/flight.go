@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Group 合并同一个 key 的并发调用, 用于防止缓存击穿
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// ErrPanic fn panic 时等待的调用者收到的错误
+var ErrPanic = errors.New("singleflight call panicked")
+
+// Do 执行 fn, 同一时刻同一个 key 只会有一个 fn 在执行, 其他调用者等待并共享结果,
+// fn panic 时等待的调用者收到 ErrPanic, 执行 fn 的调用者继续 panic
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	normal := false
+	defer func() {
+		if !normal {
+			r := recover()
+			c.val, c.err = nil, errors.New(fmt.Sprintf("%v: %v", ErrPanic, r))
+			g.finish(key, c)
+			panic(r)
+		}
+	}()
+
+	c.val, c.err = fn()
+	normal = true
+	g.finish(key, c)
+
+	return c.val, c.err, false
+}
+
+func (g *Group) finish(key string, c *call) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	c.wg.Done()
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupPanic(t *testing.T) {
+	var g Group
+
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	var waiterErr error
+	go func() {
+		defer wg.Done()
+		<-started
+		_, waiterErr, _ = g.Do("k", func() (interface{}, error) {
+			return "never", nil
+		})
+	}()
+
+	assert.Panics(t, func() {
+		g.Do("k", func() (interface{}, error) {
+			close(started)
+			// 等待另一个调用者进入等待
+			time.Sleep(50 * time.Millisecond)
+			panic("boom")
+		})
+	})
+
+	wg.Wait()
+	if assert.Error(t, waiterErr) {
+		assert.True(t, strings.Contains(waiterErr.Error(), "boom"))
+	}
+
+	// panic 之后 key 可以再次执行
+	v, err, _ := g.Do("k", func() (interface{}, error) {
+		return 1, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
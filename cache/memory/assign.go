@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"reflect"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/cache/codec"
+)
+
+var defaultCodec = codec.NewJSONCodec()
+
+func (m *MemoryCache) codec() cache.Codec {
+	if m.options.Codec != nil {
+		return m.options.Codec
+	}
+	return defaultCodec
+}
+
+// assign 将缓存中的值写入 resultPtr
+// 类型匹配时直接复制, 否则通过 codec 编码再解码, 无法转换时返回 false
+func (m *MemoryCache) assign(resultPtr interface{}, data interface{}) bool {
+	v := reflect.ValueOf(resultPtr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return false
+	}
+	elem := v.Elem()
+
+	dv := reflect.ValueOf(data)
+	if !dv.IsValid() {
+		elem.Set(reflect.Zero(elem.Type()))
+		return true
+	}
+
+	if dv.Type().AssignableTo(elem.Type()) {
+		elem.Set(dv)
+		return true
+	}
+
+	// 存入 *T, 读取 T
+	if dv.Kind() == reflect.Ptr && !dv.IsNil() && dv.Elem().Type().AssignableTo(elem.Type()) {
+		elem.Set(dv.Elem())
+		return true
+	}
+
+	b, err := m.codec().Marshal(data)
+	if err != nil {
+		return false
+	}
+
+	// 解码到临时变量, 失败时不污染 resultPtr
+	tmp := reflect.New(elem.Type())
+	if err := m.codec().Unmarshal(b, tmp.Interface()); err != nil {
+		return false
+	}
+
+	elem.Set(tmp.Elem())
+	return true
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/duolacloud/microbase/cache"
+)
+
+func (m *MemoryCache) MGet(keys []string, resultPtr interface{}, opts ...cache.ReadOption) error {
+	results := reflect.ValueOf(resultPtr)
+	if results.Kind() != reflect.Ptr || results.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("result must be a map pointer, got %T", resultPtr)
+	}
+
+	results = results.Elem()
+	if results.IsNil() {
+		results.Set(reflect.MakeMap(results.Type()))
+	}
+	elemType := results.Type().Elem()
+
+	for _, key := range keys {
+		data, stale, ok := m.store.get(m.prefix(key))
+		if !ok {
+			continue
+		}
+		if stale {
+			m.refresh(key, opts...)
+		}
+
+		elem := reflect.New(elemType)
+		if !m.assign(elem.Interface(), data) {
+			continue
+		}
+		results.SetMapIndex(reflect.ValueOf(key), elem.Elem())
+	}
+
+	return nil
+}
+
+func (m *MemoryCache) MSet(values map[string]interface{}, opts ...cache.WriteOption) error {
+	for key, value := range values {
+		if err := m.Set(key, value, opts...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryCache) DeleteByPrefix(prefix string) error {
+	prefix = m.prefix(prefix)
+
+	for _, key := range m.store.keys() {
+		if strings.HasPrefix(key, prefix) {
+			m.store.delete(key)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryCache) InvalidateTags(tags ...string) error {
+	m.tagsMu.Lock()
+	keys := make([]string, 0)
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			keys = append(keys, key)
+		}
+		delete(m.tags, tag)
+	}
+	m.tagsMu.Unlock()
+
+	for _, key := range keys {
+		m.store.delete(key)
+	}
+
+	return nil
+}
+
+// tag 记录标签到 key 的索引, key 为加上前缀后的完整 key
+func (m *MemoryCache) tag(key string, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	m.tagsMu.Lock()
+	defer m.tagsMu.Unlock()
+
+	for _, tag := range tags {
+		keys, ok := m.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			m.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	m.keyTags[key] = append(m.keyTags[key], tags...)
+}
+
+// untag key 被删除、过期或淘汰时清理标签索引, 避免索引无限增长
+func (m *MemoryCache) untag(key string) {
+	m.tagsMu.Lock()
+	defer m.tagsMu.Unlock()
+
+	for _, tag := range m.keyTags[key] {
+		if keys, ok := m.tags[tag]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(m.tags, tag)
+			}
+		}
+	}
+	delete(m.keyTags, key)
+}
@@ -3,16 +3,26 @@ package memory
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"sync"
 	"time"
 
 	"github.com/duolacloud/microbase/cache"
-	_cache "github.com/patrickmn/go-cache"
+	"github.com/duolacloud/microbase/logger"
 )
 
 type MemoryCache struct {
-	options cache.Options
-	cache   *_cache.Cache
+	cache.Contextual
+
+	options    cache.Options
+	store      *store
+	expiry     time.Duration
+	maxBytes   int64
+	group      cache.Group
+	refreshing cache.Refreshing
+	stop       chan struct{}
+	tagsMu     sync.Mutex
+	tags       map[string]map[string]struct{}
+	keyTags    map[string][]string
 }
 
 func NewCache(opts ...cache.Option) cache.Cache {
@@ -24,10 +34,16 @@ func NewCache(opts ...cache.Option) cache.Cache {
 		o(&options)
 	}
 
-	return &MemoryCache{
+	m := &MemoryCache{
 		options: options,
-		cache:   _cache.New(24*time.Hour, 30*time.Second),
+		store:   newStore("", 0, 0),
+		tags:    make(map[string]map[string]struct{}),
+		keyTags: make(map[string][]string),
 	}
+	m.store.onRemove = m.untag
+	m.Contextual = cache.Contextual{Cache: m}
+	m.configure()
+	return m
 }
 
 func (m *MemoryCache) Init(opts ...cache.Option) error {
@@ -35,9 +51,81 @@ func (m *MemoryCache) Init(opts ...cache.Option) error {
 		o(&m.options)
 	}
 
+	m.configure()
 	return nil
 }
 
+// configure 按 options 设置容量、淘汰策略、默认过期时间, 并重新启动过期清理
+func (m *MemoryCache) configure() {
+	ctx := m.options.Context
+
+	m.expiry = defaultExpiry
+	if v, ok := ctx.Value(defaultExpiryKey{}).(time.Duration); ok {
+		m.expiry = v
+	}
+
+	maxEntries, _ := ctx.Value(maxEntriesKey{}).(int)
+	m.maxBytes, _ = ctx.Value(maxBytesKey{}).(int64)
+
+	m.store.mu.Lock()
+	m.store.maxEntries = maxEntries
+	m.store.maxBytes = m.maxBytes
+	m.store.mu.Unlock()
+
+	eviction, _ := ctx.Value(evictionKey{}).(string)
+	m.store.setEviction(eviction)
+
+	interval := defaultCleanupInterval
+	if v, ok := ctx.Value(cleanupIntervalKey{}).(time.Duration); ok {
+		interval = v
+	}
+
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	if interval > 0 {
+		m.stop = make(chan struct{})
+		go m.janitor(interval, m.stop)
+	}
+}
+
+func (m *MemoryCache) janitor(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.store.deleteExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Close 停止过期清理
+func (m *MemoryCache) Close() error {
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	return nil
+}
+
+// size 估算缓存值占用的字节数, 只在设置了 WithMaxBytes 时计算
+func (m *MemoryCache) size(key string, value interface{}) int64 {
+	if m.maxBytes <= 0 {
+		return 0
+	}
+
+	b, err := m.codec().Marshal(value)
+	if err != nil {
+		return int64(len(key))
+	}
+	return int64(len(key) + len(b))
+}
+
 func (m *MemoryCache) Options() cache.Options {
 	return m.options
 }
@@ -54,27 +142,30 @@ func (m *MemoryCache) String() string {
 }
 
 func (m *MemoryCache) Exists(key string) bool {
-	_, ok := m.cache.Get(key)
+	_, ok := m.store.peek(m.prefix(key))
 	return ok
 }
 
+// Flush 清空所有缓存
+func (m *MemoryCache) Flush() {
+	m.store.flush()
+}
+
 func (m *MemoryCache) Get(key string, resultPtr interface{}, opts ...cache.ReadOption) bool {
 	readOpts := cache.ReadOptions{}
 	for _, o := range opts {
 		o(&readOpts)
 	}
 
-	key = m.prefix(key)
-
-	data, ok := m.cache.Get(key)
+	data, stale, ok := m.store.get(m.prefix(key))
 	if !ok {
 		return false
 	}
+	if stale {
+		m.refresh(key, opts...)
+	}
 
-	v := reflect.ValueOf(resultPtr)
-	v.Elem().Set(reflect.ValueOf(data))
-
-	return true
+	return m.assign(resultPtr, data)
 }
 
 func (m *MemoryCache) Set(key string, value interface{}, opts ...cache.WriteOption) error {
@@ -85,7 +176,13 @@ func (m *MemoryCache) Set(key string, value interface{}, opts ...cache.WriteOpti
 
 	key = m.prefix(key)
 
-	m.cache.Set(key, value, writeOpts.Expiry)
+	if writeOpts.Expiry <= 0 {
+		writeOpts.Expiry = m.expiry
+	}
+	expiry, soft := writeOpts.TTL()
+
+	m.store.set(key, value, m.size(key, value), expiry, soft)
+	m.tag(key, writeOpts.Tags)
 	return nil
 }
 
@@ -95,6 +192,59 @@ func (m *MemoryCache) Delete(key string, opts ...cache.DeleteOption) error {
 		o(&deleteOptions)
 	}
 
-	m.cache.Delete(m.prefix(key))
+	m.store.delete(m.prefix(key))
 	return nil
 }
+
+func (m *MemoryCache) GetOrLoad(ctx context.Context, key string, resultPtr interface{}, loader cache.Loader, opts ...cache.WriteOption) error {
+	if m.Get(key, resultPtr, cache.ReadContext(ctx)) {
+		return nil
+	}
+
+	data, err, _ := m.group.Do(m.prefix(key), func() (interface{}, error) {
+		// 等待期间可能已经被其他调用者加载
+		if data, ok := m.store.peek(m.prefix(key)); ok {
+			return data, nil
+		}
+
+		data, err := loader(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.Set(key, data, opts...); err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !m.assign(resultPtr, data) {
+		return fmt.Errorf("cannot assign %T to %T", data, resultPtr)
+	}
+
+	return nil
+}
+
+// refresh 软过期后在后台通过注册的加载函数刷新, 同一个 key 同时只有一个刷新
+func (m *MemoryCache) refresh(key string, readOpts ...cache.ReadOption) {
+	loader, opts, ok := m.options.Loaders.Match(key)
+	if !ok {
+		return
+	}
+
+	ctx := cache.RefreshContext(readOpts...)
+	m.refreshing.Go(m.prefix(key), func() {
+		data, err := loader(ctx, key)
+		if err != nil {
+			logger.Warnf("memory cache refresh key: %s, err: %v", key, err)
+			return
+		}
+
+		if err = m.Set(key, data, opts...); err != nil {
+			logger.Warnf("memory cache refresh key: %s, err: %v", key, err)
+		}
+	})
+}
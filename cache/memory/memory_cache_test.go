@@ -1,12 +1,16 @@
 package memory_test
 
 import (
+	"context"
 	"log"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/duolacloud/microbase/cache"
 	"github.com/duolacloud/microbase/cache/memory"
+	"github.com/duolacloud/microbase/multitenancy"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -38,3 +42,274 @@ func TestBasic(t *testing.T) {
 	ok = s.Get(key, &value2)
 	log.Printf("get %s, result: %v", key, ok)
 }
+
+func TestGetOrLoad(t *testing.T) {
+	s := memory.NewCache()
+	s.Init()
+
+	var loads int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(time.Millisecond * 100)
+		return &User{Name: "李小龙"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var value *User
+			err := s.GetOrLoad(context.Background(), "load", &value, loader, cache.WriteExpiry(time.Second))
+			assert.NoError(t, err)
+			assert.Equal(t, "李小龙", value.Name)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
+
+func TestBatch(t *testing.T) {
+	s := memory.NewCache(cache.WithPrefix("test"))
+	s.Init()
+
+	err := s.MSet(map[string]interface{}{
+		"user:1":  &User{Name: "张三"},
+		"user:2":  &User{Name: "李四"},
+		"order:1": &User{Name: "王五"},
+	}, cache.WriteTags("users"))
+	assert.NoError(t, err)
+
+	users := map[string]*User{}
+	err = s.MGet([]string{"user:1", "user:2", "user:3"}, &users)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "李四", users["user:2"].Name)
+
+	err = s.DeleteByPrefix("user:")
+	assert.NoError(t, err)
+
+	var value *User
+	assert.Equal(t, false, s.Get("user:1", &value))
+	assert.Equal(t, true, s.Get("order:1", &value))
+
+	err = s.InvalidateTags("users")
+	assert.NoError(t, err)
+	assert.Equal(t, false, s.Get("order:1", &value))
+}
+
+func TestTypeMismatch(t *testing.T) {
+	s := memory.NewCache()
+	s.Init()
+
+	err := s.Set("user", &User{Name: "李小龙"})
+	assert.NoError(t, err)
+
+	var user User
+	assert.Equal(t, true, s.Get("user", &user))
+	assert.Equal(t, "李小龙", user.Name)
+
+	var m map[string]interface{}
+	assert.Equal(t, true, s.Get("user", &m))
+	assert.Equal(t, "李小龙", m["name"])
+
+	var n int
+	assert.Equal(t, false, s.Get("user", &n))
+}
+
+func TestTenantScope(t *testing.T) {
+	s := memory.NewCache(cache.WithTenantScope())
+	s.Init()
+
+	ctx1 := context.WithValue(context.Background(), multitenancy.TenantId, "t1")
+	ctx2 := context.WithValue(context.Background(), multitenancy.TenantId, "t2")
+
+	err := s.SetContext(ctx1, "user", &User{Name: "t1"}, cache.WriteTags("users"))
+	assert.NoError(t, err)
+	err = s.SetContext(ctx2, "user", &User{Name: "t2"}, cache.WriteTags("users"))
+	assert.NoError(t, err)
+
+	var user User
+	assert.Equal(t, true, s.GetContext(ctx1, "user", &user))
+	assert.Equal(t, "t1", user.Name)
+
+	users := map[string]*User{}
+	err = s.MGetContext(ctx2, []string{"user"}, &users)
+	assert.NoError(t, err)
+	assert.Equal(t, "t2", users["user"].Name)
+
+	err = s.InvalidateTagsContext(ctx1, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, false, s.ExistsContext(ctx1, "user"))
+	assert.Equal(t, true, s.ExistsContext(ctx2, "user"))
+
+	cancelled, cancel := context.WithCancel(ctx2)
+	cancel()
+	assert.Error(t, s.DeleteContext(cancelled, "user"))
+	assert.Equal(t, true, s.ExistsContext(ctx2, "user"))
+}
+
+func TestEviction(t *testing.T) {
+	s := memory.NewCache(memory.WithMaxEntries(2), memory.WithEviction(memory.EvictionLRU))
+	s.Init()
+
+	assert.NoError(t, s.Set("a", 1))
+	assert.NoError(t, s.Set("b", 2))
+
+	var v int
+	assert.Equal(t, true, s.Get("a", &v))
+	assert.NoError(t, s.Set("c", 3))
+
+	assert.Equal(t, true, s.Exists("a"))
+	assert.Equal(t, false, s.Exists("b"))
+	assert.Equal(t, true, s.Exists("c"))
+
+	s = memory.NewCache(memory.WithMaxEntries(2), memory.WithEviction(memory.EvictionLFU))
+	s.Init()
+
+	assert.NoError(t, s.Set("a", 1))
+	assert.NoError(t, s.Set("b", 2))
+	for i := 0; i < 3; i++ {
+		s.Get("b", &v)
+	}
+	s.Get("a", &v)
+	assert.NoError(t, s.Set("c", 3))
+
+	assert.Equal(t, false, s.Exists("a"))
+	assert.Equal(t, true, s.Exists("b"))
+	assert.Equal(t, true, s.Exists("c"))
+
+	stats := s.(memory.StatsReporter).Stats()
+	assert.Equal(t, int64(4), stats.Hits)
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.Equal(t, int64(2), stats.Entries)
+}
+
+func TestInitEviction(t *testing.T) {
+	s := memory.NewCache(memory.WithMaxEntries(2))
+	s.Init()
+
+	var v int
+	assert.NoError(t, s.Set("a", 1))
+	assert.NoError(t, s.Set("b", 2))
+
+	// 切换为 LFU 后按访问次数淘汰, 最近访问的 a 仍被淘汰
+	assert.NoError(t, s.Init(memory.WithEviction(memory.EvictionLFU)))
+	s.Get("b", &v)
+	s.Get("b", &v)
+	s.Get("a", &v)
+	assert.NoError(t, s.Set("c", 3))
+
+	assert.Equal(t, false, s.Exists("a"))
+	assert.Equal(t, true, s.Exists("b"))
+	assert.Equal(t, true, s.Exists("c"))
+}
+
+func TestEvictionKeepsFrequency(t *testing.T) {
+	s := memory.NewCache(memory.WithMaxEntries(2), memory.WithEviction(memory.EvictionLFU))
+	s.Init()
+
+	var v int
+	assert.NoError(t, s.Set("a", 1))
+	assert.NoError(t, s.Set("b", 2))
+	for i := 0; i < 4; i++ {
+		s.Get("a", &v)
+		s.Get("b", &v)
+	}
+
+	// 写入 c 时跳过 c 淘汰 a, c 的访问次数不变
+	assert.NoError(t, s.Set("c", 3))
+	assert.Equal(t, false, s.Exists("a"))
+
+	s.Get("c", &v)
+	s.Get("c", &v)
+	s.Get("b", &v)
+	assert.NoError(t, s.Set("d", 4))
+
+	assert.Equal(t, true, s.Exists("b"))
+	assert.Equal(t, false, s.Exists("c"))
+	assert.Equal(t, true, s.Exists("d"))
+}
+
+func TestDefaultExpiry(t *testing.T) {
+	s := memory.NewCache(memory.WithDefaultExpiry(50*time.Millisecond), memory.WithCleanupInterval(10*time.Millisecond))
+	s.Init()
+
+	assert.NoError(t, s.Set("a", 1, cache.WriteTags("t")))
+	assert.Equal(t, true, s.Exists("a"))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, false, s.Exists("a"))
+	assert.Equal(t, int64(1), s.(memory.StatsReporter).Stats().Expirations)
+}
+
+func TestSoftExpiry(t *testing.T) {
+	var loads int32
+	s := memory.NewCache(cache.WithLoader("user:", func(ctx context.Context, key string) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(20 * time.Millisecond)
+		return "fresh", nil
+	}, cache.WriteExpiry(time.Minute), cache.WriteSoftExpiry(time.Minute)))
+	s.Init()
+
+	err := s.Set("user:1", "stale", cache.WriteExpiry(time.Minute), cache.WriteSoftExpiry(10*time.Millisecond), cache.WriteJitter(time.Millisecond))
+	assert.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// 软过期后仍然返回旧值, 并且只触发一次刷新
+	for i := 0; i < 10; i++ {
+		var v string
+		assert.Equal(t, true, s.Get("user:1", &v))
+		assert.Equal(t, "stale", v)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	var v string
+	assert.Equal(t, true, s.Get("user:1", &v))
+	assert.Equal(t, "fresh", v)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
+
+func TestSoftExpiryContext(t *testing.T) {
+	type result struct {
+		tenantId string
+		err      error
+	}
+	results := make(chan result, 1)
+	cancelled := make(chan struct{})
+
+	s := memory.NewCache(cache.WithLoader("user:", func(ctx context.Context, key string) (interface{}, error) {
+		<-cancelled
+		tenantId, _ := multitenancy.FromContext(ctx)
+		results <- result{tenantId, ctx.Err()}
+		return "fresh", nil
+	}))
+	s.Init()
+
+	assert.NoError(t, s.Set("user:1", "stale", cache.WriteExpiry(time.Minute), cache.WriteSoftExpiry(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+
+	// 刷新使用调用方 ctx 中的租户, 调用方的请求结束后也不取消
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), multitenancy.TenantId, "t1"))
+	var v string
+	assert.Equal(t, true, s.GetContext(ctx, "user:1", &v))
+	cancel()
+	close(cancelled)
+
+	r := <-results
+	assert.Equal(t, "t1", r.tenantId)
+	assert.NoError(t, r.err)
+}
+
+func TestJitter(t *testing.T) {
+	opts := cache.WriteOptions{Expiry: time.Minute, Jitter: 10 * time.Second}
+	for i := 0; i < 100; i++ {
+		expiry, soft := opts.TTL()
+		assert.True(t, expiry >= time.Minute && expiry < time.Minute+10*time.Second)
+		assert.Equal(t, time.Duration(0), soft)
+	}
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+)
+
+type maxEntriesKey struct{}
+type maxBytesKey struct{}
+type evictionKey struct{}
+type defaultExpiryKey struct{}
+type cleanupIntervalKey struct{}
+
+const (
+	defaultExpiry          = 24 * time.Hour
+	defaultCleanupInterval = 30 * time.Second
+)
+
+// WithMaxEntries 最多缓存的 key 数量, 0 表示不限制
+func WithMaxEntries(n int) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, maxEntriesKey{}, n)
+	}
+}
+
+// WithMaxBytes 缓存值按 codec 编码后的总字节数上限, 0 表示不限制
+func WithMaxBytes(n int64) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, maxBytesKey{}, n)
+	}
+}
+
+// WithEviction 超出容量时的淘汰策略: lru, lfu
+func WithEviction(eviction string) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, evictionKey{}, eviction)
+	}
+}
+
+// WithDefaultExpiry 写入时未指定过期时间使用的过期时间
+func WithDefaultExpiry(expiry time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, defaultExpiryKey{}, expiry)
+	}
+}
+
+// WithCleanupInterval 清理过期 key 的间隔
+func WithCleanupInterval(interval time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, cleanupIntervalKey{}, interval)
+	}
+}
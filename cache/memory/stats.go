@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Stats 缓存的命中和淘汰统计
+type Stats struct {
+	Hits        int64
+	Misses      int64
+	Evictions   int64
+	Expirations int64
+	Entries     int64
+	Bytes       int64
+}
+
+// StatsReporter 可以输出 Stats 的缓存
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// Stats 返回当前的统计数据
+func (m *MemoryCache) Stats() Stats {
+	return m.store.stats()
+}
+
+var (
+	hitsDesc = prometheus.NewDesc("microbase_cache_hits_total",
+		"Number of cache hits.", []string{"cache"}, nil)
+	missesDesc = prometheus.NewDesc("microbase_cache_misses_total",
+		"Number of cache misses.", []string{"cache"}, nil)
+	evictionsDesc = prometheus.NewDesc("microbase_cache_evictions_total",
+		"Number of entries evicted because the cache was full.", []string{"cache"}, nil)
+	expirationsDesc = prometheus.NewDesc("microbase_cache_expirations_total",
+		"Number of entries removed because they expired.", []string{"cache"}, nil)
+	entriesDesc = prometheus.NewDesc("microbase_cache_entries",
+		"Number of entries in the cache.", []string{"cache"}, nil)
+	bytesDesc = prometheus.NewDesc("microbase_cache_bytes",
+		"Estimated size of the cached values in bytes.", []string{"cache"}, nil)
+)
+
+type collector struct {
+	name     string
+	reporter StatsReporter
+}
+
+// NewCollector 将缓存统计导出为 prometheus 指标, name 作为 cache 标签区分多个缓存
+func NewCollector(name string, reporter StatsReporter) prometheus.Collector {
+	return &collector{
+		name:     name,
+		reporter: reporter,
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- hitsDesc
+	ch <- missesDesc
+	ch <- evictionsDesc
+	ch <- expirationsDesc
+	ch <- entriesDesc
+	ch <- bytesDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.reporter.Stats()
+
+	ch <- prometheus.MustNewConstMetric(hitsDesc, prometheus.CounterValue, float64(stats.Hits), c.name)
+	ch <- prometheus.MustNewConstMetric(missesDesc, prometheus.CounterValue, float64(stats.Misses), c.name)
+	ch <- prometheus.MustNewConstMetric(evictionsDesc, prometheus.CounterValue, float64(stats.Evictions), c.name)
+	ch <- prometheus.MustNewConstMetric(expirationsDesc, prometheus.CounterValue, float64(stats.Expirations), c.name)
+	ch <- prometheus.MustNewConstMetric(entriesDesc, prometheus.GaugeValue, float64(stats.Entries), c.name)
+	ch <- prometheus.MustNewConstMetric(bytesDesc, prometheus.GaugeValue, float64(stats.Bytes), c.name)
+}
@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// EvictionLRU 淘汰最久未访问的 key
+	EvictionLRU = "lru"
+	// EvictionLFU 淘汰访问次数最少的 key, 次数相同时淘汰最久未访问的
+	EvictionLFU = "lfu"
+)
+
+type item struct {
+	key       string
+	value     interface{}
+	size      int64
+	expiresAt time.Time
+	staleAt   time.Time
+
+	// LRU 链表节点
+	elem *list.Element
+	// LFU 堆中的位置、访问次数和最近访问序号
+	index int
+	freq  int64
+	tick  int64
+}
+
+func (it *item) expired(now time.Time) bool {
+	return !it.expiresAt.IsZero() && now.After(it.expiresAt)
+}
+
+// policy 淘汰策略, 调用方持有 store 的锁
+type policy interface {
+	add(it *item)
+	access(it *item)
+	remove(it *item)
+	// victim 下一个淘汰的 key, 跳过 skip, 不改变任何 key 的访问记录
+	victim(skip *item) *item
+}
+
+type lruPolicy struct {
+	ll *list.List
+}
+
+func (p *lruPolicy) add(it *item) {
+	it.elem = p.ll.PushFront(it)
+}
+
+func (p *lruPolicy) access(it *item) {
+	p.ll.MoveToFront(it.elem)
+}
+
+func (p *lruPolicy) remove(it *item) {
+	p.ll.Remove(it.elem)
+}
+
+func (p *lruPolicy) victim(skip *item) *item {
+	for e := p.ll.Back(); e != nil; e = e.Prev() {
+		if it := e.Value.(*item); it != skip {
+			return it
+		}
+	}
+	return nil
+}
+
+type lfuPolicy struct {
+	items []*item
+	tick  int64
+}
+
+func (p *lfuPolicy) Len() int { return len(p.items) }
+
+func (p *lfuPolicy) Less(i, j int) bool {
+	if p.items[i].freq != p.items[j].freq {
+		return p.items[i].freq < p.items[j].freq
+	}
+	return p.items[i].tick < p.items[j].tick
+}
+
+func (p *lfuPolicy) Swap(i, j int) {
+	p.items[i], p.items[j] = p.items[j], p.items[i]
+	p.items[i].index = i
+	p.items[j].index = j
+}
+
+func (p *lfuPolicy) Push(x interface{}) {
+	it := x.(*item)
+	it.index = len(p.items)
+	p.items = append(p.items, it)
+}
+
+func (p *lfuPolicy) Pop() interface{} {
+	n := len(p.items)
+	it := p.items[n-1]
+	p.items[n-1] = nil
+	p.items = p.items[:n-1]
+	return it
+}
+
+func (p *lfuPolicy) add(it *item) {
+	p.tick++
+	it.freq = 1
+	it.tick = p.tick
+	heap.Push(p, it)
+}
+
+func (p *lfuPolicy) access(it *item) {
+	p.tick++
+	it.freq++
+	it.tick = p.tick
+	heap.Fix(p, it.index)
+}
+
+func (p *lfuPolicy) remove(it *item) {
+	heap.Remove(p, it.index)
+}
+
+func (p *lfuPolicy) victim(skip *item) *item {
+	if len(p.items) == 0 {
+		return nil
+	}
+	if p.items[0] != skip {
+		return p.items[0]
+	}
+
+	// 堆顶被跳过时, 次小的元素是它的两个子节点之一
+	var it *item
+	for i := 1; i <= 2 && i < len(p.items); i++ {
+		if it == nil || p.Less(i, it.index) {
+			it = p.items[i]
+		}
+	}
+	return it
+}
+
+// store 有容量限制的内存存储, maxEntries 和 maxBytes 为 0 表示不限制
+type store struct {
+	mu         sync.Mutex
+	items      map[string]*item
+	eviction   string
+	policy     policy
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+
+	// onRemove key 被删除、过期或淘汰时调用, 调用时持有 store 的锁
+	onRemove func(key string)
+
+	hits        int64
+	misses      int64
+	evictions   int64
+	expirations int64
+}
+
+func newStore(eviction string, maxEntries int, maxBytes int64) *store {
+	s := &store{
+		items:      make(map[string]*item),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+	}
+	s.eviction, s.policy = newPolicy(eviction)
+	return s
+}
+
+// newPolicy 未知的淘汰策略按 LRU 处理
+func newPolicy(eviction string) (string, policy) {
+	if eviction == EvictionLFU {
+		return EvictionLFU, &lfuPolicy{}
+	}
+	return EvictionLRU, &lruPolicy{ll: list.New()}
+}
+
+// setEviction 切换淘汰策略, 已有的 key 保留, 访问记录按新策略重新计算
+func (s *store) setEviction(eviction string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eviction, p := newPolicy(eviction)
+	if eviction == s.eviction {
+		return
+	}
+
+	s.eviction, s.policy = eviction, p
+	for _, it := range s.items {
+		s.policy.add(it)
+	}
+}
+
+// get 读取 key 并记录命中率, stale 表示已超过软过期时间
+func (s *store) get(key string) (value interface{}, stale bool, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if ok && it.expired(time.Now()) {
+		s.removeItem(it)
+		s.expirations++
+		ok = false
+	}
+	if !ok {
+		s.misses++
+		return nil, false, false
+	}
+
+	s.hits++
+	s.policy.access(it)
+	return it.value, !it.staleAt.IsZero() && time.Now().After(it.staleAt), true
+}
+
+// peek 读取 key, 不影响命中率和淘汰顺序
+func (s *store) peek(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok || it.expired(time.Now()) {
+		return nil, false
+	}
+	return it.value, true
+}
+
+func (s *store) set(key string, value interface{}, size int64, ttl, soft time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if it, ok := s.items[key]; ok {
+		s.removeItem(it)
+	}
+
+	it := &item{
+		key:   key,
+		value: value,
+		size:  size,
+	}
+	if ttl > 0 {
+		it.expiresAt = time.Now().Add(ttl)
+	}
+	if soft > 0 {
+		it.staleAt = time.Now().Add(soft)
+	}
+
+	s.items[key] = it
+	s.bytes += size
+	s.policy.add(it)
+
+	s.evict(it)
+}
+
+// evict 超出容量时按策略淘汰, 不淘汰刚写入的 key, 除非它本身就超出了 maxBytes
+func (s *store) evict(current *item) {
+	for s.overflow() {
+		victim := s.policy.victim(current)
+		if victim == nil {
+			// 只剩刚写入的 key, 它本身超出了 maxBytes
+			victim = current
+		}
+
+		s.removeItem(victim)
+		s.evictions++
+	}
+}
+
+func (s *store) overflow() bool {
+	if s.maxEntries > 0 && len(s.items) > s.maxEntries {
+		return true
+	}
+	if s.maxBytes > 0 && s.bytes > s.maxBytes {
+		return true
+	}
+	return false
+}
+
+func (s *store) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if it, ok := s.items[key]; ok {
+		s.removeItem(it)
+	}
+}
+
+func (s *store) removeItem(it *item) {
+	s.policy.remove(it)
+	delete(s.items, it.key)
+	s.bytes -= it.size
+
+	if s.onRemove != nil {
+		s.onRemove(it.key)
+	}
+}
+
+// keys 返回所有未过期的 key
+func (s *store) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(s.items))
+	for key, it := range s.items {
+		if !it.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// deleteExpired 清理过期的 key
+func (s *store) deleteExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, it := range s.items {
+		if it.expired(now) {
+			s.removeItem(it)
+			s.expirations++
+		}
+	}
+}
+
+func (s *store) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, it := range s.items {
+		s.removeItem(it)
+	}
+}
+
+func (s *store) stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Stats{
+		Hits:        s.hits,
+		Misses:      s.misses,
+		Evictions:   s.evictions,
+		Expirations: s.expirations,
+		Entries:     int64(len(s.items)),
+		Bytes:       s.bytes,
+	}
+}
@@ -2,12 +2,18 @@ package cache
 
 import (
 	"context"
+	"math/rand"
 	"time"
 )
 
 type Options struct {
 	Context context.Context
 	Prefix  string
+	Codec   Codec
+	// TenantScoped ContextCache 的方法按租户隔离 key
+	TenantScoped bool
+	// Loaders 软过期后用于后台刷新的加载函数
+	Loaders *Loaders
 }
 
 type Option func(o *Options)
@@ -18,13 +24,52 @@ func WithPrefix(prefix string) Option {
 	}
 }
 
+// WithCodec 设置缓存值的编解码方式, 默认使用 json
+func WithCodec(codec Codec) Option {
+	return func(w *Options) {
+		w.Codec = codec
+	}
+}
+
+// WithTenantScope ContextCache 的方法为 key 加上 ctx 中的租户前缀
+func WithTenantScope() Option {
+	return func(w *Options) {
+		w.TenantScoped = true
+	}
+}
+
+// WithLoader 为以 prefix 开头的 key 注册加载函数,
+// 通过 WriteSoftExpiry 写入的 key 软过期后, Get 返回旧值并在后台用它刷新, 刷新时使用 opts 写入
+func WithLoader(prefix string, loader KeyLoader, opts ...WriteOption) Option {
+	return func(w *Options) {
+		if w.Loaders == nil {
+			w.Loaders = NewLoaders()
+		}
+		w.Loaders.Register(prefix, loader, opts...)
+	}
+}
+
 type ReadOptions struct {
+	// Context 调用方的 ctx, 软过期后台刷新时保留其中的租户和 span
+	Context context.Context
 }
 
 type ReadOption func(o *ReadOptions)
 
+// ReadContext 读取时传入调用方的 ctx, ContextCache 的方法会自动设置
+func ReadContext(ctx context.Context) ReadOption {
+	return func(o *ReadOptions) {
+		o.Context = ctx
+	}
+}
+
 type WriteOptions struct {
 	Expiry time.Duration
+	Tags   []string
+	// Jitter 过期时间随机增加 [0, Jitter), 避免同时写入的 key 同时过期
+	Jitter time.Duration
+	// SoftExpiry 软过期时间, 超过后 Get 仍返回旧值并触发后台刷新, 直到 Expiry 才真正删除
+	SoftExpiry time.Duration
 }
 
 type WriteOption func(o *WriteOptions)
@@ -35,6 +80,43 @@ func WriteExpiry(t time.Duration) WriteOption {
 	}
 }
 
+// WriteTags 为写入的 key 设置标签, 用于 InvalidateTags 批量失效
+func WriteTags(tags ...string) WriteOption {
+	return func(w *WriteOptions) {
+		w.Tags = append(w.Tags, tags...)
+	}
+}
+
+// WriteJitter 过期时间随机增加不超过 max 的时长
+func WriteJitter(max time.Duration) WriteOption {
+	return func(w *WriteOptions) {
+		w.Jitter = max
+	}
+}
+
+// WriteSoftExpiry 设置软过期时间, 需要通过 WithLoader 注册加载函数
+func WriteSoftExpiry(t time.Duration) WriteOption {
+	return func(w *WriteOptions) {
+		w.SoftExpiry = t
+	}
+}
+
+// TTL 返回加上随机抖动后的过期时间和软过期时间, 0 表示不过期或不使用软过期
+func (w WriteOptions) TTL() (expiry time.Duration, soft time.Duration) {
+	var jitter time.Duration
+	if w.Jitter > 0 {
+		jitter = time.Duration(rand.Int63n(int64(w.Jitter)))
+	}
+
+	if w.Expiry > 0 {
+		expiry = w.Expiry + jitter
+	}
+	if w.SoftExpiry > 0 && (w.Expiry <= 0 || w.SoftExpiry < w.Expiry) {
+		soft = w.SoftExpiry + jitter
+	}
+	return
+}
+
 type DeleteOptions struct {
 }
 
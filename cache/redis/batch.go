@@ -0,0 +1,313 @@
+package redis
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/garyburd/redigo/redis"
+)
+
+const scanCount = 1000
+
+var globReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+func (m *RedisCache) MGet(keys []string, resultPtr interface{}, opts ...cache.ReadOption) error {
+	results := reflect.ValueOf(resultPtr)
+	if results.Kind() != reflect.Ptr || results.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("result must be a map pointer, got %T", resultPtr)
+	}
+
+	results = results.Elem()
+	if results.IsNil() {
+		results.Set(reflect.MakeMap(results.Type()))
+	}
+	elemType := results.Type().Elem()
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = m.prefix(key)
+	}
+
+	// 注册了加载函数时同时读取软过期标记
+	refreshable := m.options.Loaders != nil
+	if refreshable {
+		for _, key := range keys {
+			args = append(args, softKey(m.prefix(key)))
+		}
+	}
+
+	c := m.r.Get()
+	defer c.Close()
+
+	values, err := redis.ByteSlices(c.Do("MGET", args...))
+	if err != nil {
+		return err
+	}
+
+	for i, data := range values[:len(keys)] {
+		if data == nil {
+			continue
+		}
+		if refreshable && stale(values[len(keys)+i]) {
+			m.refresh(keys[i], opts...)
+		}
+
+		elem := reflect.New(elemType)
+		if err := m.codec().Unmarshal(data, elem.Interface()); err != nil {
+			continue
+		}
+		results.SetMapIndex(reflect.ValueOf(keys[i]), elem.Elem())
+	}
+
+	return nil
+}
+
+func (m *RedisCache) MSet(values map[string]interface{}, opts ...cache.WriteOption) error {
+	writeOpts := cache.WriteOptions{}
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	c := m.r.Get()
+	defer c.Close()
+
+	// 通过 pipeline 一次性发送, MSET 不支持过期时间
+	for key, value := range values {
+		data, err := m.codec().Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if err := m.sendSet(c, key, data, writeOpts); err != nil {
+			return err
+		}
+	}
+
+	return flush(c)
+}
+
+func (m *RedisCache) DeleteByPrefix(prefix string) error {
+	match := globReplacer.Replace(m.prefix(prefix)) + "*"
+
+	// 集群模式下每个主节点只保存部分 key, 需要逐个节点扫描,
+	// 扫描到的 key 都在当前节点上, 复用扫描的连接逐个删除, 避免跨 slot 和占用第二个连接
+	return m.r.ForEachNode(func(node redis.Conn) error {
+		cursor := 0
+		for {
+			values, err := redis.Values(node.Do("SCAN", cursor, "MATCH", match, "COUNT", scanCount))
+			if err != nil {
+				return err
+			}
+
+			var keys []string
+			if _, err := redis.Scan(values, &cursor, &keys); err != nil {
+				return err
+			}
+
+			if len(keys) > 0 {
+				for _, key := range keys {
+					if err := node.Send("DEL", key); err != nil {
+						return err
+					}
+				}
+				if err := flush(node); err != nil {
+					return err
+				}
+			}
+
+			if cursor == 0 {
+				return nil
+			}
+		}
+	})
+}
+
+func (m *RedisCache) InvalidateTags(tags ...string) error {
+	c := m.r.Get()
+	defer c.Close()
+
+	for _, tag := range tags {
+		tagKey := m.tagKey(tag)
+
+		keys, err := redis.Strings(c.Do("SMEMBERS", tagKey))
+		if err != nil {
+			return err
+		}
+
+		if err := m.deleteKeys(c, keys...); err != nil {
+			return err
+		}
+		if _, err := c.Do("DEL", tagKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteKeys 删除 key 以及软过期标记, 同时从 key 所属的其它标签索引中移除, keys 带前缀
+func (m *RedisCache) deleteKeys(c redis.Conn, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := c.Send("SMEMBERS", tagsKey(key)); err != nil {
+			return err
+		}
+	}
+	replies, err := redis.Values(c.Do(""))
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		tags, err := redis.Strings(replies[i], nil)
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := c.Send("SREM", m.tagKey(tag), key); err != nil {
+				return err
+			}
+		}
+		if err := c.Send("DEL", key, softKey(key), tagsKey(key)); err != nil {
+			return err
+		}
+	}
+
+	return flush(c)
+}
+
+// TaggedKeys 返回带有这些标签的 key (不含前缀)
+func (m *RedisCache) TaggedKeys(tags ...string) ([]string, error) {
+	c := m.r.Get()
+	defer c.Close()
+
+	prefix := m.prefix("")
+	keys := make([]string, 0)
+	for _, tag := range tags {
+		members, err := redis.Strings(c.Do("SMEMBERS", m.tagKey(tag)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			keys = append(keys, strings.TrimPrefix(member, prefix))
+		}
+	}
+
+	return keys, nil
+}
+
+// tagKey 标签索引的 key, 保存带有该标签的所有 key
+func (m *RedisCache) tagKey(tag string) string {
+	return m.prefix(fmt.Sprintf("tag:%s", tag))
+}
+
+// tagsKey 反向索引, 保存 key 的所有标签, 删除 key 时从标签索引中移除, key 带前缀
+func tagsKey(key string) string {
+	return fmt.Sprintf("%s:tags", key)
+}
+
+// 标签索引的过期时间不短于其中任何一个 key, 有不过期的 key 时标签索引也不过期,
+// ARGV[2] 为 key 的过期毫秒数, 0 表示不过期
+var tagScript = redis.NewScript(1, `
+local existed = redis.call("EXISTS", KEYS[1])
+redis.call("SADD", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call("PERSIST", KEYS[1])
+elseif existed == 0 then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+else
+	local current = redis.call("PTTL", KEYS[1])
+	if current >= 0 and current < ttl then
+		redis.call("PEXPIRE", KEYS[1], ttl)
+	end
+end
+return 1
+`)
+
+// sendSet 通过 pipeline 发送写入命令, 每个 key 单独计算抖动后的过期时间
+func (m *RedisCache) sendSet(c redis.Conn, key string, data []byte, writeOpts cache.WriteOptions) error {
+	expiry, soft := writeOpts.TTL()
+	_, _, refreshable := m.options.Loaders.Match(key)
+
+	key = m.prefix(key)
+
+	var err error
+	if expiry > 0 {
+		err = c.Send("PSETEX", key, int64(expiry/time.Millisecond), data)
+	} else {
+		err = c.Send("SET", key, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	if soft > 0 {
+		deadline := time.Now().Add(soft).UnixNano() / int64(time.Millisecond)
+		if expiry > 0 {
+			err = c.Send("PSETEX", softKey(key), int64(expiry/time.Millisecond), deadline)
+		} else {
+			err = c.Send("SET", softKey(key), deadline)
+		}
+	} else if refreshable {
+		// 清除之前写入的软过期标记
+		err = c.Send("DEL", softKey(key))
+	}
+	if err != nil {
+		return err
+	}
+
+	return m.sendTags(c, key, expiry, writeOpts.Tags)
+}
+
+// flush 执行 pipeline 中的命令, 返回第一个命令错误
+func flush(c redis.Conn) error {
+	replies, err := redis.Values(c.Do(""))
+	if err != nil {
+		return err
+	}
+
+	for _, reply := range replies {
+		if err, ok := reply.(redis.Error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *RedisCache) sendTags(c redis.Conn, key string, expiry time.Duration, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ttl := int64(expiry / time.Millisecond)
+	for _, tag := range tags {
+		if err := tagScript.Send(c, m.tagKey(tag), key, ttl); err != nil {
+			return err
+		}
+	}
+
+	// 反向索引与 key 同时过期
+	if err := c.Send("SADD", redis.Args{}.Add(tagsKey(key)).AddFlat(tags)...); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return c.Send("PEXPIRE", tagsKey(key), ttl)
+	}
+	return c.Send("PERSIST", tagsKey(key))
+}
@@ -0,0 +1,518 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const (
+	clusterSlots        = 16384
+	clusterMaxRedirects = 5
+	clusterRetryBackoff = 100 * time.Millisecond
+)
+
+// clusterPool 集群模式的连接池, 每个主节点一个 redis.Pool, 命令按 key 的 slot 路由
+type clusterPool struct {
+	opts poolOptions
+
+	mu    sync.RWMutex
+	nodes map[string]*redis.Pool
+	slots [clusterSlots]string
+
+	refreshing int32
+	refreshMu  sync.Mutex
+}
+
+func newClusterPool(o poolOptions) *clusterPool {
+	return &clusterPool{
+		opts:  o,
+		nodes: make(map[string]*redis.Pool),
+	}
+}
+
+func (p *clusterPool) Get() redis.Conn {
+	p.mu.RLock()
+	ready := len(p.nodes) > 0
+	p.mu.RUnlock()
+
+	if !ready {
+		if err := p.refresh(); err != nil {
+			return errorConn{err}
+		}
+	}
+
+	return &clusterConn{p: p}
+}
+
+func (p *clusterPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for addr, node := range p.nodes {
+		if e := node.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(p.nodes, addr)
+	}
+	return err
+}
+
+func (p *clusterPool) ForEachNode(fn func(c redis.Conn) error) error {
+	if err := p.refresh(); err != nil {
+		return err
+	}
+
+	for _, addr := range p.masters() {
+		c := p.node(addr).Get()
+		err := fn(c)
+		c.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *clusterPool) DialPubSub() (redis.Conn, error) {
+	var err error
+	for _, addr := range append(p.masters(), p.opts.addrs...) {
+		var c redis.Conn
+		c, err = p.opts.dial(addr, 0)
+		if err == nil {
+			return c, nil
+		}
+	}
+	return nil, err
+}
+
+// masters 返回当前 slot 表中的所有主节点地址
+func (p *clusterPool) masters() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	addrs := make([]string, 0)
+	for _, addr := range p.slots {
+		if addr == "" {
+			continue
+		}
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// node 返回节点的连接池, 不存在时创建
+func (p *clusterPool) node(addr string) *redis.Pool {
+	p.mu.RLock()
+	node, ok := p.nodes[addr]
+	p.mu.RUnlock()
+	if ok {
+		return node
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if node, ok = p.nodes[addr]; ok {
+		return node
+	}
+
+	o := p.opts
+	node = &redis.Pool{
+		MaxActive:   o.poolSize,
+		MaxIdle:     o.maxIdle,
+		IdleTimeout: o.idleTimeout,
+		Dial: func() (redis.Conn, error) {
+			return o.dial(addr, o.readTimeout)
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < time.Second {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+	p.nodes[addr] = node
+	return node
+}
+
+// slotAddr 返回负责 slot 的节点地址, 未知时返回任意一个节点
+func (p *clusterPool) slotAddr(slot int) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if slot >= 0 {
+		if addr := p.slots[slot]; addr != "" {
+			return addr
+		}
+	}
+
+	for addr := range p.nodes {
+		return addr
+	}
+	return p.opts.addrs[0]
+}
+
+func (p *clusterPool) setSlot(slot int, addr string) {
+	p.mu.Lock()
+	p.slots[slot] = addr
+	p.mu.Unlock()
+}
+
+// refresh 通过 CLUSTER SLOTS 重建 slot 表, 依次尝试已知节点和种子节点
+func (p *clusterPool) refresh() error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	var err error
+	for _, addr := range append(p.masters(), p.opts.addrs...) {
+		var slots [clusterSlots]string
+		if slots, err = p.fetchSlots(addr); err != nil {
+			continue
+		}
+
+		p.mu.Lock()
+		p.slots = slots
+		p.mu.Unlock()
+
+		for _, a := range slots {
+			if a != "" {
+				p.node(a)
+			}
+		}
+		return nil
+	}
+
+	if err == nil {
+		err = errors.New("redis cluster: no node available")
+	}
+	return err
+}
+
+func (p *clusterPool) fetchSlots(addr string) ([clusterSlots]string, error) {
+	var slots [clusterSlots]string
+
+	c := p.node(addr).Get()
+	defer c.Close()
+
+	ranges, err := redis.Values(c.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return slots, err
+	}
+
+	for _, r := range ranges {
+		values, err := redis.Values(r, nil)
+		if err != nil {
+			return slots, err
+		}
+		if len(values) < 3 {
+			continue
+		}
+
+		start, err := redis.Int(values[0], nil)
+		if err != nil {
+			return slots, err
+		}
+		end, err := redis.Int(values[1], nil)
+		if err != nil {
+			return slots, err
+		}
+		master, err := redis.Values(values[2], nil)
+		if err != nil || len(master) < 2 {
+			return slots, errors.New("redis cluster: invalid CLUSTER SLOTS reply")
+		}
+		host, err := redis.String(master[0], nil)
+		if err != nil {
+			return slots, err
+		}
+		port, err := redis.Int(master[1], nil)
+		if err != nil {
+			return slots, err
+		}
+		if host == "" {
+			// 节点未声明 ip 时沿用当前连接的主机
+			host, _, _ = net.SplitHostPort(addr)
+		}
+
+		nodeAddr := fmt.Sprintf("%s:%d", host, port)
+		for slot := start; slot <= end && slot < clusterSlots; slot++ {
+			slots[slot] = nodeAddr
+		}
+	}
+
+	return slots, nil
+}
+
+// refreshAsync 收到 MOVED 后在后台刷新 slot 表
+func (p *clusterPool) refreshAsync() {
+	if !atomic.CompareAndSwapInt32(&p.refreshing, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&p.refreshing, 0)
+		p.refresh()
+	}()
+}
+
+type clusterCommand struct {
+	name string
+	args []interface{}
+}
+
+type clusterReply struct {
+	reply interface{}
+	err   error
+}
+
+// clusterConn 路由到集群的逻辑连接, 每条命令从对应节点的连接池借用连接;
+// Send/Flush/Receive 在本地排队, 保持与 redigo 流水线相同的语义
+type clusterConn struct {
+	p       *clusterPool
+	pending []clusterCommand
+	replies []clusterReply
+}
+
+func (c *clusterConn) Close() error {
+	c.pending = nil
+	c.replies = nil
+	return nil
+}
+
+func (c *clusterConn) Err() error {
+	return nil
+}
+
+func (c *clusterConn) Send(cmd string, args ...interface{}) error {
+	c.pending = append(c.pending, clusterCommand{cmd, args})
+	return nil
+}
+
+func (c *clusterConn) Flush() error {
+	for _, pc := range c.pending {
+		reply, err := c.do(pc.name, pc.args...)
+		c.replies = append(c.replies, clusterReply{reply, err})
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *clusterConn) Receive() (interface{}, error) {
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+	if len(c.replies) == 0 {
+		return nil, errors.New("redis cluster: no pending reply")
+	}
+
+	r := c.replies[0]
+	c.replies = c.replies[1:]
+	return r.reply, r.err
+}
+
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := c.replies
+	c.replies = nil
+
+	if cmd == "" {
+		values := make([]interface{}, len(replies))
+		for i, r := range replies {
+			if r.err != nil {
+				if _, ok := r.err.(redis.Error); !ok {
+					return nil, r.err
+				}
+				values[i] = r.err
+				continue
+			}
+			values[i] = r.reply
+		}
+		return values, nil
+	}
+
+	reply, err := c.do(cmd, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range replies {
+		if r.err != nil {
+			return reply, r.err
+		}
+	}
+	return reply, nil
+}
+
+// do 执行单条命令, 跨 slot 的多 key 命令拆分后合并结果
+func (c *clusterConn) do(cmd string, args ...interface{}) (interface{}, error) {
+	switch strings.ToUpper(cmd) {
+	case "MGET":
+		if len(args) > 1 {
+			values := make([]interface{}, len(args))
+			for i, key := range args {
+				v, err := c.route("GET", key)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = v
+			}
+			return values, nil
+		}
+	case "DEL", "UNLINK", "EXISTS", "TOUCH":
+		if len(args) > 1 {
+			var total int64
+			for _, key := range args {
+				n, err := redis.Int64(c.route(cmd, key))
+				if err != nil {
+					return nil, err
+				}
+				total += n
+			}
+			return total, nil
+		}
+	}
+
+	return c.route(cmd, args...)
+}
+
+// route 将命令发往 key 所在节点, 处理 MOVED/ASK 重定向
+func (c *clusterConn) route(cmd string, args ...interface{}) (interface{}, error) {
+	slot := -1
+	if key, ok := commandKey(cmd, args); ok {
+		slot = keySlot(key)
+	}
+
+	addr := c.p.slotAddr(slot)
+	asking := false
+
+	var lastErr error
+	for i := 0; i <= clusterMaxRedirects; i++ {
+		conn := c.p.node(addr).Get()
+		if asking {
+			if _, err := conn.Do("ASKING"); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+		reply, err := conn.Do(cmd, args...)
+		conn.Close()
+
+		re, ok := err.(redis.Error)
+		if !ok {
+			return reply, err
+		}
+		lastErr = err
+
+		msg := string(re)
+		switch {
+		case strings.HasPrefix(msg, "MOVED "), strings.HasPrefix(msg, "ASK "):
+			fields := strings.Fields(msg)
+			if len(fields) != 3 {
+				return reply, err
+			}
+			movedSlot, convErr := strconv.Atoi(fields[1])
+			if convErr != nil {
+				return reply, err
+			}
+
+			addr = fields[2]
+			asking = fields[0] == "ASK"
+			if !asking {
+				c.p.setSlot(movedSlot, addr)
+				c.p.refreshAsync()
+			}
+		case strings.HasPrefix(msg, "TRYAGAIN"), strings.HasPrefix(msg, "CLUSTERDOWN"):
+			time.Sleep(clusterRetryBackoff)
+			asking = false
+		default:
+			return reply, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+// commandKey 取命令的第一个 key, 用于计算 slot
+func commandKey(cmd string, args []interface{}) (string, bool) {
+	switch strings.ToUpper(cmd) {
+	case "EVAL", "EVALSHA":
+		if len(args) < 3 {
+			return "", false
+		}
+		n, err := redis.Int(args[1], nil)
+		if err != nil || n == 0 {
+			return "", false
+		}
+		return keyString(args[2]), true
+	case "PING", "PUBLISH", "SCAN", "INFO", "CLUSTER", "SCRIPT", "ASKING":
+		return "", false
+	}
+
+	if len(args) == 0 {
+		return "", false
+	}
+	return keyString(args[0]), true
+}
+
+func keyString(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	default:
+		return fmt.Sprint(k)
+	}
+}
+
+// keySlot 计算 key 的 slot, 只对 {hashtag} 中的内容做 hash
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % clusterSlots)
+}
+
+// crc16 CRC16-CCITT (XMODEM), redis cluster 规范使用的校验算法
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// errorConn 获取连接失败时返回, 所有操作都返回该错误
+type errorConn struct{ err error }
+
+func (c errorConn) Do(string, ...interface{}) (interface{}, error) { return nil, c.err }
+func (c errorConn) Send(string, ...interface{}) error              { return c.err }
+func (c errorConn) Err() error                                     { return c.err }
+func (c errorConn) Close() error                                   { return nil }
+func (c errorConn) Flush() error                                   { return c.err }
+func (c errorConn) Receive() (interface{}, error)                  { return nil, c.err }
@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/garyburd/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	defaultLoadLockExpiry   = 10 * time.Second
+	defaultLoadWaitInterval = 50 * time.Millisecond
+)
+
+// 只有锁的持有者才能释放锁
+var releaseScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func (m *RedisCache) GetOrLoad(ctx context.Context, key string, resultPtr interface{}, loader cache.Loader, opts ...cache.WriteOption) error {
+	if m.Get(key, resultPtr, cache.ReadContext(ctx)) {
+		return nil
+	}
+
+	// 进程内合并, 同一个 key 只有一个协程去竞争分布式锁
+	data, err, _ := m.group.Do(m.prefix(key), func() (interface{}, error) {
+		return m.load(ctx, key, loader, opts...)
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.codec().Unmarshal(data.([]byte), resultPtr)
+}
+
+// load 在实例间协调加载, 只有获取到锁的实例执行 loader, 其他实例等待结果
+func (m *RedisCache) load(ctx context.Context, key string, loader cache.Loader, opts ...cache.WriteOption) ([]byte, error) {
+	lockExpiry := m.loadLockExpiry()
+
+	waitInterval, ok := m.options.Context.Value(loadWaitIntervalKey{}).(time.Duration)
+	if !ok || waitInterval <= 0 {
+		waitInterval = defaultLoadWaitInterval
+	}
+
+	lockKey := fmt.Sprintf("%s:lock", m.prefix(key))
+	token := uuid.NewV4().String()
+
+	for {
+		if data, err := m.getBytes(key); err == nil {
+			return data, nil
+		}
+
+		locked, err := m.tryLock(lockKey, token, lockExpiry)
+		if err != nil {
+			return nil, err
+		}
+
+		if locked {
+			return m.loadLocked(ctx, key, lockKey, token, loader, opts...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(waitInterval):
+		}
+	}
+}
+
+func (m *RedisCache) loadLocked(ctx context.Context, key, lockKey, token string, loader cache.Loader, opts ...cache.WriteOption) ([]byte, error) {
+	defer m.unlock(lockKey, token)
+
+	// 获取锁之前可能已经有其他实例完成了加载
+	if data, err := m.getBytes(key); err == nil {
+		return data, nil
+	}
+
+	value, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := m.codec().Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.setBytes(key, data, opts...); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (m *RedisCache) loadLockExpiry() time.Duration {
+	lockExpiry, ok := m.options.Context.Value(loadLockExpiryKey{}).(time.Duration)
+	if !ok || lockExpiry <= 0 {
+		return defaultLoadLockExpiry
+	}
+	return lockExpiry
+}
+
+func (m *RedisCache) tryLock(lockKey, token string, expiry time.Duration) (bool, error) {
+	c := m.r.Get()
+	defer c.Close()
+
+	_, err := redis.String(c.Do("SET", lockKey, token, "NX", "PX", int64(expiry/time.Millisecond)))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (m *RedisCache) unlock(lockKey, token string) error {
+	c := m.r.Get()
+	defer c.Close()
+
+	_, err := releaseScript.Do(c, lockKey, token)
+	return err
+}
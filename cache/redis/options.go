@@ -2,6 +2,8 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"time"
 
 	"github.com/duolacloud/microbase/cache"
 )
@@ -20,3 +22,115 @@ func WithPassword(password string) cache.Option {
 		o.Context = context.WithValue(o.Context, passwordKey{}, password)
 	}
 }
+
+type modeKey struct{}
+type masterNameKey struct{}
+type sentinelPasswordKey struct{}
+type dbKey struct{}
+type poolSizeKey struct{}
+type maxIdleKey struct{}
+type idleTimeoutKey struct{}
+type dialTimeoutKey struct{}
+type readTimeoutKey struct{}
+type writeTimeoutKey struct{}
+type tlsConfigKey struct{}
+
+const (
+	// ModeStandalone 单机模式, 连接 addrs 中的第一个地址
+	ModeStandalone = "standalone"
+	// ModeSentinel 哨兵模式, addrs 为哨兵地址, 通过 master name 发现主节点
+	ModeSentinel = "sentinel"
+	// ModeCluster 集群模式, addrs 为种子节点, 按 slot 路由命令
+	ModeCluster = "cluster"
+)
+
+// WithMode 设置部署模式: standalone, sentinel, cluster
+func WithMode(mode string) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, modeKey{}, mode)
+	}
+}
+
+// WithMasterName 哨兵模式下主节点的名称
+func WithMasterName(name string) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, masterNameKey{}, name)
+	}
+}
+
+// WithSentinelPassword 哨兵节点的密码
+func WithSentinelPassword(password string) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, sentinelPasswordKey{}, password)
+	}
+}
+
+// WithDB 选择数据库, 集群模式不支持
+func WithDB(db int) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, dbKey{}, db)
+	}
+}
+
+// WithPoolSize 每个节点的最大连接数, 0 表示不限制
+func WithPoolSize(size int) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, poolSizeKey{}, size)
+	}
+}
+
+// WithMaxIdle 每个节点的最大空闲连接数
+func WithMaxIdle(maxIdle int) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, maxIdleKey{}, maxIdle)
+	}
+}
+
+// WithIdleTimeout 空闲连接的关闭时间
+func WithIdleTimeout(timeout time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, idleTimeoutKey{}, timeout)
+	}
+}
+
+func WithDialTimeout(timeout time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, dialTimeoutKey{}, timeout)
+	}
+}
+
+func WithReadTimeout(timeout time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, readTimeoutKey{}, timeout)
+	}
+}
+
+func WithWriteTimeout(timeout time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, writeTimeoutKey{}, timeout)
+	}
+}
+
+// WithTLSConfig 使用 TLS 连接
+func WithTLSConfig(config *tls.Config) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, tlsConfigKey{}, config)
+	}
+}
+
+type loadLockExpiryKey struct{}
+type loadWaitIntervalKey struct{}
+
+// WithLoadLockExpiry 设置 GetOrLoad 分布式加载锁的过期时间
+func WithLoadLockExpiry(expiry time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, loadLockExpiryKey{}, expiry)
+	}
+}
+
+// WithLoadWaitInterval 设置 GetOrLoad 等待其他实例加载完成时的轮询间隔
+func WithLoadWaitInterval(interval time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, loadWaitIntervalKey{}, interval)
+	}
+}
@@ -0,0 +1,251 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/garyburd/redigo/redis"
+)
+
+// Pool redis 连接池, 屏蔽单机、哨兵和集群模式的差异
+type Pool interface {
+	Get() redis.Conn
+	// ForEachNode 在每个主节点的连接上执行 fn, 非集群模式只有一个节点
+	ForEachNode(fn func(c redis.Conn) error) error
+	// DialPubSub 新建一条不带读超时的连接, 用于订阅
+	DialPubSub() (redis.Conn, error)
+	Close() error
+}
+
+type poolOptions struct {
+	mode             string
+	addrs            []string
+	password         string
+	masterName       string
+	sentinelPassword string
+	db               int
+	poolSize         int
+	maxIdle          int
+	idleTimeout      time.Duration
+	dialTimeout      time.Duration
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	tlsConfig        *tls.Config
+}
+
+func newPoolOptions(ctx context.Context) poolOptions {
+	o := poolOptions{
+		mode:        ModeStandalone,
+		maxIdle:     3,
+		idleTimeout: 240 * time.Second,
+		dialTimeout: 5 * time.Second,
+	}
+
+	if v, ok := ctx.Value(modeKey{}).(string); ok && v != "" {
+		o.mode = v
+	}
+	if v, ok := ctx.Value(addrsKey{}).([]string); ok {
+		o.addrs = v
+	}
+	if len(o.addrs) == 0 {
+		o.addrs = []string{":6379"}
+	}
+	o.password, _ = ctx.Value(passwordKey{}).(string)
+	o.masterName, _ = ctx.Value(masterNameKey{}).(string)
+	o.sentinelPassword, _ = ctx.Value(sentinelPasswordKey{}).(string)
+	o.db, _ = ctx.Value(dbKey{}).(int)
+	o.poolSize, _ = ctx.Value(poolSizeKey{}).(int)
+	if v, ok := ctx.Value(maxIdleKey{}).(int); ok {
+		o.maxIdle = v
+	}
+	if v, ok := ctx.Value(idleTimeoutKey{}).(time.Duration); ok && v > 0 {
+		o.idleTimeout = v
+	}
+	if v, ok := ctx.Value(dialTimeoutKey{}).(time.Duration); ok && v > 0 {
+		o.dialTimeout = v
+	}
+	o.readTimeout, _ = ctx.Value(readTimeoutKey{}).(time.Duration)
+	o.writeTimeout, _ = ctx.Value(writeTimeoutKey{}).(time.Duration)
+	o.tlsConfig, _ = ctx.Value(tlsConfigKey{}).(*tls.Config)
+
+	return o
+}
+
+// NewPool 按照 cache 的 redis 配置创建连接池, 其他组件 (如分布式锁) 可以复用同一套配置
+func NewPool(opts ...cache.Option) (Pool, error) {
+	options := cache.Options{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return newPool(newPoolOptions(options.Context))
+}
+
+func newPool(o poolOptions) (Pool, error) {
+	switch o.mode {
+	case ModeStandalone:
+		return newSinglePool(o, func() (string, error) {
+			return o.addrs[0], nil
+		}), nil
+	case ModeSentinel:
+		if o.masterName == "" {
+			return nil, errors.New("redis sentinel mode requires master name")
+		}
+		return newSinglePool(o, o.sentinelMaster), nil
+	case ModeCluster:
+		if o.db != 0 {
+			return nil, errors.New("redis cluster mode does not support db")
+		}
+		return newClusterPool(o), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown redis mode: %s", o.mode))
+	}
+}
+
+// dial 连接单个节点并完成认证和选库
+func (o *poolOptions) dial(addr string, readTimeout time.Duration) (redis.Conn, error) {
+	dialOpts := []redis.DialOption{
+		redis.DialConnectTimeout(o.dialTimeout),
+		redis.DialReadTimeout(readTimeout),
+		redis.DialWriteTimeout(o.writeTimeout),
+		redis.DialDatabase(o.db),
+	}
+	if o.password != "" {
+		dialOpts = append(dialOpts, redis.DialPassword(o.password))
+	}
+	if o.tlsConfig != nil {
+		dialOpts = append(dialOpts,
+			redis.DialUseTLS(true),
+			redis.DialTLSConfig(o.tlsConfig),
+			redis.DialTLSSkipVerify(o.tlsConfig.InsecureSkipVerify),
+		)
+	}
+
+	return redis.Dial("tcp", addr, dialOpts...)
+}
+
+// sentinelMaster 依次询问哨兵, 返回当前主节点地址
+func (o *poolOptions) sentinelMaster() (string, error) {
+	for _, addr := range o.addrs {
+		dialOpts := []redis.DialOption{
+			redis.DialConnectTimeout(o.dialTimeout),
+			redis.DialReadTimeout(o.dialTimeout),
+			redis.DialWriteTimeout(o.dialTimeout),
+		}
+		if o.sentinelPassword != "" {
+			dialOpts = append(dialOpts, redis.DialPassword(o.sentinelPassword))
+		}
+
+		c, err := redis.Dial("tcp", addr, dialOpts...)
+		if err != nil {
+			continue
+		}
+
+		res, err := redis.Strings(c.Do("SENTINEL", "get-master-addr-by-name", o.masterName))
+		c.Close()
+		if err != nil || len(res) != 2 {
+			continue
+		}
+
+		return net.JoinHostPort(res[0], res[1]), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("no sentinel available for master %s", o.masterName))
+}
+
+// checkRole 确认连接的是主节点, 故障转移后旧主会降级为从节点
+func checkRole(c redis.Conn) error {
+	values, err := redis.Values(c.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return errors.New("redis: empty ROLE reply")
+	}
+
+	role, err := redis.String(values[0], nil)
+	if err != nil {
+		return err
+	}
+	if role != "master" {
+		return errors.New(fmt.Sprintf("redis: node role is %s, not master", role))
+	}
+	return nil
+}
+
+// singlePool 单机和哨兵模式的连接池, 两者只是主节点地址的来源不同
+type singlePool struct {
+	*redis.Pool
+	opts       poolOptions
+	masterAddr func() (string, error)
+}
+
+func newSinglePool(o poolOptions, masterAddr func() (string, error)) *singlePool {
+	p := &singlePool{
+		opts:       o,
+		masterAddr: masterAddr,
+	}
+
+	sentinel := o.mode == ModeSentinel
+
+	p.Pool = &redis.Pool{
+		MaxActive:   o.poolSize,
+		MaxIdle:     o.maxIdle,
+		IdleTimeout: o.idleTimeout,
+		Dial: func() (redis.Conn, error) {
+			return p.dial(o.readTimeout)
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < time.Second {
+				return nil
+			}
+			if sentinel {
+				return checkRole(c)
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+
+	return p
+}
+
+func (p *singlePool) dial(readTimeout time.Duration) (redis.Conn, error) {
+	addr, err := p.masterAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := p.opts.dial(addr, readTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.mode == ModeSentinel {
+		if err := checkRole(c); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (p *singlePool) ForEachNode(fn func(c redis.Conn) error) error {
+	c := p.Get()
+	defer c.Close()
+
+	return fn(c)
+}
+
+func (p *singlePool) DialPubSub() (redis.Conn, error) {
+	return p.dial(0)
+}
@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// Publish 向 channel 发布消息
+func (m *RedisCache) Publish(channel string, message []byte) error {
+	c := m.r.Get()
+	defer c.Close()
+
+	_, err := c.Do("PUBLISH", channel, message)
+	return err
+}
+
+// Subscribe 订阅 channel, 阻塞直到 ctx 结束或连接出错
+func (m *RedisCache) Subscribe(ctx context.Context, channel string, handler func(message []byte)) error {
+	conn, err := m.r.DialPubSub()
+	if err != nil {
+		return err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	if err := psc.Subscribe(channel); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				handler(v.Data)
+			case redis.Subscription:
+				if v.Count == 0 {
+					done <- nil
+					return
+				}
+			case error:
+				done <- v
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		psc.Unsubscribe()
+		<-done
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
@@ -2,18 +2,22 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/cache/codec"
 	"github.com/garyburd/redigo/redis"
 )
 
+var defaultCodec = codec.NewJSONCodec()
+
 type RedisCache struct {
-	options cache.Options
-	r       *redis.Pool
+	cache.Contextual
+
+	options    cache.Options
+	r          Pool
+	group      cache.Group
+	refreshing cache.Refreshing
 }
 
 func NewCache(opts ...cache.Option) cache.Cache {
@@ -25,9 +29,11 @@ func NewCache(opts ...cache.Option) cache.Cache {
 		o(&options)
 	}
 
-	return &RedisCache{
+	m := &RedisCache{
 		options: options,
 	}
+	m.Contextual = cache.Contextual{Cache: m}
+	return m
 }
 
 func (m *RedisCache) Init(opts ...cache.Option) error {
@@ -47,38 +53,8 @@ func (m *RedisCache) Options() cache.Options {
 	return m.options
 }
 
-func (m *RedisCache) connect() (*redis.Pool, error) {
-	addrs := m.options.Context.Value(addrsKey{}).([]string)
-	if len(addrs) == 0 {
-		addrs = []string{":6379"}
-	}
-
-	password, _ := m.options.Context.Value(passwordKey{}).(string)
-
-	pool := &redis.Pool{
-		MaxIdle:     3,
-		IdleTimeout: 240 * time.Second,
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", strings.Join(addrs, ","))
-			if err != nil {
-				return nil, err
-			}
-
-			if password != "" {
-				if _, err := c.Do("AUTH", password); err != nil {
-					c.Close()
-					return nil, err
-				}
-			}
-			return c, err
-		},
-		TestOnBorrow: func(c redis.Conn, t time.Time) error {
-			_, err := c.Do("PING")
-			return err
-		},
-	}
-
-	return pool, nil
+func (m *RedisCache) connect() (Pool, error) {
+	return newPool(newPoolOptions(m.options.Context))
 }
 
 func (m *RedisCache) prefix(key string) string {
@@ -88,6 +64,13 @@ func (m *RedisCache) prefix(key string) string {
 	return fmt.Sprintf("%s:%s", m.options.Prefix, key)
 }
 
+func (m *RedisCache) codec() cache.Codec {
+	if m.options.Codec != nil {
+		return m.options.Codec
+	}
+	return defaultCodec
+}
+
 func (m *RedisCache) String() string {
 	return "redis"
 }
@@ -95,7 +78,7 @@ func (m *RedisCache) String() string {
 func (m *RedisCache) Exists(key string) bool {
 	c := m.r.Get()
 	defer c.Close()
-	exists, err := redis.Bool(c.Do("EXISTS", key))
+	exists, err := redis.Bool(c.Do("EXISTS", m.prefix(key)))
 	if err != nil {
 		return false
 	}
@@ -109,16 +92,15 @@ func (m *RedisCache) Get(key string, resultPtr interface{}, opts ...cache.ReadOp
 		o(&readOpts)
 	}
 
-	key = m.prefix(key)
-
-	c := m.r.Get()
-	defer c.Close()
-	data, err := redis.Bytes(c.Do("GET", key))
+	data, stale, err := m.getStale(key)
 	if err != nil {
 		return false
 	}
+	if stale {
+		m.refresh(key, opts...)
+	}
 
-	err = json.Unmarshal(data, resultPtr)
+	err = m.codec().Unmarshal(data, resultPtr)
 	if err != nil {
 		return false
 	}
@@ -127,27 +109,37 @@ func (m *RedisCache) Get(key string, resultPtr interface{}, opts ...cache.ReadOp
 }
 
 func (m *RedisCache) Set(key string, value interface{}, opts ...cache.WriteOption) error {
+	data, err := m.codec().Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return m.setBytes(key, data, opts...)
+}
+
+func (m *RedisCache) getBytes(key string) ([]byte, error) {
+	key = m.prefix(key)
+
+	c := m.r.Get()
+	defer c.Close()
+
+	return redis.Bytes(c.Do("GET", key))
+}
+
+func (m *RedisCache) setBytes(key string, data []byte, opts ...cache.WriteOption) error {
 	writeOpts := cache.WriteOptions{}
 	for _, o := range opts {
 		o(&writeOpts)
 	}
 
-	key = m.prefix(key)
-
 	c := m.r.Get()
 	defer c.Close()
 
-	data, err := json.Marshal(value)
-	if err != nil {
+	if err := m.sendSet(c, key, data, writeOpts); err != nil {
 		return err
 	}
 
-	if writeOpts.Expiry > 0 {
-		_, err = c.Do("SETEX", key, writeOpts.Expiry.Seconds(), data)
-	} else {
-		_, err = c.Do("SET", key, data)
-	}
-	return err
+	return flush(c)
 }
 
 func (m *RedisCache) Close() error {
@@ -160,11 +152,8 @@ func (m *RedisCache) Delete(key string, opts ...cache.DeleteOption) error {
 		o(&deleteOptions)
 	}
 
-	key = m.prefix(key)
-
 	c := m.r.Get()
 	defer c.Close()
 
-	_, err := c.Do("DEL", key)
-	return err
+	return m.deleteKeys(c, m.prefix(key))
 }
@@ -36,3 +36,20 @@ func TestBasic(t *testing.T) {
 	ok = s.Get(key, &value2)
 	assert.Equal(t, false, ok, "Expected no records in redis store")
 }
+
+func TestTags(t *testing.T) {
+	s := redis.NewCache(redis.WithAddrs(":6379"))
+	s.Init()
+
+	assert.NoError(t, s.Set("tagged:1", 1, cache.WriteExpiry(time.Minute), cache.WriteTags("t1", "t2")))
+	assert.NoError(t, s.Set("tagged:2", 2, cache.WriteExpiry(time.Minute), cache.WriteTags("t1")))
+
+	// 删除 key 时从所有标签索引中移除
+	assert.NoError(t, s.Delete("tagged:1"))
+	keys, err := s.(*redis.RedisCache).TaggedKeys("t1", "t2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tagged:2"}, keys)
+
+	assert.NoError(t, s.InvalidateTags("t1"))
+	assert.False(t, s.Exists("tagged:2"))
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/logger"
+	"github.com/garyburd/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+)
+
+// softKey 保存软过期时间 (毫秒时间戳) 的 key, 与数据 key 同时过期
+func softKey(key string) string {
+	return fmt.Sprintf("%s:soft", key)
+}
+
+// stale 根据软过期标记判断数据是否已经软过期
+func stale(deadline []byte) bool {
+	if deadline == nil {
+		return false
+	}
+
+	ms, err := strconv.ParseInt(string(deadline), 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().UnixNano()/int64(time.Millisecond) > ms
+}
+
+// getStale 读取数据, 注册了加载函数的 key 同时读取软过期标记
+func (m *RedisCache) getStale(key string) ([]byte, bool, error) {
+	if _, _, ok := m.options.Loaders.Match(key); !ok {
+		data, err := m.getBytes(key)
+		return data, false, err
+	}
+
+	key = m.prefix(key)
+
+	c := m.r.Get()
+	defer c.Close()
+
+	values, err := redis.ByteSlices(c.Do("MGET", key, softKey(key)))
+	if err != nil {
+		return nil, false, err
+	}
+	if values[0] == nil {
+		return nil, false, redis.ErrNil
+	}
+
+	return values[0], stale(values[1]), nil
+}
+
+// refresh 软过期后在后台通过注册的加载函数刷新,
+// 进程内合并并通过分布式锁保证所有实例中只有一个刷新
+func (m *RedisCache) refresh(key string, readOpts ...cache.ReadOption) {
+	loader, opts, ok := m.options.Loaders.Match(key)
+	if !ok {
+		return
+	}
+
+	ctx := cache.RefreshContext(readOpts...)
+	m.refreshing.Go(m.prefix(key), func() {
+		lockKey := fmt.Sprintf("%s:refresh", m.prefix(key))
+		token := uuid.NewV4().String()
+
+		locked, err := m.tryLock(lockKey, token, m.loadLockExpiry())
+		if err != nil || !locked {
+			return
+		}
+		defer m.unlock(lockKey, token)
+
+		value, err := loader(ctx, key)
+		if err != nil {
+			logger.Warnf("redis cache refresh key: %s, err: %v", key, err)
+			return
+		}
+
+		if err = m.Set(key, value, opts...); err != nil {
+			logger.Warnf("redis cache refresh key: %s, err: %v", key, err)
+		}
+	})
+}
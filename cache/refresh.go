@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyLoader 按 key 加载数据, 用于软过期后的后台刷新
+type KeyLoader func(ctx context.Context, key string) (interface{}, error)
+
+type registeredLoader struct {
+	loader KeyLoader
+	opts   []WriteOption
+}
+
+// Loaders 按 key 前缀注册的加载函数, 匹配时最长前缀优先
+type Loaders struct {
+	mu      sync.RWMutex
+	loaders map[string]registeredLoader
+}
+
+func NewLoaders() *Loaders {
+	return &Loaders{
+		loaders: make(map[string]registeredLoader),
+	}
+}
+
+func (l *Loaders) Register(prefix string, loader KeyLoader, opts ...WriteOption) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.loaders[prefix] = registeredLoader{loader, opts}
+}
+
+// Match 返回 key 对应的加载函数和刷新时的写入选项
+func (l *Loaders) Match(key string) (KeyLoader, []WriteOption, bool) {
+	if l == nil {
+		return nil, nil, false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var (
+		match string
+		found registeredLoader
+		ok    bool
+	)
+	for prefix, r := range l.loaders {
+		if strings.HasPrefix(key, prefix) && (!ok || len(prefix) > len(match)) {
+			match, found, ok = prefix, r, true
+		}
+	}
+	return found.loader, found.opts, ok
+}
+
+// Refreshing 正在后台刷新的 key, 同一个 key 同时只启动一个刷新的 goroutine
+type Refreshing struct {
+	keys sync.Map
+}
+
+// Go key 没有在刷新时启动 goroutine 执行 fn, 否则直接返回
+func (r *Refreshing) Go(key string, fn func()) {
+	if _, loaded := r.keys.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer r.keys.Delete(key)
+		fn()
+	}()
+}
+
+// RefreshContext 后台刷新使用的 ctx, 保留调用方 ctx 中的值 (租户、span 等),
+// 但不随调用方的请求结束而取消
+func RefreshContext(opts ...ReadOption) context.Context {
+	readOpts := ReadOptions{}
+	for _, o := range opts {
+		o(&readOpts)
+	}
+
+	if readOpts.Context == nil {
+		return context.Background()
+	}
+	return Detach(readOpts.Context)
+}
+
+// Detach 保留 ctx 中的值 (租户、span 等), 但不会被取消, 用于请求结束后仍要执行的后台操作
+func Detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
+
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
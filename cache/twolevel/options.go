@@ -0,0 +1,25 @@
+package twolevel
+
+import (
+	"context"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+)
+
+type l1ExpiryKey struct{}
+type channelKey struct{}
+
+// WithL1Expiry 设置本地缓存的最长过期时间, 用于限制丢失失效通知时的脏读时长
+func WithL1Expiry(expiry time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, l1ExpiryKey{}, expiry)
+	}
+}
+
+// WithChannel 设置广播失效通知的 redis channel
+func WithChannel(channel string) cache.Option {
+	return func(o *cache.Options) {
+		o.Context = context.WithValue(o.Context, channelKey{}, channel)
+	}
+}
@@ -0,0 +1,384 @@
+package twolevel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/cache/memory"
+	"github.com/duolacloud/microbase/cache/redis"
+	"github.com/duolacloud/microbase/logger"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	defaultL1Expiry = time.Minute
+	defaultChannel  = "microbase:cache:invalidate"
+)
+
+// Broadcaster 在节点间广播消息
+type Broadcaster interface {
+	Publish(channel string, message []byte) error
+	Subscribe(ctx context.Context, channel string, handler func(message []byte)) error
+}
+
+type invalidation struct {
+	Node     string   `json:"node"`
+	Keys     []string `json:"keys,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// TwoLevelCache 进程内缓存(L1) + redis(L2) 的二级缓存
+// 写入和删除时通过 redis pub/sub 通知其他节点淘汰 L1
+type TwoLevelCache struct {
+	cache.Contextual
+
+	options cache.Options
+	node    string
+	l1      cache.Cache
+	l2      cache.Cache
+	cancel  context.CancelFunc
+}
+
+func NewCache(opts ...cache.Option) cache.Cache {
+	options := cache.Options{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	m := &TwoLevelCache{
+		options: options,
+		node:    uuid.NewV4().String(),
+		l1:      memory.NewCache(opts...),
+		l2:      redis.NewCache(opts...),
+	}
+	m.Contextual = cache.Contextual{Cache: m}
+	return m
+}
+
+func (m *TwoLevelCache) Init(opts ...cache.Option) error {
+	for _, o := range opts {
+		o(&m.options)
+	}
+
+	if err := m.l1.Init(opts...); err != nil {
+		return err
+	}
+
+	if err := m.l2.Init(opts...); err != nil {
+		return err
+	}
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	go m.subscribe(ctx)
+
+	return nil
+}
+
+func (m *TwoLevelCache) Options() cache.Options {
+	return m.options
+}
+
+func (m *TwoLevelCache) String() string {
+	return "twolevel"
+}
+
+func (m *TwoLevelCache) Exists(key string) bool {
+	return m.l1.Exists(key) || m.l2.Exists(key)
+}
+
+func (m *TwoLevelCache) Get(key string, resultPtr interface{}, opts ...cache.ReadOption) bool {
+	if m.l1.Get(key, resultPtr, opts...) {
+		return true
+	}
+
+	if !m.l2.Get(key, resultPtr, opts...) {
+		return false
+	}
+
+	m.fill(key, resultPtr)
+	return true
+}
+
+func (m *TwoLevelCache) Set(key string, value interface{}, opts ...cache.WriteOption) error {
+	if err := m.l2.Set(key, value, opts...); err != nil {
+		return err
+	}
+
+	if err := m.l1.Set(key, value, m.l1WriteOptions(opts...)...); err != nil {
+		return err
+	}
+
+	return m.broadcast(key)
+}
+
+func (m *TwoLevelCache) Delete(key string, opts ...cache.DeleteOption) error {
+	if err := m.l2.Delete(key, opts...); err != nil {
+		return err
+	}
+
+	if err := m.l1.Delete(key, opts...); err != nil {
+		return err
+	}
+
+	return m.broadcast(key)
+}
+
+func (m *TwoLevelCache) GetOrLoad(ctx context.Context, key string, resultPtr interface{}, loader cache.Loader, opts ...cache.WriteOption) error {
+	if m.l1.Get(key, resultPtr, cache.ReadContext(ctx)) {
+		return nil
+	}
+
+	var loaded bool
+	err := m.l2.GetOrLoad(ctx, key, resultPtr, func(ctx context.Context) (interface{}, error) {
+		loaded = true
+		return loader(ctx)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	m.fill(key, resultPtr, opts...)
+
+	// 新加载的数据需要通知其他节点淘汰可能存在的旧值
+	if loaded {
+		return m.broadcast(key)
+	}
+	return nil
+}
+
+func (m *TwoLevelCache) MGet(keys []string, resultPtr interface{}, opts ...cache.ReadOption) error {
+	if err := m.l1.MGet(keys, resultPtr, opts...); err != nil {
+		return err
+	}
+
+	results := reflect.ValueOf(resultPtr).Elem()
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !results.MapIndex(reflect.ValueOf(key)).IsValid() {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := m.l2.MGet(missing, resultPtr, opts...); err != nil {
+		return err
+	}
+
+	for _, key := range missing {
+		if v := results.MapIndex(reflect.ValueOf(key)); v.IsValid() {
+			if err := m.l1.Set(key, v.Interface(), m.l1WriteOptions()...); err != nil {
+				logger.Warnf("twolevel cache fill l1 key: %s, err: %v", key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *TwoLevelCache) MSet(values map[string]interface{}, opts ...cache.WriteOption) error {
+	if err := m.l2.MSet(values, opts...); err != nil {
+		return err
+	}
+
+	if err := m.l1.MSet(values, m.l1WriteOptions(opts...)...); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	return m.broadcast(keys...)
+}
+
+func (m *TwoLevelCache) DeleteByPrefix(prefix string) error {
+	if err := m.l2.DeleteByPrefix(prefix); err != nil {
+		return err
+	}
+
+	if err := m.l1.DeleteByPrefix(prefix); err != nil {
+		return err
+	}
+
+	return m.publish(&invalidation{Prefixes: []string{prefix}})
+}
+
+func (m *TwoLevelCache) InvalidateTags(tags ...string) error {
+	// 从 L2 回填到 L1 的数据没有标签, 需要同时按 key 淘汰
+	var keys []string
+	if t, ok := m.l2.(interface {
+		TaggedKeys(tags ...string) ([]string, error)
+	}); ok {
+		var err error
+		if keys, err = t.TaggedKeys(tags...); err != nil {
+			return err
+		}
+	}
+
+	if err := m.l2.InvalidateTags(tags...); err != nil {
+		return err
+	}
+
+	if err := m.l1.InvalidateTags(tags...); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := m.l1.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return m.publish(&invalidation{Keys: keys, Tags: tags})
+}
+
+// Close 停止订阅失效通知
+func (m *TwoLevelCache) Close() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	if closer, ok := m.l1.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+	if closer, ok := m.l2.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Stats 返回 L1 的统计数据
+func (m *TwoLevelCache) Stats() memory.Stats {
+	if r, ok := m.l1.(memory.StatsReporter); ok {
+		return r.Stats()
+	}
+	return memory.Stats{}
+}
+
+// fill 将 L2 读取到的数据回填到 L1
+func (m *TwoLevelCache) fill(key string, resultPtr interface{}, opts ...cache.WriteOption) {
+	value := reflect.ValueOf(resultPtr).Elem().Interface()
+	if err := m.l1.Set(key, value, m.l1WriteOptions(opts...)...); err != nil {
+		logger.Warnf("twolevel cache fill l1 key: %s, err: %v", key, err)
+	}
+}
+
+// l1WriteOptions L1 的过期时间不超过 l1Expiry
+// L1 不使用软过期, 由 L2 负责刷新, L1 过期后重新从 L2 读取
+func (m *TwoLevelCache) l1WriteOptions(opts ...cache.WriteOption) []cache.WriteOption {
+	writeOpts := cache.WriteOptions{}
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	l1Expiry, ok := m.options.Context.Value(l1ExpiryKey{}).(time.Duration)
+	if !ok || l1Expiry <= 0 {
+		l1Expiry = defaultL1Expiry
+	}
+
+	if writeOpts.Expiry <= 0 || writeOpts.Expiry > l1Expiry {
+		writeOpts.Expiry = l1Expiry
+	}
+
+	return append(opts, cache.WriteExpiry(writeOpts.Expiry), cache.WriteSoftExpiry(0))
+}
+
+func (m *TwoLevelCache) channel() string {
+	if channel, ok := m.options.Context.Value(channelKey{}).(string); ok && len(channel) > 0 {
+		return channel
+	}
+
+	if m.options.Prefix == "" {
+		return defaultChannel
+	}
+	return fmt.Sprintf("%s:%s", m.options.Prefix, defaultChannel)
+}
+
+func (m *TwoLevelCache) broadcast(keys ...string) error {
+	return m.publish(&invalidation{Keys: keys})
+}
+
+func (m *TwoLevelCache) publish(inv *invalidation) error {
+	b, ok := m.l2.(Broadcaster)
+	if !ok {
+		return nil
+	}
+
+	inv.Node = m.node
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(m.channel(), data)
+}
+
+func (m *TwoLevelCache) subscribe(ctx context.Context) {
+	b, ok := m.l2.(Broadcaster)
+	if !ok {
+		return
+	}
+
+	for {
+		err := b.Subscribe(ctx, m.channel(), m.onInvalidate)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// 订阅断开期间可能丢失通知, 清空 L1 保证一致性
+		logger.Warnf("twolevel cache subscribe err: %v", err)
+		m.flushL1()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (m *TwoLevelCache) onInvalidate(message []byte) {
+	var inv invalidation
+	if err := json.Unmarshal(message, &inv); err != nil {
+		logger.Warnf("twolevel cache invalid message: %s", string(message))
+		return
+	}
+
+	if inv.Node == m.node {
+		return
+	}
+
+	for _, key := range inv.Keys {
+		m.l1.Delete(key)
+	}
+
+	for _, prefix := range inv.Prefixes {
+		m.l1.DeleteByPrefix(prefix)
+	}
+
+	if len(inv.Tags) > 0 {
+		m.l1.InvalidateTags(inv.Tags...)
+	}
+}
+
+func (m *TwoLevelCache) flushL1() {
+	if f, ok := m.l1.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
@@ -3,13 +3,17 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/duolacloud/microbase/domain/entity"
 	"github.com/duolacloud/microbase/proto/pagination"
 	"github.com/duolacloud/microbase/proto/search"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/micro/go-micro/v2/client"
+	merrors "github.com/micro/go-micro/v2/errors"
 	"github.com/thoas/go-funk"
 )
 
@@ -19,6 +23,15 @@ type SearchClient interface {
 	Update(c context.Context, document *Document) error
 	Get(c context.Context, index, typ, id string) (*Document, error)
 	Delete(c context.Context, index, typ, id string) error
+	// 批量写入和删除, 返回与入参一一对应的错误, BatchCreate 中已经存在的文档返回 ErrExists
+	BatchCreate(c context.Context, documents []*Document) ([]error, error)
+	BatchUpsert(c context.Context, documents []*Document) ([]error, error)
+	BatchDelete(c context.Context, keys []*DocumentKey) ([]error, error)
+	// 按过滤条件统计、批量更新和删除, 更新时 increments 中的字段加一
+	Count(c context.Context, filter map[string]interface{}, index, typ string) (int64, error)
+	UpdateByQuery(c context.Context, filter map[string]interface{}, fields map[string]interface{}, index, typ string, increments ...string) (int64, error)
+	DeleteByQuery(c context.Context, filter map[string]interface{}, index, typ string) (int64, error)
+	Aggregate(c context.Context, query *entity.AggregateQuery, index, typ string) ([]*entity.AggregateBucket, error)
 	List(c context.Context, query *entity.CursorQuery, index, typ string) (items []*Document, extra *entity.CursorExtra, err error)
 	Connection(c context.Context, query *entity.ConnectionQuery, index, typ string) (*entity.Connection, error)
 	Page(c context.Context, query *entity.PageQuery, index, typ string) (docs []*Document, total int64, err error)
@@ -28,6 +41,20 @@ type SearchClient interface {
 	IndexExists(c context.Context, index string) (bool, error)
 }
 
+// ErrConflict 写入时文档已经被修改, 服务端以 409 返回
+var ErrConflict = errors.New("document version conflict")
+
+// ErrExists 创建的文档已经存在, 服务端以 409 返回
+var ErrExists = errors.New("document already exists")
+
+// conflict 把服务端返回的 409 转换为 ErrConflict
+func conflict(err error) error {
+	if err != nil && merrors.Parse(err.Error()).Code == http.StatusConflict {
+		return ErrConflict
+	}
+	return err
+}
+
 type searchClient struct {
 	searchService search.SearchService
 }
@@ -45,11 +72,13 @@ func (s *searchClient) Create(c context.Context, document *Document) error {
 	}
 
 	_, err = s.searchService.Create(c, &search.Document{
-		Index:  document.Index,
-		Type:   document.Type,
-		Fields: string(b),
+		Index:       document.Index,
+		Type:        document.Type,
+		Fields:      string(b),
+		SeqNo:       document.SeqNo,
+		PrimaryTerm: document.PrimaryTerm,
 	})
-	return err
+	return conflict(err)
 }
 
 func (s *searchClient) Upsert(c context.Context, document *Document) error {
@@ -59,11 +88,13 @@ func (s *searchClient) Upsert(c context.Context, document *Document) error {
 	}
 
 	_, err = s.searchService.Upsert(c, &search.Document{
-		Index:  document.Index,
-		Type:   document.Type,
-		Fields: string(b),
+		Index:       document.Index,
+		Type:        document.Type,
+		Fields:      string(b),
+		SeqNo:       document.SeqNo,
+		PrimaryTerm: document.PrimaryTerm,
 	})
-	return err
+	return conflict(err)
 }
 
 func (s *searchClient) Update(c context.Context, document *Document) error {
@@ -73,11 +104,13 @@ func (s *searchClient) Update(c context.Context, document *Document) error {
 	}
 
 	_, err = s.searchService.Update(c, &search.Document{
-		Index:  document.Index,
-		Type:   document.Type,
-		Fields: string(b),
+		Index:       document.Index,
+		Type:        document.Type,
+		Fields:      string(b),
+		SeqNo:       document.SeqNo,
+		PrimaryTerm: document.PrimaryTerm,
 	})
-	return err
+	return conflict(err)
 }
 
 func (s *searchClient) Get(c context.Context, index, typ, id string) (*Document, error) {
@@ -87,6 +120,9 @@ func (s *searchClient) Get(c context.Context, index, typ, id string) (*Document,
 		Id:    id,
 	})
 	if err != nil {
+		if merrors.Parse(err.Error()).Code == http.StatusNotFound {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -97,9 +133,11 @@ func (s *searchClient) Get(c context.Context, index, typ, id string) (*Document,
 	}
 
 	doc := &Document{
-		Index:  rsp.Index,
-		Type:   rsp.Type,
-		Fields: fields,
+		Index:       rsp.Index,
+		Type:        rsp.Type,
+		Fields:      fields,
+		SeqNo:       rsp.SeqNo,
+		PrimaryTerm: rsp.PrimaryTerm,
 	}
 
 	return doc, nil
@@ -114,6 +152,198 @@ func (s *searchClient) Delete(c context.Context, index, typ, id string) error {
 	return err
 }
 
+func (s *searchClient) BatchCreate(c context.Context, documents []*Document) ([]error, error) {
+	return s.batchUpsert(c, documents, true)
+}
+
+func (s *searchClient) BatchUpsert(c context.Context, documents []*Document) ([]error, error) {
+	return s.batchUpsert(c, documents, false)
+}
+
+func (s *searchClient) batchUpsert(c context.Context, documents []*Document, create bool) ([]error, error) {
+	req := &search.BatchUpsertDocumentRequest{
+		Document: make([]*search.Document, len(documents)),
+		Create:   create,
+	}
+	for i, document := range documents {
+		b, err := json.Marshal(document.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Document[i] = &search.Document{
+			Index:  document.Index,
+			Type:   document.Type,
+			Fields: string(b),
+		}
+	}
+
+	rsp, err := s.searchService.BatchUpsert(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(documents))
+	for i := range errs {
+		switch {
+		case i >= len(rsp.Items):
+			errs[i] = errors.New("no response for document")
+		case rsp.Items[i].Status == http.StatusConflict && create:
+			errs[i] = ErrExists
+		case rsp.Items[i].Status == http.StatusConflict:
+			errs[i] = ErrConflict
+		case rsp.Items[i].Error != "":
+			errs[i] = errors.New(rsp.Items[i].Error)
+		}
+	}
+	return errs, nil
+}
+
+func (s *searchClient) BatchDelete(c context.Context, keys []*DocumentKey) ([]error, error) {
+	req := &search.BatchDeleteDocumentRequest{
+		Documents: make([]*search.DeleteDocumentRequest, len(keys)),
+	}
+	for i, key := range keys {
+		req.Documents[i] = &search.DeleteDocumentRequest{
+			Index: key.Index,
+			Type:  key.Type,
+			Id:    key.Id,
+		}
+	}
+
+	rsp, err := s.searchService.BatchDelete(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(keys))
+	for i := range errs {
+		if i >= len(rsp.Items) {
+			errs[i] = errors.New("no response for document")
+		} else if rsp.Items[i].Error != "" {
+			errs[i] = errors.New(rsp.Items[i].Error)
+		}
+	}
+	return errs, nil
+}
+
+func (s *searchClient) Count(c context.Context, filter map[string]interface{}, index, typ string) (int64, error) {
+	filterB, err := json.Marshal(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, err := s.searchService.Count(c, &search.CountRequest{
+		Index:  index,
+		Type:   typ,
+		Filter: string(filterB),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rsp.Count, nil
+}
+
+func (s *searchClient) UpdateByQuery(c context.Context, filter map[string]interface{}, fields map[string]interface{}, index, typ string, increments ...string) (int64, error) {
+	filterB, err := json.Marshal(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	fieldsB, err := json.Marshal(fields)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, err := s.searchService.UpdateByQuery(c, &search.UpdateByQueryRequest{
+		Index:      index,
+		Type:       typ,
+		Filter:     string(filterB),
+		Fields:     string(fieldsB),
+		Increments: increments,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rsp.Affected, nil
+}
+
+func (s *searchClient) DeleteByQuery(c context.Context, filter map[string]interface{}, index, typ string) (int64, error) {
+	filterB, err := json.Marshal(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, err := s.searchService.DeleteByQuery(c, &search.DeleteByQueryRequest{
+		Index:  index,
+		Type:   typ,
+		Filter: string(filterB),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rsp.Affected, nil
+}
+
+func (s *searchClient) Aggregate(c context.Context, query *entity.AggregateQuery, index, typ string) ([]*entity.AggregateBucket, error) {
+	filterB, err := json.Marshal(query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &search.AggregateRequest{
+		Index:   index,
+		Type:    typ,
+		Filter:  string(filterB),
+		GroupBy: query.GroupBy,
+		Size:    int32(query.Size),
+		Metrics: funk.Map(query.Metrics, func(m *entity.Metric) *search.Metric {
+			return &search.Metric{
+				Func:  string(m.Func),
+				Field: m.Field,
+				Alias: m.Alias,
+			}
+		}).([]*search.Metric),
+	}
+
+	if query.Histogram != nil {
+		req.Histogram = &search.DateHistogram{
+			Field:    query.Histogram.Field,
+			Interval: string(query.Histogram.Interval),
+		}
+	}
+
+	rsp, err := s.searchService.Aggregate(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]*entity.AggregateBucket, len(rsp.Buckets))
+	for i, b := range rsp.Buckets {
+		bucket := &entity.AggregateBucket{}
+		if err = json.Unmarshal([]byte(b.Keys), &bucket.Keys); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(b.Metrics), &bucket.Metrics); err != nil {
+			return nil, err
+		}
+
+		// 时间分桶的值序列化为 RFC3339 字符串
+		if query.Histogram != nil {
+			if v, ok := bucket.Keys[query.Histogram.Field].(string); ok {
+				t, err := time.Parse(time.RFC3339Nano, v)
+				if err != nil {
+					return nil, err
+				}
+				bucket.Keys[query.Histogram.Field] = t
+			}
+		}
+
+		buckets[i] = bucket
+	}
+	return buckets, nil
+}
+
 func (s *searchClient) List(c context.Context, query *entity.CursorQuery, index, typ string) (items []*Document, extra *entity.CursorExtra, err error) {
 	filterB, err := json.Marshal(query.Filter)
 	if err != nil {
@@ -146,10 +376,11 @@ func (s *searchClient) List(c context.Context, query *entity.CursorQuery, index,
 					Direction: direction,
 				}
 			}).([]*pagination.Order),
-			Size:      int32(query.Size),
-			Direction: cursorDirection,
-			NeedTotal: query.NeedTotal,
-			Fields:    query.Fields,
+			Size:           int32(query.Size),
+			Direction:      cursorDirection,
+			NeedTotal:      query.NeedTotal,
+			Fields:         query.Fields,
+			IncludeDeleted: query.IncludeDeleted,
 		},
 	})
 
@@ -242,6 +473,8 @@ func (s *searchClient) Page(c context.Context, query *entity.PageQuery, index, t
 					Direction: direction,
 				}
 			}).([]*pagination.Order),
+			Fields:         query.Fields,
+			IncludeDeleted: query.IncludeDeleted,
 		},
 	})
 	if err != nil {
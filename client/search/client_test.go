@@ -0,0 +1,51 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/duolacloud/microbase/proto/search"
+	"github.com/micro/go-micro/v2/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSearchService struct {
+	search.SearchService
+	req *search.BatchUpsertDocumentRequest
+}
+
+func (s *fakeSearchService) BatchUpsert(c context.Context, in *search.BatchUpsertDocumentRequest, opts ...client.CallOption) (*search.BatchUpsertDocumentResponse, error) {
+	s.req = in
+	return &search.BatchUpsertDocumentResponse{
+		Items: []*search.UpsertDocumentResponse{
+			{Ack: true},
+			{Error: "version_conflict_engine_exception: document already exists", Status: http.StatusConflict},
+			{Error: "mapper_parsing_exception: failed to parse", Status: http.StatusBadRequest},
+		},
+	}, nil
+}
+
+func TestBatchCreate(t *testing.T) {
+	service := &fakeSearchService{}
+	client := NewSearchClient(service)
+
+	docs := []*Document{
+		{Index: "user", Type: "user", Fields: map[string]interface{}{"id": "1"}},
+		{Index: "user", Type: "user", Fields: map[string]interface{}{"id": "2"}},
+		{Index: "user", Type: "user", Fields: map[string]interface{}{"id": "3"}},
+	}
+
+	errs, err := client.BatchCreate(context.Background(), docs)
+	assert.NoError(t, err)
+	assert.Equal(t, true, service.req.Create)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, ErrExists, errs[1])
+	assert.Error(t, errs[2])
+
+	// upsert 中的 409 是版本冲突
+	errs, err = client.BatchUpsert(context.Background(), docs)
+	assert.NoError(t, err)
+	assert.Equal(t, false, service.req.Create)
+	assert.Equal(t, ErrConflict, errs[1])
+}
@@ -10,4 +10,15 @@ type Document struct {
 	Type   string                 `json:"type"`
 	Fields map[string]interface{} `json:"fields"`
 	Sort   []interface{}          `json:"sort"`
+
+	// 乐观锁, Get 时返回文档当前的值, 写入时 PrimaryTerm 不为 0 则只有文档没有被修改才写入
+	SeqNo       int64 `json:"seqNo"`
+	PrimaryTerm int64 `json:"primaryTerm"`
+}
+
+// DocumentKey 定位一个文档
+type DocumentKey struct {
+	Index string `json:"index"`
+	Type  string `json:"type"`
+	Id    string `json:"id"`
 }
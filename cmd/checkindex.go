@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duolacloud/microbase/domain/repository/indexed"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/urfave/cli/v2"
+)
+
+// CheckIndexCommand 比较实体表和搜索索引, 输出每个租户的差异, 例如
+//
+//	service checkindex --entity user --tenant t1 --repair
+//
+// check 在租户的 ctx 中检查一个实体, 通常调用 indexed.Indexer.Check,
+// 不修复时存在差异以错误退出, 便于定时任务报警
+func CheckIndexCommand(check func(c context.Context, entity string, repair bool) (*indexed.Report, error)) *cli.Command {
+	return &cli.Command{
+		Name:  "checkindex",
+		Usage: "check consistency between entity tables and search index",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "entity",
+				Usage:    "entity",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:  "tenant",
+				Usage: "tenant",
+			},
+			&cli.BoolFlag{
+				Name:  "repair",
+				Usage: "repair differences",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			tenants := c.StringSlice("tenant")
+			if len(tenants) == 0 {
+				tenants = []string{""}
+			}
+			repair := c.Bool("repair")
+
+			inconsistent := 0
+			for _, tenant := range tenants {
+				ctx := c.Context
+				if tenant != "" {
+					ctx = multitenancy.WithContext(ctx, tenant)
+				}
+
+				for _, entity := range c.StringSlice("entity") {
+					report, err := check(ctx, entity, repair)
+					if err != nil {
+						return errors.New(fmt.Sprintf("check %s of tenant %s error: %v", entity, tenant, err))
+					}
+
+					fmt.Println(report.String())
+					if !report.Consistent() {
+						fmt.Printf("  missing: %v\n  extra: %v\n  stale: %v\n", report.Missing, report.Extra, report.Stale)
+						inconsistent++
+					}
+				}
+			}
+
+			if inconsistent > 0 && !repair {
+				return errors.New(fmt.Sprintf("%d entities inconsistent with search index", inconsistent))
+			}
+			return nil
+		},
+	}
+}
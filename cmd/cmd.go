@@ -47,14 +47,20 @@ func init() {
 }
 
 func Run(cb func(c *cli.Context) error, flags []cli.Flag) {
+	RunCommands(cb, flags, nil)
+}
+
+// RunCommands 不带子命令时执行 cb, 子命令用于回填索引等运维操作
+func RunCommands(cb func(c *cli.Context) error, flags []cli.Flag, commands []*cli.Command) {
 	f := defaultFlags
 	for _, flag := range flags {
 		f = append(f, flag)
 	}
 
 	app := &cli.App{
-		Flags:  f,
-		Action: cb,
+		Flags:    f,
+		Action:   cb,
+		Commands: commands,
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/duolacloud/microbase/gen"
+	"github.com/urfave/cli/v2"
+)
+
+// GenCommand 根据实体生成类型化仓储、过滤字段白名单和 proto 转换, 例如
+//
+//	microbase gen --dir domain/entity --out domain/repository --package repository \
+//		--entity-import github.com/acme/user/domain/entity \
+//		--proto-import github.com/acme/user/proto/user --proto-dir proto/user
+func GenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "gen",
+		Usage: "generate typed repositories and proto mappers of entities",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "directory of entities",
+				Value: ".",
+			},
+			&cli.StringSliceFlag{
+				Name:  "type",
+				Usage: "entity types, default all structs with Unique method",
+			},
+			&cli.StringFlag{
+				Name:  "tag",
+				Usage: "tag of filter field names, json for gorm and search, bson for mongo",
+				Value: "json",
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "output directory, default same as dir",
+			},
+			&cli.StringFlag{
+				Name:  "package",
+				Usage: "output package name, default package of entities",
+			},
+			&cli.StringFlag{
+				Name:  "entity-import",
+				Usage: "import path of entities when output to another package",
+			},
+			&cli.StringFlag{
+				Name:  "proto-import",
+				Usage: "import path of protoc-gen-go package",
+			},
+			&cli.StringFlag{
+				Name:  "proto-dir",
+				Usage: "directory of protoc-gen-go package",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			files, err := gen.Write(&gen.Config{
+				Dir:          c.String("dir"),
+				Types:        c.StringSlice("type"),
+				Tag:          c.String("tag"),
+				Out:          c.String("out"),
+				Package:      c.String("package"),
+				EntityImport: c.String("entity-import"),
+				ProtoImport:  c.String("proto-import"),
+				ProtoDir:     c.String("proto-dir"),
+			})
+			for _, f := range files {
+				fmt.Println(f)
+			}
+			return err
+		},
+	}
+}
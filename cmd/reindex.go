@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/urfave/cli/v2"
+)
+
+// ReindexCommand 把实体表中已有的记录写入搜索索引, 例如
+//
+//	service reindex --entity user --tenant t1 --tenant t2
+//
+// reindex 在租户的 ctx 中回填一个实体, 通常调用 indexed.Indexer.Backfill
+func ReindexCommand(reindex func(c context.Context, entity string) (int64, error)) *cli.Command {
+	return &cli.Command{
+		Name:  "reindex",
+		Usage: "backfill search index of entities",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "entity",
+				Usage:    "entity",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:  "tenant",
+				Usage: "tenant",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			tenants := c.StringSlice("tenant")
+			if len(tenants) == 0 {
+				tenants = []string{""}
+			}
+
+			for _, tenant := range tenants {
+				ctx := c.Context
+				if tenant != "" {
+					ctx = multitenancy.WithContext(ctx, tenant)
+				}
+
+				for _, entity := range c.StringSlice("entity") {
+					n, err := reindex(ctx, entity)
+					if err != nil {
+						return errors.New(fmt.Sprintf("reindex %s of tenant %s error: %v", entity, tenant, err))
+					}
+					fmt.Printf("reindexed %d %s of tenant %s\n", n, entity, tenant)
+				}
+			}
+			return nil
+		},
+	}
+}
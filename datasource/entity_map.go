@@ -1,5 +1,96 @@
 package datasource
 
+import (
+	"reflect"
+	"time"
+)
+
 type EntityMap interface {
 	GetEntities() []interface{}
 }
+
+// CachePolicy 实体的缓存策略
+type CachePolicy struct {
+	// Expiry 缓存时间, 0 使用默认值
+	Expiry time.Duration
+	// NegativeExpiry 记录不存在时的缓存时间, 0 表示不缓存不存在的记录
+	NegativeExpiry time.Duration
+	// Disabled 不缓存该实体
+	Disabled bool
+}
+
+// CachePolicyMap EntityMap 可以实现该接口, 为实体声明缓存策略
+type CachePolicyMap interface {
+	GetCachePolicy(m interface{}) (CachePolicy, bool)
+}
+
+// IndexSyncMode 实体同步到搜索索引的方式
+type IndexSyncMode int
+
+const (
+	// IndexSyncSync 写入成功后立即写入索引, 索引写入失败时写操作返回错误
+	IndexSyncSync IndexSyncMode = iota
+	// IndexSyncAsync 在后台写入索引, 失败只记录日志, 由回填修复
+	IndexSyncAsync
+)
+
+// IndexSync 实体同步到搜索服务索引的策略
+type IndexSync struct {
+	Mode IndexSyncMode
+}
+
+// IndexSyncMap EntityMap 可以实现该接口, 声明需要同步到搜索索引的实体
+type IndexSyncMap interface {
+	GetIndexSync(m interface{}) (IndexSync, bool)
+}
+
+// Entities 可以在注册时声明缓存策略和索引同步的 EntityMap
+type Entities struct {
+	entities []interface{}
+	policies map[reflect.Type]CachePolicy
+	indexes  map[reflect.Type]IndexSync
+}
+
+func NewEntities() *Entities {
+	return &Entities{
+		policies: make(map[reflect.Type]CachePolicy),
+		indexes:  make(map[reflect.Type]IndexSync),
+	}
+}
+
+// Register 注册实体, 可选地声明它的缓存策略
+func (e *Entities) Register(m interface{}, policy ...CachePolicy) *Entities {
+	e.entities = append(e.entities, m)
+	if len(policy) > 0 {
+		e.policies[entityType(m)] = policy[0]
+	}
+	return e
+}
+
+func (e *Entities) GetEntities() []interface{} {
+	return e.entities
+}
+
+func (e *Entities) GetCachePolicy(m interface{}) (CachePolicy, bool) {
+	policy, ok := e.policies[entityType(m)]
+	return policy, ok
+}
+
+// SyncIndex 声明已注册的实体写入后同步到搜索索引
+func (e *Entities) SyncIndex(m interface{}, sync IndexSync) *Entities {
+	e.indexes[entityType(m)] = sync
+	return e
+}
+
+func (e *Entities) GetIndexSync(m interface{}) (IndexSync, bool) {
+	sync, ok := e.indexes[entityType(m)]
+	return sync, ok
+}
+
+func entityType(m interface{}) reflect.Type {
+	t := reflect.TypeOf(m)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,337 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/duolacloud/microbase/audit"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/jinzhu/gorm"
+)
+
+// 审计钩子读取的 gorm 设置
+const (
+	auditOperationKey = "audit:operation"
+	auditBeforeKey    = "audit:before"
+)
+
+// auditChunk 按主键读取修改前后的记录和写入审计记录时每批的主键数
+const auditChunk = 500
+
+// auditRow 修改前后的一条记录, fields 和 AuditCreate 一样按实体的 json 序列化
+type auditRow struct {
+	key    []interface{}
+	id     string
+	fields map[string]interface{}
+}
+
+// SetAuditOperation 覆盖钩子推导的操作类型, 例如 Save 记录为 UPSERT
+func SetAuditOperation(db *gorm.DB, op audit.Operation) *gorm.DB {
+	return db.Set(auditOperationKey, op)
+}
+
+// auditEnabled 是否注册了审计钩子, 没有开启审计时批量写入也不记录审计
+func auditEnabled(db *gorm.DB) bool {
+	return db.Callback().Create().Get("audit:create") != nil
+}
+
+func addAuditCallbacks(db *gorm.DB) {
+	// 审计记录和写操作在同一个事务中提交
+	db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:create", auditCreateCallback)
+	db.Callback().Update().Before("gorm:update").Register("audit:before_update", auditBeforeCallback)
+	db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:update", auditUpdateCallback)
+	db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", auditBeforeCallback)
+	db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:delete", auditDeleteCallback)
+}
+
+// auditing 返回审计的 ctx 和实体, 审计记录本身和没有经过仓储的写操作不审计
+func auditing(scope *gorm.Scope) (context.Context, interface{}, bool) {
+	ctx, model, ok := hookContext(scope)
+	if !ok {
+		return nil, nil, false
+	}
+	if _, ok := model.(*audit.Record); ok {
+		return nil, nil, false
+	}
+	return ctx, model, true
+}
+
+func auditOperation(scope *gorm.Scope, op audit.Operation) audit.Operation {
+	if v, ok := scope.Get(auditOperationKey); ok {
+		if o, ok := v.(audit.Operation); ok {
+			return o
+		}
+	}
+	return op
+}
+
+func auditCreateCallback(scope *gorm.Scope) {
+	ctx, model, ok := auditing(scope)
+	if !ok {
+		return
+	}
+
+	if err := AuditCreate(ctx, scope.NewDB(), model, auditOperation(scope, audit.OperationCreate)); err != nil {
+		scope.Err(err)
+	}
+}
+
+// AuditCreate 记录新建的实体, 用于不经过 gorm 钩子的批量写入, 批量 upsert 无法知道写入前的值
+func AuditCreate(ctx context.Context, db *gorm.DB, m interface{}, op audit.Operation) error {
+	if !auditEnabled(db) {
+		return nil
+	}
+
+	after, err := audit.Fields(m)
+	if err != nil {
+		return err
+	}
+
+	record := audit.NewRecord(ctx, op, audit.EntityType(m), audit.EntityID(primaryKeys(db, m)), audit.Diff(nil, after))
+	return saveRecords(ctx, db, record)
+}
+
+// auditBeforeCallback 按写操作的条件读取修改前的记录, 有主键时先读取主键, 再按主键分批读取
+func auditBeforeCallback(scope *gorm.Scope) {
+	_, model, ok := auditing(scope)
+	if !ok {
+		return
+	}
+
+	// 条件的参数写入 SQLVars, 读取后还原, 避免影响之后生成的 UPDATE 和 DELETE 语句
+	vars := scope.SQLVars
+	scope.SQLVars = nil
+	condition := scope.CombinedConditionSql()
+	args := scope.SQLVars
+	scope.SQLVars = vars
+
+	if condition != "" {
+		condition = " " + condition
+	}
+
+	ms := scope.NewDB().NewScope(model).GetModelStruct()
+	if len(ms.PrimaryFields) == 0 {
+		before, err := queryRows(scope, ms, "SELECT * FROM "+scope.QuotedTableName()+condition, args...)
+		if err != nil {
+			scope.Err(err)
+			return
+		}
+		scope.InstanceSet(auditBeforeKey, before)
+		return
+	}
+
+	columns := make([]string, len(ms.PrimaryFields))
+	for i, field := range ms.PrimaryFields {
+		columns[i] = scope.Quote(field.DBName)
+	}
+	keys, err := queryKeys(scope, "SELECT "+strings.Join(columns, ",")+" FROM "+scope.QuotedTableName()+condition, args...)
+	if err != nil {
+		scope.Err(err)
+		return
+	}
+
+	var before []*auditRow
+	for start := 0; start < len(keys); start += auditChunk {
+		end := start + auditChunk
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		rows, err := queryChunk(scope, ms, keys[start:end])
+		if err != nil {
+			scope.Err(err)
+			return
+		}
+		before = append(before, rows...)
+	}
+	scope.InstanceSet(auditBeforeKey, before)
+}
+
+func auditUpdateCallback(scope *gorm.Scope) {
+	auditChanges(scope, audit.OperationUpdate)
+}
+
+func auditDeleteCallback(scope *gorm.Scope) {
+	op := audit.OperationDelete
+	if scope.Search.Unscoped {
+		op = audit.OperationHardDelete
+	}
+	auditChanges(scope, op)
+}
+
+// auditChanges 按主键分批重新读取修改后的记录, 比较每条记录的变化并写入这一批的审计记录,
+// 物理删除的记录修改后为空
+func auditChanges(scope *gorm.Scope, op audit.Operation) {
+	ctx, model, ok := auditing(scope)
+	if !ok {
+		return
+	}
+
+	v, ok := scope.InstanceGet(auditBeforeKey)
+	if !ok {
+		return
+	}
+	before := v.([]*auditRow)
+
+	ms := scope.NewDB().NewScope(model).GetModelStruct()
+	op = auditOperation(scope, op)
+	entityType := audit.EntityType(model)
+
+	for start := 0; start < len(before); start += auditChunk {
+		end := start + auditChunk
+		if end > len(before) {
+			end = len(before)
+		}
+		chunk := before[start:end]
+
+		after, err := reload(scope, ms, chunk)
+		if err != nil {
+			scope.Err(err)
+			return
+		}
+
+		records := make([]*audit.Record, 0, len(chunk))
+		for _, row := range chunk {
+			var fields map[string]interface{}
+			if current, ok := after[row.id]; ok {
+				fields = current.fields
+			}
+
+			changes := audit.Diff(row.fields, fields)
+			if len(changes) == 0 {
+				continue
+			}
+			records = append(records, audit.NewRecord(ctx, op, entityType, row.id, changes))
+		}
+
+		if err = saveRecords(ctx, scope.NewDB(), records...); err != nil {
+			scope.Err(err)
+			return
+		}
+	}
+}
+
+// reload 按主键读取修改后的记录, 没有主键时无法对应修改前后的记录, 只记录修改前的值
+func reload(scope *gorm.Scope, ms *gorm.ModelStruct, before []*auditRow) (map[string]*auditRow, error) {
+	after := make(map[string]*auditRow)
+	if len(ms.PrimaryFields) == 0 {
+		return after, nil
+	}
+
+	keys := make([][]interface{}, len(before))
+	for i, row := range before {
+		keys[i] = row.key
+	}
+
+	rows, err := queryChunk(scope, ms, keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		after[row.id] = row
+	}
+	return after, nil
+}
+
+// queryChunk 按主键读取一批记录
+func queryChunk(scope *gorm.Scope, ms *gorm.ModelStruct, keys [][]interface{}) ([]*auditRow, error) {
+	condition, args := primaryIn(scope, ms, keys)
+	return queryRows(scope, ms, "SELECT * FROM "+scope.QuotedTableName()+" WHERE "+condition, args...)
+}
+
+// primaryIn 按主键匹配 keys 的条件, 例如 (`id`) IN ((?),(?)), 联合主键为 (`a`,`b`) IN ((?,?),(?,?))
+func primaryIn(scope *gorm.Scope, ms *gorm.ModelStruct, keys [][]interface{}) (string, []interface{}) {
+	columns := make([]string, len(ms.PrimaryFields))
+	for i, field := range ms.PrimaryFields {
+		columns[i] = scope.Quote(field.DBName)
+	}
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, 0, len(keys)*len(columns))
+	for i, key := range keys {
+		placeholders[i] = placeholder
+		args = append(args, key...)
+	}
+	return fmt.Sprintf("(%s) IN (%s)", strings.Join(columns, ","), strings.Join(placeholders, ",")), args
+}
+
+// queryRows 按实体类型读取记录, 转换为和 AuditCreate 相同的字段, 保证新建和修改的审计值一致
+func queryRows(scope *gorm.Scope, ms *gorm.ModelStruct, query string, args ...interface{}) ([]*auditRow, error) {
+	rows, err := scope.SQLDB().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	db := scope.NewDB()
+	var result []*auditRow
+	for rows.Next() {
+		m := reflect.New(ms.ModelType).Interface()
+		if err = db.ScanRows(rows, m); err != nil {
+			return nil, err
+		}
+
+		fields, err := audit.Fields(m)
+		if err != nil {
+			return nil, err
+		}
+
+		row := &auditRow{fields: fields}
+		for _, field := range db.NewScope(m).PrimaryFields() {
+			row.key = append(row.key, field.Field.Interface())
+		}
+		row.id = audit.EntityID(primaryKeys(db, m))
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// queryKeys 读取主键列, 文本列转换为字符串
+func queryKeys(scope *gorm.Scope, query string, args ...interface{}) ([][]interface{}, error) {
+	rows, err := scope.SQLDB().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]interface{}
+	for rows.Next() {
+		key := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range key {
+			ptrs[i] = &key[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		for i, v := range key {
+			if b, ok := v.([]byte); ok {
+				key[i] = string(b)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// saveRecords 审计记录写入当前租户的 audit_records 表
+func saveRecords(ctx context.Context, db *gorm.DB, records ...*audit.Record) error {
+	tenantId, _ := multitenancy.FromContext(ctx)
+
+	db = detach(db)
+	for _, record := range records {
+		if err := db.Table(TableName(record.TableName(), tenantId)).Create(record).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
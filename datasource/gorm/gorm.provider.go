@@ -7,9 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/duolacloud/microbase/audit"
 	"github.com/duolacloud/microbase/datasource"
 	"github.com/duolacloud/microbase/datasource/gorm/opentracing"
 	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/duolacloud/microbase/outbox"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 	"github.com/micro/go-micro/v2/config"
@@ -20,6 +22,9 @@ func NewGormTenancy(config config.Config, entityMap datasource.EntityMap) (multi
 	connectionString := config.Get("db", "connection_string").String("")
 
 	isolation := config.Get("multitenancy", "isolation").String("schema")
+	// db.audit 开启审计, 注册审计钩子并为每个租户迁移 audit_records 表, db.outbox 同样开启事件
+	enableAudit := config.Get("db", "audit").Bool(false)
+	enableOutbox := config.Get("db", "outbox").Bool(false)
 
 	if len(driver) == 0 {
 		return nil, errors.New("driver is empty")
@@ -39,7 +44,7 @@ func NewGormTenancy(config config.Config, entityMap datasource.EntityMap) (multi
 	defaultDB.DB().SetMaxIdleConns(1)
 	defaultDB.DB().SetConnMaxLifetime(3 * time.Minute)
 
-	addAutoCallbacks(defaultDB)
+	addAutoCallbacks(defaultDB, enableAudit, enableOutbox)
 	opentracing.AddGormCallbacks(defaultDB)
 
 	var clientCreateFn func(ctx context.Context, tenantId string) (multitenancy.Resource, error)
@@ -70,7 +75,7 @@ func NewGormTenancy(config config.Config, entityMap datasource.EntityMap) (multi
 			db.DB().SetMaxIdleConns(10)
 			db.DB().SetConnMaxLifetime(3 * time.Minute)
 
-			addAutoCallbacks(db)
+			addAutoCallbacks(db, enableAudit, enableOutbox)
 
 			opentracing.AddGormCallbacks(db)
 
@@ -101,6 +106,9 @@ func FromDBName(serviceName string, name string) string {
 
 func DBFromContext(tenancy multitenancy.Tenancy, ctx context.Context) (*gorm.DB, error) {
 	tenantName, _ := multitenancy.FromContext(ctx)
+	if db, ok := multitenancy.ResourceFromContext(ctx, tenancy, tenantName); ok {
+		return db.(*gorm.DB), nil
+	}
 
 	db, err := tenancy.ResourceFor(ctx, tenantName)
 	if err != nil {
@@ -109,11 +117,18 @@ func DBFromContext(tenancy multitenancy.Tenancy, ctx context.Context) (*gorm.DB,
 	return db.(*gorm.DB), nil
 }
 
-func addAutoCallbacks(db *gorm.DB) {
+func addAutoCallbacks(db *gorm.DB, enableAudit, enableOutbox bool) {
 	// 替换替换默认的钩子
 	db.Callback().Create().Replace("gorm:update_time_stamp", updateTimeForCreateCallback)
 	db.Callback().Update().Replace("gorm:update_time_stamp", updateTimeForUpdateCallback)
 	db.Callback().Delete().Replace("gorm:delete", deleteCallback)
+
+	if enableAudit {
+		addAuditCallbacks(db)
+	}
+	if enableOutbox {
+		addOutboxCallbacks(db)
+	}
 }
 
 func TableName(tableName string, tenantId string) string {
@@ -127,7 +142,15 @@ func TableName(tableName string, tenantId string) string {
 func autoMigrate(tenantId string, entityMap datasource.EntityMap, db *gorm.DB) error {
 	// ctx, span := trace.StartSpan(ctx, "tenancy.Migrate")
 	// defer span.End()
-	entities := entityMap.GetEntities()
+	// 每个租户的审计记录和待投递的事件保存在自己的 audit_records 和 outbox_messages 表, 只迁移开启了的
+	var entities []interface{}
+	if auditEnabled(db) {
+		entities = append(entities, &audit.Record{})
+	}
+	if outboxEnabled(db) {
+		entities = append(entities, &outbox.Message{})
+	}
+	entities = append(entities, entityMap.GetEntities()...)
 
 	db = db.Unscoped()
 	for _, entity := range entities {
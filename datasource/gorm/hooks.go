@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// 写操作钩子读取的 gorm 设置
+const (
+	contextKey = "microbase:context"
+	entityKey  = "microbase:entity"
+)
+
+// SetContext 写操作的钩子从 ctx 中获取租户和操作人, 没有设置 ctx 的写操作不会被审计, 也不会写入事件
+func SetContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.Set(contextKey, ctx)
+}
+
+// SetEntity 没有 Model 的 Update 通过 m 推导实体类型、字段名和事件, 例如 db.Table(table).Where(...).Update(data)
+func SetEntity(db *gorm.DB, m interface{}) *gorm.DB {
+	return db.Set(entityKey, m)
+}
+
+// hookContext 返回 SetContext 设置的 ctx 和写入的实体
+func hookContext(scope *gorm.Scope) (context.Context, interface{}, bool) {
+	v, ok := scope.Get(contextKey)
+	if !ok || scope.HasError() {
+		return nil, nil, false
+	}
+	ctx, ok := v.(context.Context)
+	if !ok {
+		return nil, nil, false
+	}
+
+	model := scope.Value
+	if model == nil {
+		if model, ok = scope.Get(entityKey); !ok || model == nil {
+			return nil, nil, false
+		}
+	}
+	return ctx, model, true
+}
+
+// detach 钩子自己的写入不再触发审计和事件
+func detach(db *gorm.DB) *gorm.DB {
+	return db.Set(contextKey, nil)
+}
+
+// primaryKeys 实体主键的 json 名称和值
+func primaryKeys(db *gorm.DB, m interface{}) map[string]interface{} {
+	keys := make(map[string]interface{})
+	for _, field := range db.NewScope(m).PrimaryFields() {
+		keys[jsonName(field.StructField)] = field.Field.Interface()
+	}
+	return keys
+}
+
+func jsonName(field *gorm.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.DBName
+	}
+	return name
+}
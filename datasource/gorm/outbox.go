@@ -0,0 +1,68 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/duolacloud/microbase/audit"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/duolacloud/microbase/outbox"
+	"github.com/jinzhu/gorm"
+)
+
+// outboxEnabled 是否注册了事件钩子, 没有开启时批量写入也不写入事件
+func outboxEnabled(db *gorm.DB) bool {
+	return db.Callback().Create().Get("outbox:create") != nil
+}
+
+func addOutboxCallbacks(db *gorm.DB) {
+	// 事件和写操作在同一个事务中提交, 进程在提交后退出也不会丢失事件
+	db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("outbox:create", outboxCallback)
+	db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("outbox:update", outboxCallback)
+	db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("outbox:delete", outboxCallback)
+}
+
+func outboxCallback(scope *gorm.Scope) {
+	ctx, model, ok := hookContext(scope)
+	if !ok {
+		return
+	}
+
+	if err := SaveEvents(ctx, scope.NewDB(), model); err != nil {
+		scope.Err(err)
+	}
+}
+
+// SaveEvents 把实现了 outbox.EventSource 的实体的事件写入当前租户的 outbox 表,
+// 用于不经过 gorm 钩子的批量写入
+func SaveEvents(ctx context.Context, db *gorm.DB, m interface{}) error {
+	if !outboxEnabled(db) {
+		return nil
+	}
+
+	source, ok := m.(outbox.EventSource)
+	if !ok {
+		return nil
+	}
+
+	events := source.PopEvents()
+	if len(events) == 0 {
+		return nil
+	}
+
+	aggregateType := audit.EntityType(m)
+	aggregateID := audit.EntityID(primaryKeys(db, m))
+
+	tenantId, _ := multitenancy.FromContext(ctx)
+	db = detach(db).Table(TableName((&outbox.Message{}).TableName(), tenantId))
+
+	for _, event := range events {
+		message, err := outbox.NewMessage(ctx, aggregateType, aggregateID, event)
+		if err != nil {
+			return err
+		}
+		if err = db.Create(message).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/duolacloud/microbase/logger"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/jinzhu/gorm"
+)
+
+// TxManager 在同一个事务中执行多个仓储操作
+type TxManager interface {
+	// RunInTx 开启事务并把事务绑定到 fn 的 ctx, fn 返回错误或 panic 时回滚, 否则提交;
+	// ctx 中已有同一租户的事务时改用 savepoint
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	tenancy multitenancy.Tenancy
+}
+
+// NewTxManager 事务从 tenancy 为当前租户提供的 *gorm.DB 开启,
+// 通过 DBFromContext 或 repository.MultitenancyProvider 取得的 DB 自动使用该事务
+func NewTxManager(tenancy multitenancy.Tenancy) TxManager {
+	return &txManager{
+		tenancy: tenancy,
+	}
+}
+
+type txKey struct {
+	tenancy  multitenancy.Tenancy
+	tenantId string
+}
+
+type currentTxKey struct{}
+
+// tx 一个进行中的事务, 和 *gorm.DB 的事务一样不能并发使用
+type tx struct {
+	db            *gorm.DB
+	savepoints    int
+	afterCommit   []func()
+	afterRollback []func()
+}
+
+func (m *txManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tenantId, _ := multitenancy.FromContext(ctx)
+	key := txKey{m.tenancy, tenantId}
+
+	if t, ok := ctx.Value(key).(*tx); ok {
+		return t.savepoint(context.WithValue(ctx, currentTxKey{}, t), fn)
+	}
+
+	db, err := DBFromContext(m.tenancy, ctx)
+	if err != nil {
+		return err
+	}
+
+	db = db.BeginTx(ctx, nil)
+	if db.Error != nil {
+		return db.Error
+	}
+
+	t := &tx{db: db}
+	ctx = multitenancy.WithResource(ctx, m.tenancy, tenantId, db)
+	ctx = context.WithValue(ctx, key, t)
+	ctx = context.WithValue(ctx, currentTxKey{}, t)
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(ctx); err != nil {
+		t.rollback()
+		return err
+	}
+
+	if err = db.Commit().Error; err != nil {
+		runHooks(t.afterRollback)
+		return err
+	}
+
+	runHooks(t.afterCommit)
+	return nil
+}
+
+func (t *tx) rollback() {
+	if err := t.db.Rollback().Error; err != nil {
+		logger.Errorf("gorm rollback error: %v", err)
+	}
+	runHooks(t.afterRollback)
+}
+
+// savepoint 嵌套事务, fn 失败时只回滚到 savepoint, 并撤销其中注册的提交钩子
+func (t *tx) savepoint(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	t.savepoints++
+	name := fmt.Sprintf("sp_%d", t.savepoints)
+
+	if err := t.db.Exec(fmt.Sprintf("SAVEPOINT %s", name)).Error; err != nil {
+		return err
+	}
+
+	commits, rollbacks := len(t.afterCommit), len(t.afterRollback)
+	rollbackTo := func() {
+		if err := t.db.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", name)).Error; err != nil {
+			logger.Errorf("gorm rollback to savepoint %s error: %v", name, err)
+		}
+
+		hooks := t.afterRollback[rollbacks:]
+		t.afterCommit = t.afterCommit[:commits]
+		t.afterRollback = t.afterRollback[:rollbacks]
+		runHooks(hooks)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			rollbackTo()
+			panic(r)
+		}
+	}()
+
+	if err = fn(ctx); err != nil {
+		rollbackTo()
+		return err
+	}
+
+	return t.db.Exec(fmt.Sprintf("RELEASE SAVEPOINT %s", name)).Error
+}
+
+func runHooks(hooks []func()) {
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// AfterCommit 注册在当前事务提交后执行的函数, 不在事务中时立即执行
+func AfterCommit(ctx context.Context, fn func()) {
+	if t, ok := ctx.Value(currentTxKey{}).(*tx); ok {
+		t.afterCommit = append(t.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// AfterRollback 注册在当前事务或 savepoint 回滚后执行的函数, 不在事务中时忽略
+func AfterRollback(ctx context.Context, fn func()) {
+	if t, ok := ctx.Value(currentTxKey{}).(*tx); ok {
+		t.afterRollback = append(t.afterRollback, fn)
+	}
+}
+
+// InTx ctx 是否处于 RunInTx 开启的事务中
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(currentTxKey{}).(*tx)
+	return ok
+}
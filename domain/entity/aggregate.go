@@ -0,0 +1,182 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidAggregate = errors.New("invalid aggregate")
+
+	// 指标名称会作为 SQL 的别名, 只允许标识符
+	metricNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+type AggregateFunc string
+
+const (
+	AggregateFuncCount AggregateFunc = "COUNT"
+	AggregateFuncSum   AggregateFunc = "SUM"
+	AggregateFuncAvg   AggregateFunc = "AVG"
+	AggregateFuncMin   AggregateFunc = "MIN"
+	AggregateFuncMax   AggregateFunc = "MAX"
+)
+
+// Metric 统计指标, Field 为空的 COUNT 统计记录数, 其它函数只能用于数值字段
+type Metric struct {
+	Func  AggregateFunc `json:"func"`
+	Field string        `json:"field"`
+	Alias string        `json:"alias"`
+}
+
+// Name 统计结果中的名称, 默认为 count 或者 sum_field 这样的形式
+func (m *Metric) Name() string {
+	if m.Alias != "" {
+		return m.Alias
+	}
+
+	if m.Field == "" {
+		return strings.ToLower(string(m.Func))
+	}
+	return fmt.Sprintf("%s_%s", strings.ToLower(string(m.Func)), m.Field)
+}
+
+type DateInterval string
+
+const (
+	DateIntervalMinute DateInterval = "MINUTE"
+	DateIntervalHour   DateInterval = "HOUR"
+	DateIntervalDay    DateInterval = "DAY"
+	DateIntervalWeek   DateInterval = "WEEK"
+	DateIntervalMonth  DateInterval = "MONTH"
+	DateIntervalYear   DateInterval = "YEAR"
+)
+
+// DateHistogram 按时间字段分桶, 分桶的 key 为桶的起始时间
+type DateHistogram struct {
+	Field    string       `json:"field"`
+	Interval DateInterval `json:"interval"`
+}
+
+// AggregateQuery 分组统计, 先按 GroupBy 的字段分组, 再按 Histogram 分桶
+type AggregateQuery struct {
+	Filter         map[string]interface{} `json:"filter"`
+	GroupBy        []string               `json:"groupBy"`
+	Histogram      *DateHistogram         `json:"histogram"`
+	Metrics        []*Metric              `json:"metrics"`
+	Size           int                    `json:"size"`           // 最多返回的分组数
+	IncludeDeleted bool                   `json:"includeDeleted"` // 包含软删除的记录
+}
+
+// AggregateBucket 一个分组的统计结果,
+// Keys 为分组字段的值, 时间分桶的值在 Histogram.Field 下, 类型为 time.Time,
+// Metrics 的 key 为 Metric.Name()
+type AggregateBucket struct {
+	Keys    map[string]interface{} `json:"keys"`
+	Metrics map[string]float64     `json:"metrics"`
+}
+
+// KeyFields 分组的全部字段, 时间分桶字段在最后
+func (q *AggregateQuery) KeyFields() []string {
+	fields := append([]string{}, q.GroupBy...)
+	if q.Histogram != nil {
+		fields = append(fields, q.Histogram.Field)
+	}
+	return fields
+}
+
+// Validate 校验分组字段和统计指标, 没有指标时默认统计记录数; known 为 nil 时不校验字段
+func (q *AggregateQuery) Validate(known FilterFields) error {
+	check := func(path, field string) (reflect.Type, error) {
+		if field == "" {
+			return nil, fmt.Errorf("%s: %w, field is required", path, ErrInvalidAggregate)
+		}
+		if known == nil {
+			return nil, nil
+		}
+
+		t, ok := known[field]
+		if !ok {
+			return nil, fmt.Errorf("%s %s: %w", path, field, ErrUnknownField)
+		}
+		return t, nil
+	}
+
+	for i, field := range q.GroupBy {
+		if _, err := check(fmt.Sprintf("groupBy[%d]", i), field); err != nil {
+			return err
+		}
+	}
+
+	if q.Histogram != nil {
+		t, err := check("histogram", q.Histogram.Field)
+		if err != nil {
+			return err
+		}
+		if t != nil && t.String() != "time.Time" && t.String() != "*time.Time" {
+			return fmt.Errorf("histogram %s: %w, must be a time field", q.Histogram.Field, ErrInvalidAggregate)
+		}
+
+		switch q.Histogram.Interval {
+		case DateIntervalMinute, DateIntervalHour, DateIntervalDay, DateIntervalWeek, DateIntervalMonth, DateIntervalYear:
+		default:
+			return fmt.Errorf("histogram interval %s: %w", q.Histogram.Interval, ErrInvalidAggregate)
+		}
+	}
+
+	if len(q.Metrics) == 0 {
+		q.Metrics = []*Metric{{Func: AggregateFuncCount}}
+	}
+
+	names := make(map[string]bool, len(q.Metrics))
+	for i, metric := range q.Metrics {
+		path := fmt.Sprintf("metrics[%d]", i)
+
+		switch metric.Func {
+		case AggregateFuncCount:
+			if metric.Field != "" {
+				if _, err := check(path, metric.Field); err != nil {
+					return err
+				}
+			}
+		case AggregateFuncSum, AggregateFuncAvg, AggregateFuncMin, AggregateFuncMax:
+			t, err := check(path, metric.Field)
+			if err != nil {
+				return err
+			}
+			if t != nil && !isNumeric(t) {
+				return fmt.Errorf("%s %s: %w, must be a numeric field", path, metric.Field, ErrInvalidAggregate)
+			}
+		default:
+			return fmt.Errorf("%s func %s: %w", path, metric.Func, ErrInvalidAggregate)
+		}
+
+		name := metric.Name()
+		if !metricNameRegexp.MatchString(name) {
+			return fmt.Errorf("%s %s: %w, name must be an identifier", path, name, ErrInvalidAggregate)
+		}
+		if names[name] {
+			return fmt.Errorf("%s %s: %w, duplicate name", path, name, ErrInvalidAggregate)
+		}
+		names[name] = true
+	}
+
+	return nil
+}
+
+func isNumeric(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package entity_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateQueryValidate(t *testing.T) {
+	known := entity.FilterFields{
+		"status": reflect.TypeOf(""),
+		"amount": reflect.TypeOf(0),
+		"ctime":  reflect.TypeOf(time.Time{}),
+	}
+
+	query := &entity.AggregateQuery{
+		GroupBy:   []string{"status"},
+		Histogram: &entity.DateHistogram{Field: "ctime", Interval: entity.DateIntervalDay},
+	}
+	assert.NoError(t, query.Validate(known))
+	assert.Equal(t, "count", query.Metrics[0].Name())
+	assert.Equal(t, []string{"status", "ctime"}, query.KeyFields())
+
+	query.Metrics = []*entity.Metric{{Func: entity.AggregateFuncSum, Field: "amount"}, {Func: entity.AggregateFuncAvg, Field: "amount", Alias: "avg"}}
+	assert.NoError(t, query.Validate(known))
+	assert.Equal(t, "sum_amount", query.Metrics[0].Name())
+
+	query.Metrics = []*entity.Metric{{Func: entity.AggregateFuncSum, Field: "status"}}
+	assert.True(t, errors.Is(query.Validate(known), entity.ErrInvalidAggregate))
+
+	query.Metrics = []*entity.Metric{{Func: entity.AggregateFuncMax, Field: "price"}}
+	assert.True(t, errors.Is(query.Validate(known), entity.ErrUnknownField))
+
+	query.Metrics = []*entity.Metric{{Func: entity.AggregateFuncCount, Alias: "a`b"}}
+	assert.True(t, errors.Is(query.Validate(known), entity.ErrInvalidAggregate))
+
+	query.Metrics = nil
+	query.Histogram = &entity.DateHistogram{Field: "status", Interval: entity.DateIntervalDay}
+	assert.True(t, errors.Is(query.Validate(known), entity.ErrInvalidAggregate))
+
+	query.Histogram = &entity.DateHistogram{Field: "ctime", Interval: "QUARTER"}
+	assert.True(t, errors.Is(query.Validate(known), entity.ErrInvalidAggregate))
+}
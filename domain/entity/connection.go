@@ -9,22 +9,33 @@ import (
 )
 
 type ConnectionQuery struct {
-	Filter    map[string]interface{} `json:"filter"` // 筛选条件
-	First     *int                   `json:"first"`
-	Last      *int                   `json:"last"`
-	Before    *string                `json:"before"`
-	After     *string                `json:"after"`
-	Fields    []string               `json:"fields"`
-	Orders    []*Order               `json:"order"` // 游标字段&排序
-	NeedTotal bool                   `json:"needTotal"`
+	Filter         map[string]interface{} `json:"filter"` // 筛选条件
+	First          *int                   `json:"first"`
+	Last           *int                   `json:"last"`
+	Before         *string                `json:"before"`
+	After          *string                `json:"after"`
+	Fields         []string               `json:"fields"`
+	Orders         []*Order               `json:"order"` // 游标字段&排序
+	NeedTotal      bool                   `json:"needTotal"`
+	IncludeDeleted bool                   `json:"includeDeleted"` // 包含软删除的记录
 }
 
+// ConnectionQueryFromPB 忽略格式错误的过滤条件, 需要校验时使用 ConnectionQueryFromPBWithError
 func ConnectionQueryFromPB(q *pagination.ConnectionQuery) *ConnectionQuery {
-	var filter map[string]interface{}
-	if len(q.Filter) != 0 {
-		_ = json.Unmarshal([]byte(q.Filter), &filter)
+	filter, _ := DecodeFilter(q.Filter, q.FilterQuery)
+	return connectionQueryFromPB(q, filter)
+}
+
+// ConnectionQueryFromPBWithError 过滤条件格式错误时返回错误
+func ConnectionQueryFromPBWithError(q *pagination.ConnectionQuery) (*ConnectionQuery, error) {
+	filter, err := DecodeFilter(q.Filter, q.FilterQuery)
+	if err != nil {
+		return nil, err
 	}
+	return connectionQueryFromPB(q, filter), nil
+}
 
+func connectionQueryFromPB(q *pagination.ConnectionQuery, filter map[string]interface{}) *ConnectionQuery {
 	var first *int
 	var _first int
 	if q.First != nil {
@@ -52,13 +63,14 @@ func ConnectionQueryFromPB(q *pagination.ConnectionQuery) *ConnectionQuery {
 	}
 
 	return &ConnectionQuery{
-		First:     first,
-		Last:      last,
-		Before:    before,
-		After:     after,
-		Filter:    filter,
-		Fields:    q.Fields,
-		NeedTotal: q.NeedTotal,
+		First:          first,
+		Last:           last,
+		Before:         before,
+		After:          after,
+		Filter:         filter,
+		Fields:         q.Fields,
+		NeedTotal:      q.NeedTotal,
+		IncludeDeleted: q.IncludeDeleted,
 		Orders: funk.Map(q.Orders, func(o *pagination.Order) *Order {
 			var direction OrderDirection
 			if o.Direction == pagination.OrderDirection_DESC {
@@ -79,9 +91,10 @@ func (c *ConnectionQuery) ToPB() *pagination.ConnectionQuery {
 	filterB, _ := json.Marshal(c.Filter)
 
 	pbquery := &pagination.ConnectionQuery{
-		NeedTotal: c.NeedTotal,
-		Fields:    c.Fields,
-		Filter:    string(filterB),
+		NeedTotal:      c.NeedTotal,
+		IncludeDeleted: c.IncludeDeleted,
+		Fields:         c.Fields,
+		Filter:         string(filterB),
 		Orders: funk.Map(c.Orders, func(o *Order) *pagination.Order {
 			var direction pagination.OrderDirection
 			if o.Direction == OrderDirectionDesc {
@@ -2,7 +2,6 @@ package entity
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -43,19 +42,33 @@ const (
 )
 
 type CursorQuery struct {
-	Filter    map[string]interface{} `json:"filter"`    // 筛选条件
-	Cursor    string                 `json:"cursor"`    // 游标值
-	Orders    []*Order               `json:"order"`     // 游标字段&排序
-	Size      int                    `json:"size"`      // 数据量
-	Direction CursorDirection        `json:"direction"` // 查询方向 0：游标前；1：游标后
-	NeedTotal bool                   `json:"needTotal"`
-	Fields    []string               `json:"fields"`
+	Filter         map[string]interface{} `json:"filter"`    // 筛选条件
+	Cursor         string                 `json:"cursor"`    // 游标值
+	Orders         []*Order               `json:"order"`     // 游标字段&排序
+	Size           int                    `json:"size"`      // 数据量
+	Direction      CursorDirection        `json:"direction"` // 查询方向 0：游标前；1：游标后
+	NeedTotal      bool                   `json:"needTotal"`
+	Fields         []string               `json:"fields"`
+	IncludeDeleted bool                   `json:"includeDeleted"` // 包含软删除的记录
 }
 
+// FromPB 忽略格式错误的过滤条件, 需要校验时使用 FromPBWithError
 func (q *CursorQuery) FromPB(o *pagination.ListQuery) {
-	var filter map[string]interface{}
-	_ = json.Unmarshal([]byte(o.Filter), &filter)
+	filter, _ := DecodeFilter(o.Filter, o.FilterQuery)
+	q.fromPB(o, filter)
+}
+
+// FromPBWithError 过滤条件格式错误时返回错误
+func (q *CursorQuery) FromPBWithError(o *pagination.ListQuery) error {
+	filter, err := DecodeFilter(o.Filter, o.FilterQuery)
+	if err != nil {
+		return err
+	}
+	q.fromPB(o, filter)
+	return nil
+}
 
+func (q *CursorQuery) fromPB(o *pagination.ListQuery, filter map[string]interface{}) {
 	if o.Direction == pagination.CursorDirection_before {
 		q.Direction = CursorDirectionBefore
 	} else {
@@ -84,6 +97,7 @@ func (q *CursorQuery) FromPB(o *pagination.ListQuery) {
 	q.Size = int(o.Size)
 	q.NeedTotal = o.NeedTotal
 	q.Fields = o.Fields
+	q.IncludeDeleted = o.IncludeDeleted
 }
 
 type CursorExtra struct {
@@ -0,0 +1,323 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/duolacloud/microbase/types/smarttime"
+)
+
+var (
+	ErrUnknownField    = errors.New("unknown field")
+	ErrUnknownOperator = errors.New("unknown operator")
+	ErrFilterValue     = errors.New("invalid filter value")
+)
+
+// FilterError 过滤条件错误, Path 为出错的位置, 例如 OR[1].age.IN
+type FilterError struct {
+	Path   string
+	Err    error
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("filter %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("filter %s: %v, %s", e.Path, e.Err, e.Reason)
+}
+
+func (e *FilterError) Unwrap() error {
+	return e.Err
+}
+
+// Filter 解析后的过滤条件
+type Filter interface {
+	Accept(v FilterVisitor) (interface{}, error)
+}
+
+// GroupFilter AND, OR, NOR 组合条件, 同一层的多个条件和同一字段的多个操作符按 AND 组合
+type GroupFilter struct {
+	Type    FilterType
+	Filters []Filter
+}
+
+// FieldFilter 字段条件, Value 已经按操作符校验过, 数组统一为 []interface{}, 时间字段统一为 time.Time
+type FieldFilter struct {
+	Field string
+	Op    FilterType
+	Value interface{}
+}
+
+// FilterVisitor 后序遍历过滤条件, 各个后端实现自己的 visitor 把条件编译为查询语句,
+// children 为子条件编译后的结果
+type FilterVisitor interface {
+	VisitField(f *FieldFilter) (interface{}, error)
+	VisitGroup(f *GroupFilter, children []interface{}) (interface{}, error)
+}
+
+// Empty 没有任何字段条件, 例如 {"AND": []} 和 {"name": {}}, 编译后不限制任何记录
+func (f *GroupFilter) Empty() bool {
+	for _, filter := range f.Filters {
+		if group, ok := filter.(*GroupFilter); !ok || !group.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *FieldFilter) Accept(v FilterVisitor) (interface{}, error) {
+	return v.VisitField(f)
+}
+
+func (f *GroupFilter) Accept(v FilterVisitor) (interface{}, error) {
+	children := make([]interface{}, len(f.Filters))
+	for i, filter := range f.Filters {
+		child, err := filter.Accept(v)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = child
+	}
+	return v.VisitGroup(f, children)
+}
+
+// FilterFields 实体的字段元数据, key 为过滤条件中使用的字段名, 为 nil 时不校验字段
+type FilterFields map[string]reflect.Type
+
+// ParseFilter 解析并校验 json 格式的过滤条件, 返回的根节点为 AND
+func ParseFilter(filter map[string]interface{}, fields FilterFields) (*GroupFilter, error) {
+	p := &filterParser{fields: fields}
+	return p.parseGroup("", FilterType_AND, filter)
+}
+
+type filterParser struct {
+	fields FilterFields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedKeys 按 key 排序, 保证编译出的查询语句稳定
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (p *filterParser) parseGroup(path string, typ FilterType, filter map[string]interface{}) (*GroupFilter, error) {
+	group := &GroupFilter{Type: typ}
+
+	for _, key := range sortedKeys(filter) {
+		value := filter[key]
+
+		switch FilterType(key) {
+		case FilterType_AND, FilterType_OR, FilterType_NOR:
+			child, err := p.parseSubFilters(joinPath(path, key), FilterType(key), value)
+			if err != nil {
+				return nil, err
+			}
+			group.Filters = append(group.Filters, child)
+		default:
+			child, err := p.parseField(joinPath(path, key), key, value)
+			if err != nil {
+				return nil, err
+			}
+			group.Filters = append(group.Filters, child)
+		}
+	}
+
+	return group, nil
+}
+
+// parseSubFilters AND, OR, NOR 的值为过滤条件的数组; 兼容旧的对象写法,
+// 对象的每个 key 作为一个子条件
+func (p *filterParser) parseSubFilters(path string, typ FilterType, value interface{}) (*GroupFilter, error) {
+	var (
+		subFilters []map[string]interface{}
+		paths      []string
+	)
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		subFilters = v
+		for i := range v {
+			paths = append(paths, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case []interface{}:
+		for i, item := range v {
+			subFilter, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, &FilterError{Path: fmt.Sprintf("%s[%d]", path, i), Err: ErrFilterValue, Reason: "must be an object"}
+			}
+			subFilters = append(subFilters, subFilter)
+			paths = append(paths, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			subFilters = append(subFilters, map[string]interface{}{key: v[key]})
+			paths = append(paths, path)
+		}
+	default:
+		return nil, &FilterError{Path: path, Err: ErrFilterValue, Reason: "must be an array"}
+	}
+
+	group := &GroupFilter{Type: typ}
+	for i, subFilter := range subFilters {
+		child, err := p.parseGroup(paths[i], FilterType_AND, subFilter)
+		if err != nil {
+			return nil, err
+		}
+		group.Filters = append(group.Filters, child)
+	}
+	return group, nil
+}
+
+func (p *filterParser) parseField(path, field string, value interface{}) (Filter, error) {
+	var fieldType reflect.Type
+	if p.fields != nil {
+		t, ok := p.fields[field]
+		if !ok {
+			return nil, &FilterError{Path: path, Err: ErrUnknownField}
+		}
+		fieldType = t
+	}
+
+	ops, ok := value.(map[string]interface{})
+	if !ok {
+		// 简写形式 {"name": "a"} 等价于 {"name": {"EQ": "a"}}
+		ops = map[string]interface{}{string(FilterType_EQ): value}
+	}
+
+	group := &GroupFilter{Type: FilterType_AND}
+	for _, key := range sortedKeys(ops) {
+		op := FilterType(key)
+		v, err := checkFilterValue(joinPath(path, key), op, ops[key], fieldType)
+		if err != nil {
+			return nil, err
+		}
+
+		if op == FilterType_EQ && v == nil {
+			op = FilterType_IS_NULL
+		}
+
+		group.Filters = append(group.Filters, &FieldFilter{
+			Field: field,
+			Op:    op,
+			Value: v,
+		})
+	}
+
+	if len(group.Filters) == 1 {
+		return group.Filters[0], nil
+	}
+	return group, nil
+}
+
+// checkFilterValue 校验操作符和值的格式
+func checkFilterValue(path string, op FilterType, value interface{}, fieldType reflect.Type) (interface{}, error) {
+	switch op {
+	case FilterType_EQ, FilterType_NE, FilterType_GT, FilterType_GTE, FilterType_LT, FilterType_LTE:
+		if !isScalar(value) {
+			return nil, &FilterError{Path: path, Err: ErrFilterValue, Reason: "must be a scalar"}
+		}
+		return convertFilterValue(path, value, fieldType)
+	case FilterType_LIKE, FilterType_NOT_LIKE, FilterType_MATCH:
+		if _, ok := value.(string); !ok {
+			return nil, &FilterError{Path: path, Err: ErrFilterValue, Reason: "must be a string"}
+		}
+		return value, nil
+	case FilterType_IN, FilterType_NOT_IN:
+		values, ok := toSlice(value)
+		if !ok {
+			return nil, &FilterError{Path: path, Err: ErrFilterValue, Reason: "must be an array"}
+		}
+		return convertFilterValues(path, values, fieldType)
+	case FilterType_BETWEEN:
+		values, ok := toSlice(value)
+		if !ok || len(values) != 2 {
+			return nil, &FilterError{Path: path, Err: ErrFilterValue, Reason: "must be an array of two elements"}
+		}
+		return convertFilterValues(path, values, fieldType)
+	case FilterType_IS_NULL, FilterType_NOT_NULL:
+		return nil, nil
+	default:
+		return nil, &FilterError{Path: path, Err: ErrUnknownOperator}
+	}
+}
+
+func isScalar(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		_, ok := value.(time.Time)
+		return ok
+	}
+	return true
+}
+
+func toSlice(value interface{}) ([]interface{}, bool) {
+	if values, ok := value.([]interface{}); ok {
+		return values, true
+	}
+
+	v := reflect.ValueOf(value)
+	if value == nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil, false
+	}
+
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+func convertFilterValues(path string, values []interface{}, fieldType reflect.Type) ([]interface{}, error) {
+	converted := make([]interface{}, len(values))
+	for i, value := range values {
+		if !isScalar(value) {
+			return nil, &FilterError{Path: fmt.Sprintf("%s[%d]", path, i), Err: ErrFilterValue, Reason: "must be a scalar"}
+		}
+
+		v, err := convertFilterValue(fmt.Sprintf("%s[%d]", path, i), value, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = v
+	}
+	return converted, nil
+}
+
+// convertFilterValue 时间字段支持多种时间格式, 统一转换为 time.Time
+func convertFilterValue(path string, value interface{}, fieldType reflect.Type) (interface{}, error) {
+	if value == nil || fieldType == nil {
+		return value, nil
+	}
+
+	switch fieldType.String() {
+	case "time.Time", "*time.Time":
+		// json 解析出的毫秒时间戳为 float64
+		if f, ok := value.(float64); ok {
+			value = int64(f)
+		}
+
+		t, err := smarttime.Parse(value)
+		if err != nil {
+			return nil, &FilterError{Path: path, Err: ErrFilterValue, Reason: "must be a time"}
+		}
+		return time.Time(t), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,95 @@
+package entity_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var fields = entity.FilterFields{
+	"name":  reflect.TypeOf(""),
+	"age":   reflect.TypeOf(0),
+	"ctime": reflect.TypeOf(time.Time{}),
+}
+
+func TestParseFilter(t *testing.T) {
+	root, err := entity.ParseFilter(map[string]interface{}{
+		"name": "a",
+		"age":  map[string]interface{}{"GT": 1, "LT": 5},
+		"OR": []interface{}{
+			map[string]interface{}{"ctime": map[string]interface{}{"GTE": float64(1600000000000)}},
+			map[string]interface{}{"name": nil},
+		},
+	}, fields)
+	assert.NoError(t, err)
+
+	// 按 key 排序: OR, age, name
+	assert.Equal(t, entity.FilterType_AND, root.Type)
+	assert.Len(t, root.Filters, 3)
+
+	or := root.Filters[0].(*entity.GroupFilter)
+	assert.Equal(t, entity.FilterType_OR, or.Type)
+	ctime := or.Filters[0].(*entity.GroupFilter).Filters[0].(*entity.FieldFilter)
+	assert.Equal(t, time.Unix(1600000000, 0), ctime.Value)
+	assert.Equal(t, entity.FilterType_IS_NULL, or.Filters[1].(*entity.GroupFilter).Filters[0].(*entity.FieldFilter).Op)
+
+	age := root.Filters[1].(*entity.GroupFilter)
+	assert.Equal(t, entity.FilterType_GT, age.Filters[0].(*entity.FieldFilter).Op)
+	assert.Equal(t, entity.FilterType_LT, age.Filters[1].(*entity.FieldFilter).Op)
+
+	assert.Equal(t, &entity.FieldFilter{Field: "name", Op: entity.FilterType_EQ, Value: "a"}, root.Filters[2])
+}
+
+func TestParseFilterLegacyGroup(t *testing.T) {
+	// 旧的写法: OR 的值为对象, 每个 key 是一个子条件
+	root, err := entity.ParseFilter(map[string]interface{}{
+		"OR": map[string]interface{}{
+			"name": "a",
+			"age":  map[string]interface{}{"GT": 1},
+		},
+	}, fields)
+	assert.NoError(t, err)
+
+	or := root.Filters[0].(*entity.GroupFilter)
+	assert.Equal(t, entity.FilterType_OR, or.Type)
+	assert.Len(t, or.Filters, 2)
+
+	assert.Equal(t, &entity.FieldFilter{Field: "age", Op: entity.FilterType_GT, Value: 1}, or.Filters[0].(*entity.GroupFilter).Filters[0])
+	assert.Equal(t, &entity.FieldFilter{Field: "name", Op: entity.FilterType_EQ, Value: "a"}, or.Filters[1].(*entity.GroupFilter).Filters[0])
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	cases := []struct {
+		filter map[string]interface{}
+		path   string
+		err    error
+	}{
+		{map[string]interface{}{"unknown": 1}, "unknown", entity.ErrUnknownField},
+		{map[string]interface{}{"age": map[string]interface{}{"NESTED": 1}}, "age.NESTED", entity.ErrUnknownOperator},
+		{map[string]interface{}{"age": map[string]interface{}{"IN": 1}}, "age.IN", entity.ErrFilterValue},
+		{map[string]interface{}{"age": map[string]interface{}{"BETWEEN": []interface{}{1}}}, "age.BETWEEN", entity.ErrFilterValue},
+		{map[string]interface{}{"name": map[string]interface{}{"LIKE": 1}}, "name.LIKE", entity.ErrFilterValue},
+		{map[string]interface{}{"ctime": "yesterday"}, "ctime.EQ", entity.ErrFilterValue},
+		{map[string]interface{}{"OR": 1}, "OR", entity.ErrFilterValue},
+		{map[string]interface{}{"OR": map[string]interface{}{"age": map[string]interface{}{"IN": 1}}}, "OR.age.IN", entity.ErrFilterValue},
+		{map[string]interface{}{"AND": []interface{}{map[string]interface{}{"age": map[string]interface{}{"EQ": []interface{}{1}}}}}, "AND[0].age.EQ", entity.ErrFilterValue},
+	}
+
+	for _, c := range cases {
+		_, err := entity.ParseFilter(c.filter, fields)
+
+		var filterErr *entity.FilterError
+		if assert.True(t, errors.As(err, &filterErr), "%v", c.filter) {
+			assert.Equal(t, c.path, filterErr.Path)
+			assert.True(t, errors.Is(err, c.err))
+		}
+	}
+
+	// 没有字段元数据时不校验字段
+	_, err := entity.ParseFilter(map[string]interface{}{"unknown": 1}, nil)
+	assert.NoError(t, err)
+}
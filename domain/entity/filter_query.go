@@ -0,0 +1,610 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 文本过滤条件, 解析为和 json 过滤条件相同的结构, 例如:
+//
+//	status:active AND (age>=18 OR vip:true) AND name~"zh%"
+//
+// 字段操作符:
+//
+//	:  =    EQ, 值为 null 时为 IS_NULL
+//	!: !=   NE, 值为 null 时为 NOT_NULL
+//	> >= < <=
+//	~  !~   LIKE, NOT_LIKE
+//	=~      MATCH
+//	:(a, b)   IN, !:(a, b) 为 NOT_IN
+//	:[a, b]   BETWEEN, * 表示不限制
+//
+// 组合条件 NOT > AND > OR, 使用括号改变优先级. 值可以是带引号的字符串, 数字, true, false, null,
+// 或者不含空白和 ()[],:!=<>~" 的字符串
+
+// FilterSyntaxError 文本过滤条件的语法错误, Offset 为出错位置的字节偏移
+type FilterSyntaxError struct {
+	Offset int
+	Reason string
+}
+
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("filter syntax error at %d: %s", e.Offset, e.Reason)
+}
+
+var filterQueryOps = map[string]FilterType{
+	":":  FilterType_EQ,
+	"=":  FilterType_EQ,
+	"!:": FilterType_NE,
+	"!=": FilterType_NE,
+	">":  FilterType_GT,
+	">=": FilterType_GTE,
+	"<":  FilterType_LT,
+	"<=": FilterType_LTE,
+	"~":  FilterType_LIKE,
+	"!~": FilterType_NOT_LIKE,
+	"=~": FilterType_MATCH,
+}
+
+type filterTokenKind int
+
+const (
+	tokenEOF filterTokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+type filterToken struct {
+	kind   filterTokenKind
+	text   string
+	offset int
+}
+
+func isFilterOpChar(r rune) bool {
+	return strings.ContainsRune(":!=<>~", r)
+}
+
+func isFilterWordChar(r rune) bool {
+	return !unicode.IsSpace(r) && !isFilterOpChar(r) && !strings.ContainsRune(`()[],"`, r)
+}
+
+func lexFilterQuery(s string) ([]filterToken, error) {
+	var tokens []filterToken
+
+	runes := []rune(s)
+	offsets := make([]int, len(runes)+1)
+	for i, j := 0, 0; i < len(runes); i++ {
+		offsets[i] = j
+		j += len(string(runes[i]))
+		offsets[i+1] = j
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenLParen, text: "(", offset: offsets[i]})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenRParen, text: ")", offset: offsets[i]})
+			i++
+		case r == '[':
+			tokens = append(tokens, filterToken{kind: tokenLBracket, text: "[", offset: offsets[i]})
+			i++
+		case r == ']':
+			tokens = append(tokens, filterToken{kind: tokenRBracket, text: "]", offset: offsets[i]})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: tokenComma, text: ",", offset: offsets[i]})
+			i++
+		case r == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &FilterSyntaxError{Offset: offsets[start], Reason: "unterminated string"}
+			}
+			i++
+
+			text, err := strconv.Unquote(string(runes[start:i]))
+			if err != nil {
+				return nil, &FilterSyntaxError{Offset: offsets[start], Reason: "invalid string"}
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: text, offset: offsets[start]})
+		case isFilterOpChar(r):
+			for i < len(runes) && isFilterOpChar(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: tokenOp, text: string(runes[start:i]), offset: offsets[start]})
+		default:
+			for i < len(runes) && isFilterWordChar(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: string(runes[start:i]), offset: offsets[start]})
+		}
+	}
+
+	return append(tokens, filterToken{kind: tokenEOF, offset: len(s)}), nil
+}
+
+type filterQueryParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterQueryParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterQueryParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterQueryParser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterQueryParser) expect(kind filterTokenKind, what string) (filterToken, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, &FilterSyntaxError{Offset: t.offset, Reason: fmt.Sprintf("expect %s", what)}
+	}
+	return t, nil
+}
+
+// ParseFilterQuery 解析文本过滤条件, 空字符串返回空的过滤条件
+func ParseFilterQuery(s string) (map[string]interface{}, error) {
+	tokens, err := lexFilterQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterQueryParser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return map[string]interface{}{}, nil
+	}
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &FilterSyntaxError{Offset: t.offset, Reason: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return filter, nil
+}
+
+func (p *filterQueryParser) parseOr() (map[string]interface{}, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []interface{}{first}
+	for p.keyword("OR") {
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 1 {
+		return first, nil
+	}
+	return map[string]interface{}{string(FilterType_OR): filters}, nil
+}
+
+func (p *filterQueryParser) parseAnd() (map[string]interface{}, error) {
+	first, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []map[string]interface{}{first}
+	for p.keyword("AND") {
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 1 {
+		return first, nil
+	}
+	return mergeAnd(filters), nil
+}
+
+func (p *filterQueryParser) parseNot() (map[string]interface{}, error) {
+	if p.keyword("NOT") {
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{string(FilterType_NOR): []interface{}{filter}}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *filterQueryParser) parseTerm() (map[string]interface{}, error) {
+	field, err := p.expect(tokenWord, "field")
+	if err != nil {
+		return nil, err
+	}
+
+	opToken, err := p.expect(tokenOp, "operator")
+	if err != nil {
+		return nil, err
+	}
+	op, ok := filterQueryOps[opToken.text]
+	if !ok {
+		return nil, &FilterSyntaxError{Offset: opToken.offset, Reason: fmt.Sprintf("unknown operator %q", opToken.text)}
+	}
+
+	switch t := p.peek(); {
+	case t.kind == tokenLParen && (op == FilterType_EQ || op == FilterType_NE):
+		values, err := p.parseList(tokenLParen, tokenRParen, ")")
+		if err != nil {
+			return nil, err
+		}
+
+		if op == FilterType_EQ {
+			op = FilterType_IN
+		} else {
+			op = FilterType_NOT_IN
+		}
+		return map[string]interface{}{field.text: map[string]interface{}{string(op): values}}, nil
+	case t.kind == tokenLBracket && op == FilterType_EQ:
+		values, err := p.parseList(tokenLBracket, tokenRBracket, "]")
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 2 {
+			return nil, &FilterSyntaxError{Offset: t.offset, Reason: "range must have two values"}
+		}
+		return map[string]interface{}{field.text: map[string]interface{}{string(FilterType_BETWEEN): values}}, nil
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		switch op {
+		case FilterType_EQ:
+			return map[string]interface{}{field.text: map[string]interface{}{string(FilterType_IS_NULL): true}}, nil
+		case FilterType_NE:
+			return map[string]interface{}{field.text: map[string]interface{}{string(FilterType_NOT_NULL): true}}, nil
+		}
+	}
+
+	if op == FilterType_EQ {
+		return map[string]interface{}{field.text: value}, nil
+	}
+	return map[string]interface{}{field.text: map[string]interface{}{string(op): value}}, nil
+}
+
+// parseList 解析 (a, b) 或 [a, b], * 表示 null
+func (p *filterQueryParser) parseList(open, close filterTokenKind, closeText string) ([]interface{}, error) {
+	p.next()
+
+	values := []interface{}{}
+	if p.peek().kind == close {
+		p.next()
+		return values, nil
+	}
+
+	for {
+		var value interface{}
+		if t := p.peek(); t.kind == tokenWord && t.text == "*" && open == tokenLBracket {
+			p.next()
+		} else {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == close {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, &FilterSyntaxError{Offset: t.offset, Reason: fmt.Sprintf("expect , or %s", closeText)}
+		}
+	}
+}
+
+func (p *filterQueryParser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return t.text, nil
+	case tokenWord:
+		return filterLiteral(t.text), nil
+	default:
+		return nil, &FilterSyntaxError{Offset: t.offset, Reason: "expect value"}
+	}
+}
+
+// filterLiteral 不带引号的值: null, true, false, 数字, 其他为字符串
+func filterLiteral(s string) interface{} {
+	switch s {
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// mergeAnd 合并 AND 的条件, 不同字段和同一字段的不同操作符合并到同一个 map, 无法合并的放到 AND 中
+func mergeAnd(filters []map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	var rest []interface{}
+
+	for _, filter := range filters {
+		if !mergeFilter(merged, filter) {
+			rest = append(rest, filter)
+		}
+	}
+
+	if len(rest) > 0 {
+		if and, ok := merged[string(FilterType_AND)].([]interface{}); ok {
+			merged[string(FilterType_AND)] = append(and, rest...)
+		} else {
+			merged[string(FilterType_AND)] = rest
+		}
+	}
+	return merged
+}
+
+func mergeFilter(merged, filter map[string]interface{}) bool {
+	for key, value := range filter {
+		existing, ok := merged[key]
+		if !ok {
+			continue
+		}
+
+		switch FilterType(key) {
+		case FilterType_AND, FilterType_OR, FilterType_NOR:
+			return false
+		}
+
+		existingOps, ok1 := existing.(map[string]interface{})
+		ops, ok2 := value.(map[string]interface{})
+		if !ok1 || !ok2 {
+			return false
+		}
+		for op := range ops {
+			if _, ok := existingOps[op]; ok {
+				return false
+			}
+		}
+	}
+
+	for key, value := range filter {
+		existing, ok := merged[key]
+		if !ok {
+			merged[key] = value
+			continue
+		}
+
+		existingOps := existing.(map[string]interface{})
+		for op, v := range value.(map[string]interface{}) {
+			existingOps[op] = v
+		}
+	}
+	return true
+}
+
+// DecodeFilter 解码 pagination 查询中的过滤条件, filter 为 json 格式, filterQuery 为文本格式,
+// 同时存在时按 AND 组合
+func DecodeFilter(filter, filterQuery string) (map[string]interface{}, error) {
+	var filters []map[string]interface{}
+
+	if len(filter) != 0 {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(filter), &m); err != nil {
+			return nil, err
+		}
+		filters = append(filters, m)
+	}
+
+	if len(filterQuery) != 0 {
+		m, err := ParseFilterQuery(filterQuery)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, m)
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil, nil
+	case 1:
+		return filters[0], nil
+	default:
+		return mergeAnd(filters), nil
+	}
+}
+
+// FormatFilter 把过滤条件输出为文本格式, 用于日志
+func FormatFilter(filter map[string]interface{}) (string, error) {
+	root, err := ParseFilter(filter, nil)
+	if err != nil {
+		return "", err
+	}
+	return root.String(), nil
+}
+
+func (f *GroupFilter) String() string {
+	var parts []string
+	for _, child := range f.Filters {
+		s := child.(fmt.Stringer).String()
+		if s == "" {
+			continue
+		}
+
+		// 嵌套的组合条件加上括号
+		if group, ok := child.(*GroupFilter); ok && group.Type != FilterType_NOR && group.size() > 1 {
+			if group.Type != f.Type || f.Type == FilterType_NOR {
+				s = "(" + s + ")"
+			}
+		}
+		parts = append(parts, s)
+	}
+
+	switch f.Type {
+	case FilterType_OR:
+		return strings.Join(parts, " OR ")
+	case FilterType_NOR:
+		if len(parts) == 0 {
+			return ""
+		}
+		if len(parts) == 1 {
+			return "NOT " + parts[0]
+		}
+		return "NOT (" + strings.Join(parts, " OR ") + ")"
+	default:
+		return strings.Join(parts, " AND ")
+	}
+}
+
+// size 非空的子条件数量
+func (f *GroupFilter) size() int {
+	n := 0
+	for _, child := range f.Filters {
+		if child.(fmt.Stringer).String() != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *FieldFilter) String() string {
+	switch f.Op {
+	case FilterType_EQ:
+		return f.Field + ":" + formatFilterValue(f.Value)
+	case FilterType_NE:
+		return f.Field + "!:" + formatFilterValue(f.Value)
+	case FilterType_GT:
+		return f.Field + ">" + formatFilterValue(f.Value)
+	case FilterType_GTE:
+		return f.Field + ">=" + formatFilterValue(f.Value)
+	case FilterType_LT:
+		return f.Field + "<" + formatFilterValue(f.Value)
+	case FilterType_LTE:
+		return f.Field + "<=" + formatFilterValue(f.Value)
+	case FilterType_LIKE:
+		return f.Field + "~" + formatFilterValue(f.Value)
+	case FilterType_NOT_LIKE:
+		return f.Field + "!~" + formatFilterValue(f.Value)
+	case FilterType_MATCH:
+		return f.Field + "=~" + formatFilterValue(f.Value)
+	case FilterType_IN:
+		return f.Field + ":(" + formatFilterValues(f.Value, "null") + ")"
+	case FilterType_NOT_IN:
+		return f.Field + "!:(" + formatFilterValues(f.Value, "null") + ")"
+	case FilterType_BETWEEN:
+		return f.Field + ":[" + formatFilterValues(f.Value, "*") + "]"
+	case FilterType_IS_NULL:
+		return f.Field + ":null"
+	case FilterType_NOT_NULL:
+		return f.Field + "!:null"
+	default:
+		return fmt.Sprintf("%s %s %v", f.Field, f.Op, f.Value)
+	}
+}
+
+func formatFilterValues(value interface{}, null string) string {
+	values, _ := value.([]interface{})
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if v == nil {
+			parts[i] = null
+		} else {
+			parts[i] = formatFilterValue(v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatFilterValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		// 会被解析为其他类型或者包含特殊字符的字符串需要引号
+		if v == "" || v == "*" || filterLiteral(v) != v || strings.IndexFunc(v, func(r rune) bool { return !isFilterWordChar(r) }) >= 0 {
+			return strconv.Quote(v)
+		}
+		for _, keyword := range []string{"AND", "OR", "NOT"} {
+			if strings.EqualFold(v, keyword) {
+				return strconv.Quote(v)
+			}
+		}
+		return v
+	case time.Time:
+		return strconv.Quote(v.Format(time.RFC3339))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,141 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/proto/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilterQuery(t *testing.T) {
+	filter, err := entity.ParseFilterQuery(`status:active AND (age>=18 OR vip:true) AND name~"zh%"`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"status": "active",
+		"name":   map[string]interface{}{"LIKE": "zh%"},
+		"OR": []interface{}{
+			map[string]interface{}{"age": map[string]interface{}{"GTE": int64(18)}},
+			map[string]interface{}{"vip": true},
+		},
+	}, filter)
+
+	filter, err = entity.ParseFilterQuery(`age>1 AND age<5 AND NOT tag:(a, "b c") AND ctime:[*, 100] AND dtime:null`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"age":   map[string]interface{}{"GT": int64(1), "LT": int64(5)},
+		"NOR":   []interface{}{map[string]interface{}{"tag": map[string]interface{}{"IN": []interface{}{"a", "b c"}}}},
+		"ctime": map[string]interface{}{"BETWEEN": []interface{}{nil, int64(100)}},
+		"dtime": map[string]interface{}{"IS_NULL": true},
+	}, filter)
+
+	// 同一字段重复的操作符无法合并, 放到 AND 中
+	filter, err = entity.ParseFilterQuery(`age>1 AND age>2`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"age": map[string]interface{}{"GT": int64(1)},
+		"AND": []interface{}{map[string]interface{}{"age": map[string]interface{}{"GT": int64(2)}}},
+	}, filter)
+
+	filter, err = entity.ParseFilterQuery("")
+	assert.NoError(t, err)
+	assert.Empty(t, filter)
+}
+
+func TestParseFilterQueryErrors(t *testing.T) {
+	cases := []struct {
+		query  string
+		offset int
+	}{
+		{`status`, 6},
+		{`status:`, 7},
+		{`status:active AND`, 17},
+		{`(status:active`, 14},
+		{`status<>active`, 6},
+		{`name:"abc`, 5},
+		{`age:[1, 2, 3]`, 4},
+		{`a:1 b:2`, 4},
+	}
+
+	for _, c := range cases {
+		_, err := entity.ParseFilterQuery(c.query)
+		syntaxErr, ok := err.(*entity.FilterSyntaxError)
+		if assert.True(t, ok, c.query) {
+			assert.Equal(t, c.offset, syntaxErr.Offset, c.query)
+		}
+	}
+}
+
+func TestFormatFilter(t *testing.T) {
+	queries := []string{
+		`name~"zh%" AND status:active AND (age>=18 OR vip:true)`,
+		`age>1 AND age<5 AND NOT (tag:(a, "b c") OR ctime:[*, 100]) AND dtime:null`,
+		`name:"OR" OR NOT (a:1 AND b!:null)`,
+		`name:"18" AND note=~"full text"`,
+	}
+
+	for _, query := range queries {
+		filter, err := entity.ParseFilterQuery(query)
+		assert.NoError(t, err)
+
+		s, err := entity.FormatFilter(filter)
+		assert.NoError(t, err)
+
+		// 输出的文本可以解析为相同的过滤条件
+		again, err := entity.ParseFilterQuery(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, filter, again, s)
+	}
+
+	s, err := entity.FormatFilter(map[string]interface{}{
+		"status": "active",
+		"OR": []interface{}{
+			map[string]interface{}{"age": map[string]interface{}{"GTE": 18}},
+			map[string]interface{}{"vip": true},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "(age>=18 OR vip:true) AND status:active", s)
+}
+
+func TestDecodeFilter(t *testing.T) {
+	filter, err := entity.DecodeFilter(`{"status":"active"}`, `age>=18`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"status": "active",
+		"age":    map[string]interface{}{"GTE": int64(18)},
+	}, filter)
+
+	filter, err = entity.DecodeFilter("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = entity.DecodeFilter("", "age>=")
+	assert.Error(t, err)
+}
+
+func TestQueryFromPB(t *testing.T) {
+	pbQuery := &pagination.ConnectionQuery{
+		FilterQuery: "age>=",
+		Fields:      []string{"id"},
+	}
+
+	_, err := entity.ConnectionQueryFromPBWithError(pbQuery)
+	assert.Error(t, err)
+
+	// 不返回错误的版本忽略格式错误的过滤条件
+	query := entity.ConnectionQueryFromPB(pbQuery)
+	assert.Nil(t, query.Filter)
+	assert.Equal(t, []string{"id"}, query.Fields)
+
+	var cursor entity.CursorQuery
+	assert.Error(t, cursor.FromPBWithError(&pagination.ListQuery{FilterQuery: "age>="}))
+	assert.NoError(t, cursor.FromPBWithError(&pagination.ListQuery{FilterQuery: "age>=18", Size: 10}))
+	assert.Equal(t, 10, cursor.Size)
+
+	var page entity.PageQuery
+	assert.Error(t, page.FromPBWithError(&pagination.PageQuery{Filter: "{"}))
+	page.FromPB(&pagination.PageQuery{Filter: "{", PageNo: 2})
+	assert.Nil(t, page.Filter)
+	assert.Equal(t, 2, page.PageNo)
+}
@@ -1,8 +1,52 @@
 package entity
 
+import (
+	"github.com/duolacloud/microbase/proto/pagination"
+	"github.com/thoas/go-funk"
+)
+
 type PageQuery struct {
-	Filter   map[string]interface{} `json:"filter"`
-	PageNo   int                    `json:"pageNo"`
-	PageSize int                    `json:"pageSize"`
-	Orders   []*Order               `json:"order"`
+	Filter         map[string]interface{} `json:"filter"`
+	PageNo         int                    `json:"pageNo"`
+	PageSize       int                    `json:"pageSize"`
+	Orders         []*Order               `json:"order"`
+	Fields         []string               `json:"fields"`
+	IncludeDeleted bool                   `json:"includeDeleted"` // 包含软删除的记录
+}
+
+// FromPB 忽略格式错误的过滤条件, 需要校验时使用 FromPBWithError
+func (q *PageQuery) FromPB(o *pagination.PageQuery) {
+	filter, _ := DecodeFilter(o.Filter, o.FilterQuery)
+	q.fromPB(o, filter)
+}
+
+// FromPBWithError 过滤条件格式错误时返回错误
+func (q *PageQuery) FromPBWithError(o *pagination.PageQuery) error {
+	filter, err := DecodeFilter(o.Filter, o.FilterQuery)
+	if err != nil {
+		return err
+	}
+	q.fromPB(o, filter)
+	return nil
+}
+
+func (q *PageQuery) fromPB(o *pagination.PageQuery, filter map[string]interface{}) {
+	q.Filter = filter
+	q.PageNo = int(o.PageNo)
+	q.PageSize = int(o.PageSize)
+	q.Orders = funk.Map(o.Orders, func(o *pagination.Order) *Order {
+		var direction OrderDirection
+		if o.Direction == pagination.OrderDirection_DESC {
+			direction = OrderDirectionDesc
+		} else {
+			direction = OrderDirectionAsc
+		}
+
+		return &Order{
+			Field:     o.Field,
+			Direction: direction,
+		}
+	}).([]*Order)
+	q.Fields = o.Fields
+	q.IncludeDeleted = o.IncludeDeleted
 }
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"fmt"
+)
+
+// ProjectFields 校验查询指定的字段, 并追加 required 中缺少的字段 (例如主键和游标字段),
+// fields 为空时返回 nil, 表示获取全部字段; known 为 nil 时不校验字段
+func ProjectFields(fields []string, known FilterFields, required ...string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	projected := make([]string, 0, len(fields)+len(required))
+	seen := make(map[string]bool, len(fields)+len(required))
+
+	for i, field := range fields {
+		if known != nil {
+			if _, ok := known[field]; !ok {
+				return nil, fmt.Errorf("fields[%d] %s: %w", i, field, ErrUnknownField)
+			}
+		}
+
+		if !seen[field] {
+			seen[field] = true
+			projected = append(projected, field)
+		}
+	}
+
+	for _, field := range required {
+		if !seen[field] {
+			seen[field] = true
+			projected = append(projected, field)
+		}
+	}
+
+	return projected, nil
+}
+
+// orderFields 排序字段, 游标由排序字段的值编码, 投影时必须包含
+func orderFields(orders []*Order) []string {
+	fields := make([]string, len(orders))
+	for i, order := range orders {
+		fields[i] = order.Field
+	}
+	return fields
+}
+
+// ProjectFields 查询的字段, 总是包含游标字段
+func (q *CursorQuery) ProjectFields(known FilterFields, required ...string) ([]string, error) {
+	return ProjectFields(q.Fields, known, append(orderFields(q.Orders), required...)...)
+}
+
+// ProjectFields 查询的字段, 总是包含游标字段
+func (q *ConnectionQuery) ProjectFields(known FilterFields, required ...string) ([]string, error) {
+	return ProjectFields(q.Fields, known, append(orderFields(q.Orders), required...)...)
+}
+
+// ProjectFields 查询的字段
+func (q *PageQuery) ProjectFields(known FilterFields, required ...string) ([]string, error) {
+	return ProjectFields(q.Fields, known, required...)
+}
@@ -0,0 +1,39 @@
+package entity_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectFields(t *testing.T) {
+	known := entity.FilterFields{
+		"id":    reflect.TypeOf(""),
+		"name":  reflect.TypeOf(""),
+		"age":   reflect.TypeOf(0),
+		"ctime": reflect.TypeOf(0),
+	}
+
+	fields, err := entity.ProjectFields(nil, known, "id")
+	assert.NoError(t, err)
+	assert.Nil(t, fields)
+
+	query := &entity.CursorQuery{
+		Fields: []string{"name", "name"},
+		Orders: []*entity.Order{{Field: "ctime"}, {Field: "id"}},
+	}
+	fields, err = query.ProjectFields(known, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "ctime", "id"}, fields)
+
+	query.Fields = []string{"name", "password"}
+	_, err = query.ProjectFields(known)
+	assert.True(t, errors.Is(err, entity.ErrUnknownField))
+
+	fields, err = query.ProjectFields(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "password", "ctime", "id"}, fields)
+}
@@ -0,0 +1,303 @@
+package audited
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/duolacloud/microbase/audit"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/logger"
+)
+
+// BaseRepository 记录审计日志的仓储, 用于没有写入钩子的 search 和 mongo 后端,
+// 写操作成功后读取实体比较前后的字段, gorm 后端通过 datasource/gorm 注册的回调记录
+type BaseRepository struct {
+	repo     repository.BaseRepository
+	recorder audit.Recorder
+}
+
+// NewBaseRepository 包装任意的 repository.BaseRepository, 审计记录由 recorder 保存
+func NewBaseRepository(repo repository.BaseRepository, recorder audit.Recorder) repository.BaseRepository {
+	return &BaseRepository{
+		repo:     repo,
+		recorder: recorder,
+	}
+}
+
+type recorder struct {
+	repo repository.BaseRepository
+}
+
+// NewRecorder 把审计记录作为 audit.Record 实体写入 repo,
+// 之后可以通过 repo.Connection(ctx, query, &audit.Record{}) 分页查询
+func NewRecorder(repo repository.BaseRepository) audit.Recorder {
+	return &recorder{
+		repo: repo,
+	}
+}
+
+func (r *recorder) Record(c context.Context, records ...*audit.Record) error {
+	for _, record := range records {
+		if err := r.repo.Create(c, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *BaseRepository) Get(c context.Context, m entity.Entity) error {
+	return r.repo.Get(c, m)
+}
+
+func (r *BaseRepository) Create(c context.Context, m entity.Entity) error {
+	if err := r.repo.Create(c, m); err != nil {
+		return err
+	}
+
+	r.record(c, audit.OperationCreate, m, nil, r.fields(m))
+	return nil
+}
+
+func (r *BaseRepository) Upsert(c context.Context, m entity.Entity) (*repository.ChangeInfo, error) {
+	before := r.snapshot(c, m)
+
+	change, err := r.repo.Upsert(c, m)
+	if err != nil {
+		return nil, err
+	}
+
+	r.record(c, audit.OperationUpsert, m, before, r.fields(m))
+	return change, nil
+}
+
+// Update 只写入 data 中的字段, 写入后重新读取实体
+func (r *BaseRepository) Update(c context.Context, m entity.Entity, data interface{}) error {
+	before := r.snapshot(c, m)
+
+	if err := r.repo.Update(c, m, data); err != nil {
+		return err
+	}
+
+	r.record(c, audit.OperationUpdate, m, before, r.snapshot(c, m))
+	return nil
+}
+
+func (r *BaseRepository) Delete(c context.Context, m entity.Entity) error {
+	before := r.snapshot(c, m)
+
+	if err := r.repo.Delete(c, m); err != nil {
+		return err
+	}
+
+	r.record(c, audit.OperationDelete, m, before, nil)
+	return nil
+}
+
+// Restore 软删除的实体 Get 不到, 审计记录的 Before 为空
+func (r *BaseRepository) Restore(c context.Context, m entity.Entity) error {
+	if err := r.repo.Restore(c, m); err != nil {
+		return err
+	}
+
+	r.record(c, audit.OperationRestore, m, nil, r.snapshot(c, m))
+	return nil
+}
+
+func (r *BaseRepository) HardDelete(c context.Context, m entity.Entity) error {
+	before := r.snapshot(c, m)
+
+	if err := r.repo.HardDelete(c, m); err != nil {
+		return err
+	}
+
+	r.record(c, audit.OperationHardDelete, m, before, nil)
+	return nil
+}
+
+func (r *BaseRepository) BatchCreate(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchCreate(c, ms)
+	r.recordBatch(c, audit.OperationCreate, ms, make([]map[string]interface{}, len(ms)), results, false)
+	return results, err
+}
+
+func (r *BaseRepository) BatchUpsert(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	befores := r.snapshots(c, ms)
+
+	results, err := r.repo.BatchUpsert(c, ms)
+	r.recordBatch(c, audit.OperationUpsert, ms, befores, results, false)
+	return results, err
+}
+
+func (r *BaseRepository) BatchDelete(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	befores := r.snapshots(c, ms)
+
+	results, err := r.repo.BatchDelete(c, ms)
+	r.recordBatch(c, audit.OperationDelete, ms, befores, results, true)
+	return results, err
+}
+
+func (r *BaseRepository) Count(c context.Context, m entity.Entity, filter map[string]interface{}) (int64, error) {
+	return r.repo.Count(c, m, filter)
+}
+
+func (r *BaseRepository) Exists(c context.Context, m entity.Entity, filter map[string]interface{}) (bool, error) {
+	return r.repo.Exists(c, m, filter)
+}
+
+func (r *BaseRepository) FindOne(c context.Context, m entity.Entity, filter map[string]interface{}) error {
+	return r.repo.FindOne(c, m, filter)
+}
+
+// UpdateMany 不逐条读取被修改的实体, 记录一条带过滤条件的审计记录, Changes 只有修改后的值
+func (r *BaseRepository) UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	n, err := r.repo.UpdateMany(c, m, filter, change, opts...)
+	if err != nil || n == 0 {
+		return n, err
+	}
+
+	r.recordMass(c, audit.OperationUpdate, m, filter, audit.Diff(nil, r.fields(change)))
+	return n, nil
+}
+
+func (r *BaseRepository) DeleteMany(c context.Context, m entity.Entity, filter map[string]interface{}, opts ...repository.MassOption) (int64, error) {
+	n, err := r.repo.DeleteMany(c, m, filter, opts...)
+	if err != nil || n == 0 {
+		return n, err
+	}
+
+	r.recordMass(c, audit.OperationDelete, m, filter, audit.Changes{})
+	return n, nil
+}
+
+func (r *BaseRepository) Page(c context.Context, m entity.Entity, query *entity.PageQuery, resultPtr interface{}) (total int64, err error) {
+	return r.repo.Page(c, m, query, resultPtr)
+}
+
+func (r *BaseRepository) List(c context.Context, query *entity.CursorQuery, m entity.Entity, resultPtr interface{}) (*entity.CursorExtra, error) {
+	return r.repo.List(c, query, m, resultPtr)
+}
+
+func (r *BaseRepository) Connection(c context.Context, query *entity.ConnectionQuery, m entity.Entity) (*entity.Connection, error) {
+	return r.repo.Connection(c, query, m)
+}
+
+func (r *BaseRepository) Aggregate(c context.Context, m entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	return r.repo.Aggregate(c, m, query)
+}
+
+// skip 审计记录本身的写入不再审计
+func skip(m interface{}) bool {
+	_, ok := m.(*audit.Record)
+	return ok
+}
+
+// snapshot 按主键读取实体当前的字段, 不修改 m, 实体不存在时返回 nil
+func (r *BaseRepository) snapshot(c context.Context, m entity.Entity) map[string]interface{} {
+	if skip(m) {
+		return nil
+	}
+
+	v := reflect.New(reflect.TypeOf(m).Elem())
+	v.Elem().Set(reflect.ValueOf(m).Elem())
+	current := v.Interface().(entity.Entity)
+
+	if err := r.repo.Get(c, current); err != nil {
+		if !repository.IsNotFound(err) {
+			logger.Errorf("audited repository get %s error: %v", audit.EntityType(m), err)
+		}
+		return nil
+	}
+	return r.fields(current)
+}
+
+func (r *BaseRepository) snapshots(c context.Context, ms []entity.Entity) []map[string]interface{} {
+	befores := make([]map[string]interface{}, len(ms))
+	for i, m := range ms {
+		befores[i] = r.snapshot(c, m)
+	}
+	return befores
+}
+
+func (r *BaseRepository) fields(m interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	fields, err := audit.Fields(m)
+	if err != nil {
+		logger.Errorf("audited repository fields of %T error: %v", m, err)
+	}
+	return fields
+}
+
+// record 写操作已经生效, 审计记录写入失败只记录日志, 没有变化的写操作不记录
+func (r *BaseRepository) record(c context.Context, op audit.Operation, m entity.Entity, before, after map[string]interface{}) {
+	if skip(m) {
+		return
+	}
+
+	changes := audit.Diff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	record := audit.NewRecord(c, op, audit.EntityType(m), entityID(m), changes)
+	if err := r.recorder.Record(c, record); err != nil {
+		logger.Errorf("audited repository record %s %s error: %v", op, record.EntityType, err)
+	}
+}
+
+// recordBatch 只记录成功的实体, deleted 为 true 时修改后的值为空
+func (r *BaseRepository) recordBatch(c context.Context, op audit.Operation, ms []entity.Entity, befores []map[string]interface{}, results []*repository.BatchResult, deleted bool) {
+	records := make([]*audit.Record, 0, len(results))
+	for i, result := range results {
+		if i >= len(ms) || result.Error != nil || skip(ms[i]) {
+			continue
+		}
+
+		var after map[string]interface{}
+		if !deleted {
+			after = r.fields(ms[i])
+		}
+		changes := audit.Diff(befores[i], after)
+		if len(changes) == 0 {
+			continue
+		}
+		records = append(records, audit.NewRecord(c, op, audit.EntityType(ms[i]), entityID(ms[i]), changes))
+	}
+
+	if len(records) == 0 {
+		return
+	}
+
+	if err := r.recorder.Record(c, records...); err != nil {
+		logger.Errorf("audited repository record batch %s error: %v", op, err)
+	}
+}
+
+func (r *BaseRepository) recordMass(c context.Context, op audit.Operation, m entity.Entity, filter map[string]interface{}, changes audit.Changes) {
+	if skip(m) {
+		return
+	}
+
+	record := audit.NewRecord(c, op, audit.EntityType(m), "", changes)
+	if b, err := json.Marshal(filter); err == nil {
+		record.Filter = string(b)
+	}
+
+	if err := r.recorder.Record(c, record); err != nil {
+		logger.Errorf("audited repository record %s %s error: %v", op, record.EntityType, err)
+	}
+}
+
+// entityID Unique() 通常为主键字段的 map, 其它类型按 json 序列化
+func entityID(m entity.Entity) string {
+	if keys, ok := m.Unique().(map[string]interface{}); ok {
+		return audit.EntityID(keys)
+	}
+
+	b, _ := json.Marshal(m.Unique())
+	return string(b)
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/duolacloud/microbase/domain/entity"
 )
 
+// TagKey 仓储读取的字段 tag, 各个功能共用, 例如 repo:"version" 和 repo:"index"
+const TagKey = "repo"
+
 type ChangeInfo struct {
 	Updated    int
 	Removed    int         // Number of documents removed
@@ -13,9 +16,26 @@ type ChangeInfo struct {
 	UpsertedId interface{} // Upserted _id field, when not explicitly provided
 }
 
+// BatchResult 批量写入中单条数据的结果
+type BatchResult struct {
+	Error error
+}
+
+// NewBatchResults 创建与入参一一对应的结果
+func NewBatchResults(n int) []*BatchResult {
+	results := make([]*BatchResult, n)
+	for i := range results {
+		results[i] = &BatchResult{}
+	}
+	return results
+}
+
 type BaseRepository interface {
+	// 实体有版本字段时 Create 的版本号从 1 开始
 	Create(c context.Context, m entity.Entity) error
 
+	// 实体有版本字段时, 版本号为 0 表示新建, 否则只更新版本号一致的记录,
+	// 写入成功后 m 的版本号加一, 版本号不一致时返回 ErrConflict
 	Upsert(c context.Context, m entity.Entity) (*ChangeInfo, error)
 
 	Update(c context.Context, m entity.Entity, change interface{}) error
@@ -31,6 +51,36 @@ type BaseRepository interface {
 	// m	数据对象
 	Delete(c context.Context, m entity.Entity) error
 
+	// 恢复软删除的记录，记录不存在或者没有被删除时返回 ErrNotFound
+	Restore(c context.Context, m entity.Entity) error
+
+	// 根据主键物理删除，不经过软删除
+	HardDelete(c context.Context, m entity.Entity) error
+
+	// 批量写入，返回的结果与 ms 一一对应
+	// error 不为空时表示整批失败，否则单条数据的错误记录在对应的 BatchResult 中
+	BatchCreate(c context.Context, ms []entity.Entity) ([]*BatchResult, error)
+
+	BatchUpsert(c context.Context, ms []entity.Entity) ([]*BatchResult, error)
+
+	// 根据主键批量删除
+	BatchDelete(c context.Context, ms []entity.Entity) ([]*BatchResult, error)
+
+	// 按过滤条件查询，filter 与 PageQuery.Filter 的格式相同
+	// m	数据指针，仅用于帮助推导数据类型
+	Count(c context.Context, m entity.Entity, filter map[string]interface{}) (int64, error)
+
+	Exists(c context.Context, m entity.Entity, filter map[string]interface{}) (bool, error)
+
+	// 查询一条满足条件的记录写入 m，不存在时返回 ErrNotFound
+	FindOne(c context.Context, m entity.Entity, filter map[string]interface{}) error
+
+	// 批量更新和删除满足条件的记录，返回影响的记录数
+	// filter 为空时返回 ErrEmptyFilter，除非传入 Force()
+	UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...MassOption) (int64, error)
+
+	DeleteMany(c context.Context, m entity.Entity, filter map[string]interface{}, opts ...MassOption) (int64, error)
+
 	// 游标查询
 	// @c	上下文
 	// @query	查询条件
@@ -43,4 +93,8 @@ type BaseRepository interface {
 	// @query	查询条件
 	// m	数据指针，仅用于帮助推导数据类型
 	Connection(c context.Context, query *entity.ConnectionQuery, m entity.Entity) (*entity.Connection, error)
+
+	// 分组统计，返回每个分组的统计结果
+	// m	数据指针，仅用于帮助推导数据类型
+	Aggregate(c context.Context, m entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error)
 }
@@ -0,0 +1,293 @@
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/datasource"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/logger"
+	"github.com/duolacloud/microbase/multitenancy"
+	breflect "github.com/duolacloud/microbase/reflect"
+)
+
+// DefaultExpiry 实体未声明缓存时间时使用的缓存时间
+var DefaultExpiry = 5 * time.Minute
+
+// BaseRepository 带读缓存的仓储, Get 优先读取缓存, 写操作成功后删除缓存;
+// 在 dsgorm.TxManager 开启的事务中 Get 不读写缓存, 写操作在事务提交后才删除缓存
+type BaseRepository struct {
+	repo      repository.BaseRepository
+	cache     cache.Cache
+	entityMap datasource.EntityMap
+}
+
+// NewBaseRepository 包装任意的 repository.BaseRepository,
+// 如果 entityMap 实现了 datasource.CachePolicyMap, 按实体声明的策略缓存
+func NewBaseRepository(repo repository.BaseRepository, c cache.Cache, entityMap datasource.EntityMap) repository.BaseRepository {
+	return &BaseRepository{
+		repo:      repo,
+		cache:     c,
+		entityMap: entityMap,
+	}
+}
+
+func (r *BaseRepository) policy(m entity.Entity) datasource.CachePolicy {
+	if policies, ok := r.entityMap.(datasource.CachePolicyMap); ok {
+		if policy, ok := policies.GetCachePolicy(m); ok {
+			if policy.Expiry <= 0 {
+				policy.Expiry = DefaultExpiry
+			}
+			return policy
+		}
+	}
+
+	return datasource.CachePolicy{
+		Expiry: DefaultExpiry,
+	}
+}
+
+// key 缓存的 key: entity:{类型}:{租户}:{Unique()}
+func (r *BaseRepository) key(c context.Context, m entity.Entity) (string, error) {
+	unique, err := json.Marshal(m.Unique())
+	if err != nil {
+		return "", err
+	}
+
+	return r.prefix(c, m) + string(unique), nil
+}
+
+// prefix 同一租户下同一类型实体缓存 key 的公共前缀
+func (r *BaseRepository) prefix(c context.Context, m entity.Entity) string {
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	tenantId, _ := multitenancy.FromContext(c)
+
+	return fmt.Sprintf("entity:%s:%s:", breflect.TheNamingStrategy.Table(t.Name()), tenantId)
+}
+
+// missKey 记录不存在时的缓存 key
+func missKey(key string) string {
+	return key + ":miss"
+}
+
+func (r *BaseRepository) Get(c context.Context, m entity.Entity) error {
+	// 事务中可能读到未提交的记录, 缓存中也可能是事务修改之前的值
+	policy := r.policy(m)
+	if policy.Disabled || dsgorm.InTx(c) {
+		return r.repo.Get(c, m)
+	}
+
+	key, err := r.key(c, m)
+	if err != nil {
+		return err
+	}
+
+	if policy.NegativeExpiry > 0 && r.cache.ExistsContext(c, missKey(key)) {
+		return repository.ErrNotFound
+	}
+
+	return r.cache.GetOrLoadContext(c, key, m, func(ctx context.Context) (interface{}, error) {
+		if err := r.repo.Get(ctx, m); err != nil {
+			if policy.NegativeExpiry > 0 && repository.IsNotFound(err) {
+				if err := r.cache.SetContext(ctx, missKey(key), true, cache.WriteExpiry(policy.NegativeExpiry)); err != nil {
+					logger.Errorf("cached repository set %s error: %v", missKey(key), err)
+				}
+			}
+			return nil, err
+		}
+
+		// 缓存副本, 避免调用方修改 m 影响缓存中的值
+		v := reflect.New(reflect.TypeOf(m).Elem())
+		v.Elem().Set(reflect.ValueOf(m).Elem())
+		return v.Interface(), nil
+	}, cache.WriteExpiry(policy.Expiry))
+}
+
+func (r *BaseRepository) Create(c context.Context, m entity.Entity) error {
+	if err := r.repo.Create(c, m); err != nil {
+		return err
+	}
+
+	r.invalidate(c, m)
+	return nil
+}
+
+func (r *BaseRepository) Upsert(c context.Context, m entity.Entity) (*repository.ChangeInfo, error) {
+	change, err := r.repo.Upsert(c, m)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(c, m)
+	return change, nil
+}
+
+func (r *BaseRepository) Update(c context.Context, m entity.Entity, data interface{}) error {
+	if err := r.repo.Update(c, m, data); err != nil {
+		return err
+	}
+
+	r.invalidate(c, m)
+	return nil
+}
+
+func (r *BaseRepository) Delete(c context.Context, m entity.Entity) error {
+	if err := r.repo.Delete(c, m); err != nil {
+		return err
+	}
+
+	r.invalidate(c, m)
+	return nil
+}
+
+// Restore 之前 Get 可能缓存了记录不存在, 恢复后同样删除缓存
+func (r *BaseRepository) Restore(c context.Context, m entity.Entity) error {
+	if err := r.repo.Restore(c, m); err != nil {
+		return err
+	}
+
+	r.invalidate(c, m)
+	return nil
+}
+
+func (r *BaseRepository) HardDelete(c context.Context, m entity.Entity) error {
+	if err := r.repo.HardDelete(c, m); err != nil {
+		return err
+	}
+
+	r.invalidate(c, m)
+	return nil
+}
+
+func (r *BaseRepository) BatchCreate(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchCreate(c, ms)
+	r.invalidateBatch(c, ms, results)
+	return results, err
+}
+
+func (r *BaseRepository) BatchUpsert(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchUpsert(c, ms)
+	r.invalidateBatch(c, ms, results)
+	return results, err
+}
+
+func (r *BaseRepository) BatchDelete(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchDelete(c, ms)
+	r.invalidateBatch(c, ms, results)
+	return results, err
+}
+
+func (r *BaseRepository) Count(c context.Context, m entity.Entity, filter map[string]interface{}) (int64, error) {
+	return r.repo.Count(c, m, filter)
+}
+
+func (r *BaseRepository) Exists(c context.Context, m entity.Entity, filter map[string]interface{}) (bool, error) {
+	return r.repo.Exists(c, m, filter)
+}
+
+func (r *BaseRepository) FindOne(c context.Context, m entity.Entity, filter map[string]interface{}) error {
+	return r.repo.FindOne(c, m, filter)
+}
+
+// UpdateMany 无法知道哪些实体被修改, 删除该类型在当前租户下的全部缓存
+func (r *BaseRepository) UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	n, err := r.repo.UpdateMany(c, m, filter, change, opts...)
+	if err != nil {
+		return n, err
+	}
+
+	r.invalidateAll(c, m)
+	return n, nil
+}
+
+func (r *BaseRepository) DeleteMany(c context.Context, m entity.Entity, filter map[string]interface{}, opts ...repository.MassOption) (int64, error) {
+	n, err := r.repo.DeleteMany(c, m, filter, opts...)
+	if err != nil {
+		return n, err
+	}
+
+	r.invalidateAll(c, m)
+	return n, nil
+}
+
+func (r *BaseRepository) Page(c context.Context, m entity.Entity, query *entity.PageQuery, resultPtr interface{}) (total int64, err error) {
+	return r.repo.Page(c, m, query, resultPtr)
+}
+
+func (r *BaseRepository) List(c context.Context, query *entity.CursorQuery, m entity.Entity, resultPtr interface{}) (*entity.CursorExtra, error) {
+	return r.repo.List(c, query, m, resultPtr)
+}
+
+func (r *BaseRepository) Connection(c context.Context, query *entity.ConnectionQuery, m entity.Entity) (*entity.Connection, error) {
+	return r.repo.Connection(c, query, m)
+}
+
+func (r *BaseRepository) Aggregate(c context.Context, m entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	return r.repo.Aggregate(c, m, query)
+}
+
+// afterCommit 事务中的写入在提交后才删除缓存, 否则提交前并发的 Get 会把旧的记录重新缓存; 回滚时缓存不变
+func afterCommit(c context.Context, fn func()) {
+	if dsgorm.InTx(c) {
+		dsgorm.AfterCommit(c, fn)
+		return
+	}
+	fn()
+}
+
+// invalidateBatch 删除批量写入中成功的实体的缓存
+func (r *BaseRepository) invalidateBatch(c context.Context, ms []entity.Entity, results []*repository.BatchResult) {
+	for i, result := range results {
+		if i < len(ms) && result.Error == nil {
+			r.invalidate(c, ms[i])
+		}
+	}
+}
+
+// invalidate 删除实体的缓存和不存在标记, 写操作已经成功, 删除失败只记录日志
+func (r *BaseRepository) invalidate(c context.Context, m entity.Entity) {
+	if r.policy(m).Disabled {
+		return
+	}
+
+	// 写操作已经生效, 即使 ctx 已取消也必须删除缓存
+	c = cache.Detach(c)
+
+	key, err := r.key(c, m)
+	if err != nil {
+		logger.Errorf("cached repository key error: %v", err)
+		return
+	}
+
+	afterCommit(c, func() {
+		for _, k := range []string{key, missKey(key)} {
+			if err := r.cache.DeleteContext(c, k); err != nil {
+				logger.Errorf("cached repository delete %s error: %v", k, err)
+			}
+		}
+	})
+}
+
+// invalidateAll 删除实体类型在当前租户下的全部缓存
+func (r *BaseRepository) invalidateAll(c context.Context, m entity.Entity) {
+	if r.policy(m).Disabled {
+		return
+	}
+
+	prefix := r.prefix(c, m)
+	afterCommit(c, func() {
+		if err := r.cache.DeleteByPrefixContext(cache.Detach(c), prefix); err != nil {
+			logger.Errorf("cached repository delete prefix %s error: %v", prefix, err)
+		}
+	})
+}
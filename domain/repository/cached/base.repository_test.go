@@ -0,0 +1,281 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duolacloud/microbase/cache/memory"
+	"github.com/duolacloud/microbase/datasource"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/domain/repository/gorm"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/micro/go-micro/v2/config"
+	source "github.com/micro/go-micro/v2/config/source/memory"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (u *User) Unique() interface{} {
+	return map[string]interface{}{
+		"id": u.ID,
+	}
+}
+
+// fakeRepository 记录 Get 次数的内存仓储
+type fakeRepository struct {
+	repository.BaseRepository
+	users map[string]User
+	gets  int
+}
+
+func (r *fakeRepository) Get(c context.Context, m entity.Entity) error {
+	r.gets++
+	u := m.(*User)
+	found, ok := r.users[u.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	*u = found
+	return nil
+}
+
+func (r *fakeRepository) Update(c context.Context, m entity.Entity, data interface{}) error {
+	u := m.(*User)
+	r.users[u.ID] = User{ID: u.ID, Name: data.(string)}
+	return nil
+}
+
+func (r *fakeRepository) Create(c context.Context, m entity.Entity) error {
+	u := m.(*User)
+	r.users[u.ID] = *u
+	return nil
+}
+
+func (r *fakeRepository) BatchUpsert(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+	for i, m := range ms {
+		u := m.(*User)
+		if u.Name == "" {
+			results[i].Error = errors.New("name is empty")
+			continue
+		}
+		r.users[u.ID] = *u
+	}
+	return results, nil
+}
+
+// UpdateMany 只支持按 name 过滤
+func (r *fakeRepository) UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for id, u := range r.users {
+		if u.Name == filter["name"] {
+			r.users[id] = User{ID: id, Name: change.(string)}
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestGet(t *testing.T) {
+	repo := &fakeRepository{users: map[string]User{"1": {ID: "1", Name: "a"}}}
+	entities := datasource.NewEntities().Register(&User{}, datasource.CachePolicy{
+		Expiry:         time.Minute,
+		NegativeExpiry: time.Minute,
+	})
+	c := memory.NewCache()
+	c.Init()
+	r := NewBaseRepository(repo, c, entities)
+
+	ctx := context.WithValue(context.Background(), multitenancy.TenantId, "t1")
+
+	for i := 0; i < 3; i++ {
+		u := &User{ID: "1"}
+		assert.NoError(t, r.Get(ctx, u))
+		assert.Equal(t, "a", u.Name)
+	}
+	assert.Equal(t, 1, repo.gets)
+
+	// 其他租户使用独立的缓存
+	u := &User{ID: "1"}
+	assert.NoError(t, r.Get(context.Background(), u))
+	assert.Equal(t, 2, repo.gets)
+
+	assert.NoError(t, r.Update(ctx, &User{ID: "1"}, "b"))
+	u = &User{ID: "1"}
+	assert.NoError(t, r.Get(ctx, u))
+	assert.Equal(t, "b", u.Name)
+	assert.Equal(t, 3, repo.gets)
+
+	// 不存在的记录被缓存, 创建后失效
+	for i := 0; i < 2; i++ {
+		assert.True(t, repository.IsNotFound(r.Get(ctx, &User{ID: "2"})))
+	}
+	assert.Equal(t, 4, repo.gets)
+
+	assert.NoError(t, r.Create(ctx, &User{ID: "2", Name: "c"}))
+	u = &User{ID: "2"}
+	assert.NoError(t, r.Get(ctx, u))
+	assert.Equal(t, "c", u.Name)
+}
+
+func TestDisabled(t *testing.T) {
+	repo := &fakeRepository{users: map[string]User{"1": {ID: "1", Name: "a"}}}
+	entities := datasource.NewEntities().Register(&User{}, datasource.CachePolicy{Disabled: true})
+	c := memory.NewCache()
+	c.Init()
+	r := NewBaseRepository(repo, c, entities)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, r.Get(context.Background(), &User{ID: "1"}))
+	}
+	assert.Equal(t, 2, repo.gets)
+}
+
+func TestBatchUpsert(t *testing.T) {
+	repo := &fakeRepository{users: map[string]User{"1": {ID: "1", Name: "a"}, "2": {ID: "2", Name: "b"}}}
+	c := memory.NewCache()
+	c.Init()
+	r := NewBaseRepository(repo, c, datasource.NewEntities().Register(&User{}))
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "2"} {
+		assert.NoError(t, r.Get(ctx, &User{ID: id}))
+	}
+
+	results, err := r.BatchUpsert(ctx, []entity.Entity{&User{ID: "1", Name: "c"}, &User{ID: "2"}})
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Error)
+	assert.Error(t, results[1].Error)
+
+	// 只有写入成功的实体缓存失效
+	u := &User{ID: "1"}
+	assert.NoError(t, r.Get(ctx, u))
+	assert.Equal(t, "c", u.Name)
+	assert.Equal(t, 3, repo.gets)
+
+	assert.NoError(t, r.Get(ctx, &User{ID: "2"}))
+	assert.Equal(t, 3, repo.gets)
+}
+
+func TestUpdateMany(t *testing.T) {
+	repo := &fakeRepository{users: map[string]User{"1": {ID: "1", Name: "a"}, "2": {ID: "2", Name: "a"}}}
+	c := memory.NewCache()
+	c.Init()
+	r := NewBaseRepository(repo, c, datasource.NewEntities().Register(&User{}))
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "2"} {
+		assert.NoError(t, r.Get(ctx, &User{ID: id}))
+	}
+
+	_, err := r.UpdateMany(ctx, &User{}, nil, "b")
+	assert.Equal(t, repository.ErrEmptyFilter, err)
+
+	n, err := r.UpdateMany(ctx, &User{}, map[string]interface{}{"name": "a"}, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// 批量更新后该类型的缓存全部失效
+	for _, id := range []string{"1", "2"} {
+		u := &User{ID: id}
+		assert.NoError(t, r.Get(ctx, u))
+		assert.Equal(t, "b", u.Name)
+	}
+	assert.Equal(t, 4, repo.gets)
+}
+
+type Member struct {
+	ID   string `json:"id" gorm:"primary_key"`
+	Name string `json:"name"`
+}
+
+func (m *Member) Unique() interface{} {
+	return map[string]interface{}{
+		"id": m.ID,
+	}
+}
+
+// TestRunInTx 需要本地的 mysql, 事务中 Get 不读写缓存, 提交后才删除缓存, 回滚时缓存不变
+func TestRunInTx(t *testing.T) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{
+		"db": {
+			"driver": "mysql",
+			"connection_string": "root:debezium@tcp(localhost:3306)/test?charset=utf8mb4&parseTime=True&loc=Local"
+		}
+	}`)
+	if err = cfg.Load(source.NewSource(source.WithJSON(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	entities := datasource.NewEntities().Register(&Member{})
+	tenancy, err := dsgorm.NewGormTenancy(cfg, entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := memory.NewCache()
+	c.Init()
+	r := NewBaseRepository(gorm.NewBaseRepository(repository.NewMultitenancyProvider(tenancy)), c, entities)
+	txManager := dsgorm.NewTxManager(tenancy)
+
+	ctx := multitenancy.WithContext(context.Background(), "t1")
+	member := &Member{ID: uuid.NewV4().String(), Name: "tom"}
+	assert.NoError(t, r.Create(ctx, member))
+	assert.NoError(t, r.Get(ctx, &Member{ID: member.ID}))
+
+	key, err := r.(*BaseRepository).key(ctx, member)
+	assert.NoError(t, err)
+	assert.True(t, c.ExistsContext(ctx, key))
+
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := r.Update(ctx, &Member{ID: member.ID}, map[string]interface{}{"name": "jerry"}); err != nil {
+			return err
+		}
+
+		// 提交前缓存中仍是旧的记录, 事务中读取到未提交的记录
+		assert.True(t, c.ExistsContext(ctx, key))
+		got := &Member{ID: member.ID}
+		assert.NoError(t, r.Get(ctx, got))
+		assert.Equal(t, "jerry", got.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, c.ExistsContext(ctx, key))
+
+	got := &Member{ID: member.ID}
+	assert.NoError(t, r.Get(ctx, got))
+	assert.Equal(t, "jerry", got.Name)
+
+	// 回滚的记录不会被缓存
+	phantom := &Member{ID: uuid.NewV4().String(), Name: "spike"}
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := r.Create(ctx, phantom); err != nil {
+			return err
+		}
+		assert.NoError(t, r.Get(ctx, &Member{ID: phantom.ID}))
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+
+	phantomKey, err := r.(*BaseRepository).key(ctx, phantom)
+	assert.NoError(t, err)
+	assert.False(t, c.ExistsContext(ctx, phantomKey))
+	assert.True(t, repository.IsNotFound(r.Get(ctx, &Member{ID: phantom.ID})))
+}
@@ -24,6 +24,10 @@ func NewMultitenancyProvider(tenancy multitenancy.Tenancy) DataSourceProvider {
 
 func (p *MultitenancyProvider) ProvideDB(c context.Context) (interface{}, error) {
 	tenantId, _ := multitenancy.FromContext(c)
+	if db, ok := multitenancy.ResourceFromContext(c, p.tenancy, tenantId); ok {
+		return db, nil
+	}
+
 	db, err := p.tenancy.ResourceFor(c, tenantId)
 	return db, err
 }
@@ -0,0 +1,177 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/olivere/elastic/v6"
+)
+
+// 每一层分组最多返回的桶数
+const defaultAggregateSize = 1000
+
+// Aggregator 分组统计, 每个分组字段对应一层 terms 聚合, 时间分桶为最内层的 date_histogram 聚合
+type Aggregator struct {
+	client *elastic.Client
+}
+
+func NewAggregator(client *elastic.Client) *Aggregator {
+	return &Aggregator{
+		client,
+	}
+}
+
+// groupKey, metricKey 聚合按位置命名, 避免和字段名冲突
+func groupKey(i int) string {
+	return fmt.Sprintf("group_%d", i)
+}
+
+func metricKey(i int) string {
+	return fmt.Sprintf("metric_%d", i)
+}
+
+func (a *Aggregator) Aggregate(c context.Context, query *entity.AggregateQuery, index, typ string) ([]*entity.AggregateBucket, error) {
+	// 索引没有字段元数据, 只校验格式
+	if err := query.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	filter, err := applyFilter(c, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	searchService := a.client.Search().
+		Index(index).
+		Type(typ).
+		Query(filter).
+		Size(0)
+
+	for name, agg := range a.subAggregations(query, 0) {
+		searchService.Aggregation(name, agg)
+	}
+
+	result, err := searchService.Do(c)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]*entity.AggregateBucket, 0)
+	a.collect(query, result.Aggregations, 0, map[string]interface{}{}, result.TotalHits(), &buckets)
+
+	if query.Size > 0 && len(buckets) > query.Size {
+		buckets = buckets[:query.Size]
+	}
+	return buckets, nil
+}
+
+// subAggregations 第 i 层分组下的聚合, 最内层为统计指标, 记录数直接使用 doc_count
+func (a *Aggregator) subAggregations(query *entity.AggregateQuery, i int) map[string]elastic.Aggregation {
+	if i < len(query.KeyFields()) {
+		return map[string]elastic.Aggregation{groupKey(i): a.bucketAggregation(query, i)}
+	}
+
+	aggs := make(map[string]elastic.Aggregation)
+	for j, metric := range query.Metrics {
+		switch metric.Func {
+		case entity.AggregateFuncCount:
+			if metric.Field != "" {
+				aggs[metricKey(j)] = elastic.NewValueCountAggregation().Field(metric.Field)
+			}
+		case entity.AggregateFuncSum:
+			aggs[metricKey(j)] = elastic.NewSumAggregation().Field(metric.Field)
+		case entity.AggregateFuncAvg:
+			aggs[metricKey(j)] = elastic.NewAvgAggregation().Field(metric.Field)
+		case entity.AggregateFuncMin:
+			aggs[metricKey(j)] = elastic.NewMinAggregation().Field(metric.Field)
+		case entity.AggregateFuncMax:
+			aggs[metricKey(j)] = elastic.NewMaxAggregation().Field(metric.Field)
+		}
+	}
+	return aggs
+}
+
+func (a *Aggregator) bucketAggregation(query *entity.AggregateQuery, i int) elastic.Aggregation {
+	size := query.Size
+	if size <= 0 {
+		size = defaultAggregateSize
+	}
+
+	if i < len(query.GroupBy) {
+		agg := elastic.NewTermsAggregation().
+			Field(query.GroupBy[i]).
+			Size(size).
+			OrderByKeyAsc()
+		for name, sub := range a.subAggregations(query, i+1) {
+			agg.SubAggregation(name, sub)
+		}
+		return agg
+	}
+
+	agg := elastic.NewDateHistogramAggregation().
+		Field(query.Histogram.Field).
+		Interval(strings.ToLower(string(query.Histogram.Interval))).
+		MinDocCount(1)
+	for name, sub := range a.subAggregations(query, i+1) {
+		agg.SubAggregation(name, sub)
+	}
+	return agg
+}
+
+// collect 展开嵌套的桶, 每个最内层的桶对应一个分组
+func (a *Aggregator) collect(query *entity.AggregateQuery, aggs elastic.Aggregations, i int, keys map[string]interface{}, docCount int64, buckets *[]*entity.AggregateBucket) {
+	keyFields := query.KeyFields()
+
+	if i == len(keyFields) {
+		bucket := &entity.AggregateBucket{
+			Keys:    make(map[string]interface{}, len(keys)),
+			Metrics: make(map[string]float64, len(query.Metrics)),
+		}
+		for k, v := range keys {
+			bucket.Keys[k] = v
+		}
+
+		for j, metric := range query.Metrics {
+			if metric.Func == entity.AggregateFuncCount && metric.Field == "" {
+				bucket.Metrics[metric.Name()] = float64(docCount)
+				continue
+			}
+
+			// 各种单值指标的结果格式相同
+			value, ok := aggs.Sum(metricKey(j))
+			if ok && value.Value != nil {
+				bucket.Metrics[metric.Name()] = *value.Value
+			} else {
+				bucket.Metrics[metric.Name()] = 0
+			}
+		}
+
+		*buckets = append(*buckets, bucket)
+		return
+	}
+
+	if i < len(query.GroupBy) {
+		items, ok := aggs.Terms(groupKey(i))
+		if !ok {
+			return
+		}
+		for _, item := range items.Buckets {
+			keys[keyFields[i]] = item.Key
+			a.collect(query, item.Aggregations, i+1, keys, item.DocCount, buckets)
+		}
+		return
+	}
+
+	items, ok := aggs.DateHistogram(groupKey(i))
+	if !ok {
+		return
+	}
+	for _, item := range items.Buckets {
+		// key 为毫秒时间戳
+		keys[keyFields[i]] = time.Unix(0, int64(item.Key)*int64(time.Millisecond))
+		a.collect(query, item.Aggregations, i+1, keys, item.DocCount, buckets)
+	}
+}
@@ -111,9 +111,12 @@ func (p *ConnectionPaginator) Paginate(c context.Context, query *entity.Connecti
 	rootFilters = append(rootFilters, filter)
 	// rootFilters = append(rootFilters, cursorFilters...)
 
-	if len(query.Fields) > 0 {
-		// TODO 只获取指定字段
+	// 索引没有字段元数据, 不校验字段, 只保证游标字段一定被获取
+	fields, err := query.ProjectFields(nil)
+	if err != nil {
+		return nil, err
 	}
+	applyFields(searchService, fields)
 
 	searchService.Size(limit).Query(elastic.NewBoolQuery().Filter(rootFilters...))
 
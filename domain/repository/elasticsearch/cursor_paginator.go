@@ -63,6 +63,13 @@ func (p *CursorPaginator) Paginate(c context.Context, query *entity.CursorQuery,
 		Size(limit).
 		Query(elastic.NewBoolQuery().Filter(rootFilters...))
 
+	// 索引没有字段元数据, 不校验字段, 只保证游标字段一定被获取
+	fields, err := query.ProjectFields(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	applyFields(searchService, fields)
+
 	b, _ := json.Marshal(query.Orders)
 	log.Printf("orders: %v", string(b))
 	p.applyOrders(searchService, query.Orders, query.Direction == entity.CursorDirectionBefore)
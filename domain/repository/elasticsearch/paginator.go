@@ -2,6 +2,7 @@ package elasticsearch
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/duolacloud/microbase/client/search"
 	"github.com/duolacloud/microbase/domain/entity"
@@ -19,5 +20,59 @@ func NewPaginator(client *elastic.Client) *Paginator {
 }
 
 func (p *Paginator) Paginate(c context.Context, query *entity.PageQuery, index, typ string) (docs []*search.Document, total int64, err error) {
+	filter, err := applyFilter(c, query.Filter)
+	if err != nil {
+		return
+	}
+
+	pageNo := query.PageNo
+	if pageNo < 1 {
+		pageNo = 1
+	}
+
+	pageSize := query.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	searchService := p.client.Search().
+		Index(index).
+		Type(typ).
+		From((pageNo - 1) * pageSize).
+		Size(pageSize).
+		Query(filter)
+
+	for _, order := range query.Orders {
+		searchService.Sort(order.Field, order.Direction != entity.OrderDirectionDesc)
+	}
+
+	fields, err := query.ProjectFields(nil, "id")
+	if err != nil {
+		return
+	}
+	applyFields(searchService, fields)
+
+	result, err := searchService.Do(c)
+	if err != nil {
+		return
+	}
+
+	total = result.TotalHits()
+
+	docs = make([]*search.Document, len(result.Hits.Hits))
+	for i, r := range result.Hits.Hits {
+		doc := &search.Document{
+			Index: index,
+			Type:  typ,
+		}
+
+		err = json.Unmarshal(*r.Source, &doc.Fields)
+		if err != nil {
+			return
+		}
+
+		docs[i] = doc
+	}
+
 	return
 }
@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"context"
+
+	"github.com/olivere/elastic/v6"
+)
+
+// Querier 按过滤条件统计、批量更新和删除文档, 过滤条件和分页查询一致
+type Querier struct {
+	client *elastic.Client
+}
+
+func NewQuerier(client *elastic.Client) *Querier {
+	return &Querier{
+		client,
+	}
+}
+
+func (q *Querier) Count(c context.Context, filter map[string]interface{}, index, typ string) (int64, error) {
+	query, err := applyFilter(c, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.client.Count().
+		Index(index).
+		Type(typ).
+		Query(query).
+		Do(c)
+}
+
+// updateByQueryScript 合并字段, 再把 increments 中的字段加一, 字段不存在时视为 0
+const updateByQueryScript = `ctx._source.putAll(params.fields);
+for (String f : params.increments) {
+	def v = ctx._source[f];
+	ctx._source[f] = (v == null ? 0 : v) + 1;
+}`
+
+// UpdateByQuery 将 fields 合并到所有匹配的文档中, increments 中的字段同时加一
+func (q *Querier) UpdateByQuery(c context.Context, filter map[string]interface{}, fields map[string]interface{}, index, typ string, increments ...string) (int64, error) {
+	query, err := applyFilter(c, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if increments == nil {
+		increments = []string{}
+	}
+	script := elastic.NewScript(updateByQueryScript).
+		Lang("painless").
+		Params(map[string]interface{}{
+			"fields":     fields,
+			"increments": increments,
+		})
+
+	res, err := q.client.UpdateByQuery(index).
+		Type(typ).
+		Query(query).
+		Script(script).
+		ProceedOnVersionConflict().
+		Refresh("true").
+		Do(c)
+	if err != nil {
+		return 0, err
+	}
+	return res.Updated, nil
+}
+
+func (q *Querier) DeleteByQuery(c context.Context, filter map[string]interface{}, index, typ string) (int64, error) {
+	query, err := applyFilter(c, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := q.client.DeleteByQuery(index).
+		Type(typ).
+		Query(query).
+		ProceedOnVersionConflict().
+		Refresh("true").
+		Do(c)
+	if err != nil {
+		return 0, err
+	}
+	return res.Deleted, nil
+}
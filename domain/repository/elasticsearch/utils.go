@@ -2,196 +2,107 @@ package elasticsearch
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/duolacloud/microbase/domain/entity"
-	"github.com/duolacloud/microbase/domain/repository"
-	"github.com/duolacloud/microbase/utils/xtree"
 	"github.com/olivere/elastic/v6"
-	"github.com/thoas/go-funk"
 )
 
+// queryVisitor 把过滤条件编译为 elastic 查询, 索引没有字段元数据, 只校验操作符和值的格式
 type queryVisitor struct {
-	key   string
-	value interface{}
-	query elastic.Query
 }
 
-func (n *queryVisitor) children(current *xtree.Node) error {
-	var hasChildren bool
-
-	if current.Parent == nil {
-		hasChildren = true
-	} else {
-		filterType := entity.FilterType(n.key)
-
-		switch filterType {
-		case entity.FilterType_AND, entity.FilterType_OR:
-			hasChildren = true
-		}
+func (v *queryVisitor) VisitGroup(f *entity.GroupFilter, children []interface{}) (interface{}, error) {
+	queries := make([]elastic.Query, 0, len(children))
+	for _, child := range children {
+		queries = append(queries, child.(elastic.Query))
 	}
 
-	if !hasChildren {
-		return nil
+	query := elastic.NewBoolQuery()
+	if len(queries) == 0 {
+		return query, nil
 	}
 
-	vMap, ok := n.value.(map[string]interface{})
-	if !ok {
-		return errors.New(fmt.Sprintf("child query key format not match"))
-	}
-
-	children := make([]*xtree.Node, 0, len(vMap))
-	for key, child := range vMap {
-		child := &xtree.Node{
-			Visitor: &queryVisitor{
-				key:   key,
-				value: child,
-			},
-			Parent: current,
-		}
-
-		children = append(children, child)
+	switch f.Type {
+	case entity.FilterType_OR:
+		query.Should(queries...).MinimumShouldMatch("1")
+	case entity.FilterType_NOR:
+		query.MustNot(queries...)
+	default:
+		query.Filter(queries...)
 	}
-	current.Children = children
-	return nil
+	return query, nil
 }
 
-func (n *queryVisitor) PreVisit(c context.Context, current *xtree.Node) error {
-	err := n.children(current)
-	if err != nil {
-		return err
-	}
-
-	if current.Parent == nil {
-		n.query = elastic.NewBoolQuery()
-		return nil
-	}
-
-	filterType := entity.FilterType(n.key)
-
-	switch filterType {
-	case entity.FilterType_AND:
-		{
-			n.query = elastic.NewBoolQuery()
-			return nil
+func (v *queryVisitor) VisitField(f *entity.FieldFilter) (interface{}, error) {
+	switch f.Op {
+	case entity.FilterType_EQ:
+		return elastic.NewTermQuery(f.Field, f.Value), nil
+	case entity.FilterType_NE:
+		return elastic.NewBoolQuery().MustNot(elastic.NewTermQuery(f.Field, f.Value)), nil
+	case entity.FilterType_GT:
+		return elastic.NewRangeQuery(f.Field).Gt(f.Value), nil
+	case entity.FilterType_GTE:
+		return elastic.NewRangeQuery(f.Field).Gte(f.Value), nil
+	case entity.FilterType_LT:
+		return elastic.NewRangeQuery(f.Field).Lt(f.Value), nil
+	case entity.FilterType_LTE:
+		return elastic.NewRangeQuery(f.Field).Lte(f.Value), nil
+	case entity.FilterType_LIKE:
+		return elastic.NewWildcardQuery(f.Field, likeToWildcard(f.Value.(string))), nil
+	case entity.FilterType_NOT_LIKE:
+		return elastic.NewBoolQuery().MustNot(elastic.NewWildcardQuery(f.Field, likeToWildcard(f.Value.(string)))), nil
+	case entity.FilterType_MATCH:
+		return elastic.NewMatchQuery(f.Field, f.Value), nil
+	case entity.FilterType_IN:
+		return elastic.NewTermsQuery(f.Field, f.Value.([]interface{})...), nil
+	case entity.FilterType_NOT_IN:
+		return elastic.NewBoolQuery().MustNot(elastic.NewTermsQuery(f.Field, f.Value.([]interface{})...)), nil
+	case entity.FilterType_BETWEEN:
+		values := f.Value.([]interface{})
+		query := elastic.NewRangeQuery(f.Field)
+		if values[0] != nil {
+			query.Gte(values[0])
 		}
-	case entity.FilterType_OR:
-		{
-			n.query = elastic.NewBoolQuery()
-			return nil
+		if values[1] != nil {
+			query.Lte(values[1])
 		}
+		return query, nil
+	case entity.FilterType_IS_NULL:
+		return elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery(f.Field)), nil
+	case entity.FilterType_NOT_NULL:
+		return elastic.NewExistsQuery(f.Field), nil
+	default:
+		return nil, &entity.FilterError{Path: f.Field, Err: entity.ErrUnknownOperator}
 	}
-
-	fieldName := n.key
-
-	vMap, ok := n.value.(map[string]interface{})
-	if !ok {
-		n.query = elastic.NewTermQuery(fieldName, n.value)
-		return nil
-	}
-
-	rangeQuery := elastic.NewRangeQuery(fieldName)
-	isRangeQuery := false
-
-	for vKey, vValue := range vMap {
-		/*
-			switch field.Struct.Type.String() {
-			case "time.Time", "*time.Time":
-				v, err := smarttime.Parse(vValue)
-				if err == nil {
-					vValue = v
-				}
-			}
-		*/
-
-		filterType = entity.FilterType(vKey)
-		switch filterType {
-		case entity.FilterType_EQ:
-			elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_NE:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_GT:
-			rangeQuery.Gt(vValue)
-			isRangeQuery = true
-		case entity.FilterType_GTE:
-			rangeQuery.Gte(vValue)
-			isRangeQuery = true
-		case entity.FilterType_LT:
-			rangeQuery.Lt(vValue)
-			isRangeQuery = true
-		case entity.FilterType_LTE:
-			rangeQuery.Lte(vValue)
-			isRangeQuery = true
-		case entity.FilterType_LIKE:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_MATCH:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_NOT_LIKE:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_IN:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_NOT_IN:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_BETWEEN:
-			values, ok := n.value.([]interface{})
-			if !ok {
-				return repository.ErrFilterValueType
-			}
-
-			if len(values) != 2 {
-				return repository.ErrFilterValueSize
-			}
-			rangeQuery.Lte(values[0]).Gte(values[1])
-			isRangeQuery = true
-		case entity.FilterType_IS_NULL:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		case entity.FilterType_NOT_NULL:
-			n.query = elastic.NewTermQuery(fieldName, vValue)
-		}
-	}
-
-	if isRangeQuery {
-		n.query = rangeQuery
-	}
-
-	return nil
 }
 
-func (n *queryVisitor) PostVisit(c context.Context, current *xtree.Node) error {
-	if current.Parent == nil {
-		subQueries := funk.Map(current.Children, func(child *xtree.Node) elastic.Query {
-			subVisitor := child.Visitor.(*queryVisitor)
-			return subVisitor.query
-		}).([]elastic.Query)
-		boolQuery := n.query.(*elastic.BoolQuery)
-		boolQuery.Filter(subQueries...)
-
-		return nil
-	}
-
-	filterType := entity.FilterType(n.key)
-	for _, child := range current.Children {
-		childVisitor := child.Visitor.(*queryVisitor)
-		query := n.query.(*elastic.BoolQuery)
-
-		switch filterType {
-		case entity.FilterType_EQ,
-			entity.FilterType_GT,
-			entity.FilterType_GTE,
-			entity.FilterType_LT,
-			entity.FilterType_LTE,
-			entity.FilterType_LIKE,
-			entity.FilterType_MATCH,
-			entity.FilterType_IN,
-			entity.FilterType_BETWEEN,
-			entity.FilterType_IS_NULL:
-			query.Must(childVisitor.query)
-		case entity.FilterType_NE, entity.FilterType_NOT_LIKE, entity.FilterType_NOT_IN, entity.FilterType_NOT_NULL:
-			query.MustNot(childVisitor.query)
+// likeToWildcard 把 SQL LIKE 的 % 和 _ 转换为 wildcard 的 * 和 ?
+func likeToWildcard(like string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range like {
+		switch {
+		case escaped:
+			if r == '*' || r == '?' {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteRune('*')
+		case r == '_':
+			b.WriteRune('?')
+		case r == '*' || r == '?':
+			b.WriteString(fmt.Sprintf("\\%c", r))
+		default:
+			b.WriteRune(r)
 		}
 	}
-	return nil
+	return b.String()
 }
 
 func applyFilter(c context.Context, filter map[string]interface{}) (elastic.Query, error) {
@@ -199,16 +110,23 @@ func applyFilter(c context.Context, filter map[string]interface{}) (elastic.Quer
 		return elastic.NewBoolQuery(), nil
 	}
 
-	rootVisitor := &queryVisitor{
-		value: filter,
+	root, err := entity.ParseFilter(filter, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	tree := xtree.NewXTree()
-	if err := tree.Travel(c, &xtree.Node{
-		Visitor: rootVisitor,
-	}); err != nil {
+	query, err := root.Accept(&queryVisitor{})
+	if err != nil {
 		return nil, err
 	}
+	return query.(elastic.Query), nil
+}
+
+// applyFields 通过 _source includes 只获取指定字段, fields 为空时获取全部字段
+func applyFields(searchService *elastic.SearchService, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
 
-	return rootVisitor.query, nil
+	searchService.FetchSourceContext(elastic.NewFetchSourceContext(true).Include(fields...))
 }
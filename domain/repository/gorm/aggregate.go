@@ -0,0 +1,171 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	_gorm "github.com/jinzhu/gorm"
+)
+
+// Aggregate 使用 GROUP BY 分组统计, 时间分桶按数据库方言截断时间
+func (r *BaseRepository) Aggregate(c context.Context, m entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	db, model, err := r.filtered(c, m, query.Filter, query.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	ms := db.NewScope(model).GetModelStruct()
+	if err = query.Validate(filterFields(ms, db)); err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	for _, name := range query.GroupBy {
+		field, _ := FindField(name, ms, db)
+		groups = append(groups, db.Dialect().Quote(field.DBName))
+	}
+
+	if query.Histogram != nil {
+		field, _ := FindField(query.Histogram.Field, ms, db)
+		expr, err := truncateDate(db.Dialect(), db.Dialect().Quote(field.DBName), query.Histogram.Interval)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, expr)
+	}
+
+	selects := append([]string{}, groups...)
+	for _, metric := range query.Metrics {
+		expr := "*"
+		if metric.Field != "" {
+			field, _ := FindField(metric.Field, ms, db)
+			expr = db.Dialect().Quote(field.DBName)
+		}
+		selects = append(selects, fmt.Sprintf("%s(%s) AS %s", metric.Func, expr, db.Dialect().Quote(metric.Name())))
+	}
+
+	db = db.Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+	if query.Size > 0 {
+		db = db.Limit(query.Size)
+	}
+
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keyFields := query.KeyFields()
+	values := make([]interface{}, len(selects))
+	ptrs := make([]interface{}, len(selects))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	buckets := make([]*entity.AggregateBucket, 0)
+	for rows.Next() {
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		bucket := &entity.AggregateBucket{
+			Keys:    make(map[string]interface{}, len(keyFields)),
+			Metrics: make(map[string]float64, len(query.Metrics)),
+		}
+
+		for i, name := range keyFields {
+			bucket.Keys[name] = columnValue(values[i])
+		}
+
+		if query.Histogram != nil {
+			t, err := scanDate(bucket.Keys[query.Histogram.Field])
+			if err != nil {
+				return nil, err
+			}
+			bucket.Keys[query.Histogram.Field] = t
+		}
+
+		for i, metric := range query.Metrics {
+			v, err := columnFloat(values[len(keyFields)+i])
+			if err != nil {
+				return nil, err
+			}
+			bucket.Metrics[metric.Name()] = v
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// truncateDate 截断到分桶起始时间的 SQL 表达式
+func truncateDate(dialect _gorm.Dialect, column string, interval entity.DateInterval) (string, error) {
+	switch dialect.GetName() {
+	case "mysql":
+		switch interval {
+		case entity.DateIntervalMinute:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:%%i:00')", column), nil
+		case entity.DateIntervalHour:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00')", column), nil
+		case entity.DateIntervalWeek:
+			return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY), '%%Y-%%m-%%d 00:00:00')", column, column), nil
+		case entity.DateIntervalMonth:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01 00:00:00')", column), nil
+		case entity.DateIntervalYear:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-01-01 00:00:00')", column), nil
+		default:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d 00:00:00')", column), nil
+		}
+	case "postgres":
+		return fmt.Sprintf("DATE_TRUNC('%s', %s)", strings.ToLower(string(interval)), column), nil
+	default:
+		return "", errors.New(fmt.Sprintf("date histogram is not supported by dialect %s", dialect.GetName()))
+	}
+}
+
+func columnValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func columnFloat(v interface{}) (float64, error) {
+	switch i := v.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return float64(i), nil
+	case float64:
+		return i, nil
+	case float32:
+		return float64(i), nil
+	case []byte:
+		return strconv.ParseFloat(string(i), 64)
+	case string:
+		return strconv.ParseFloat(i, 64)
+	default:
+		return 0, errors.New(fmt.Sprintf("unexpected metric value %v", v))
+	}
+}
+
+// scanDate mysql 的 DATE_FORMAT 返回字符串, 按本地时区解析
+func scanDate(v interface{}) (time.Time, error) {
+	switch i := v.(type) {
+	case time.Time:
+		return i, nil
+	case string:
+		return time.ParseInLocation("2006-01-02 15:04:05", i, time.Local)
+	default:
+		return time.Time{}, errors.New(fmt.Sprintf("unexpected date value %v", v))
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/duolacloud/microbase/audit"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
 	"github.com/duolacloud/microbase/datasource/gorm/opentracing"
 	"github.com/duolacloud/microbase/domain/entity"
 	"github.com/duolacloud/microbase/domain/repository"
-	breflect "github.com/duolacloud/microbase/reflect"
 	_gorm "github.com/jinzhu/gorm"
 )
 
@@ -25,7 +26,8 @@ func (r *BaseRepository) DB(c context.Context) (*_gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return db.(*_gorm.DB), nil
+	// 经过仓储的写操作由 datasource/gorm 注册的钩子审计和写入事件
+	return dsgorm.SetContext(c, db.(*_gorm.DB)), nil
 }
 
 func (r *BaseRepository) Create(c context.Context, m entity.Entity) error {
@@ -38,6 +40,13 @@ func (r *BaseRepository) Create(c context.Context, m entity.Entity) error {
 	scope := db.NewScope(m)
 	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
 
+	if _, _, err = versionColumn(scope.GetModelStruct(), m); err != nil {
+		return err
+	}
+	if err = repository.InitVersion(m); err != nil {
+		return err
+	}
+
 	return db.Table(table).Create(m).Error
 }
 
@@ -51,6 +60,15 @@ func (r *BaseRepository) Upsert(c context.Context, m entity.Entity) (*repository
 	scope := db.NewScope(m)
 	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
 
+	vf, column, err := versionColumn(scope.GetModelStruct(), m)
+	if err != nil {
+		return nil, err
+	}
+	db = dsgorm.SetAuditOperation(db, audit.OperationUpsert)
+	if vf != nil {
+		return upsertVersion(db.Table(table), m, vf, column)
+	}
+
 	result := db.Table(table).Save(m)
 	if result.Error != nil {
 		return nil, result.Error
@@ -78,7 +96,20 @@ func (r *BaseRepository) Update(c context.Context, m entity.Entity, data interfa
 
 	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
 
-	return db.Table(table).Where(m.Unique()).Update(data).Error
+	vf, column, err := versionColumn(scope.GetModelStruct(), m)
+	if err != nil {
+		return err
+	}
+	if vf != nil {
+		version := vf.Get(m)
+		if err = updateVersion(db.Table(table), m, column, version, updateMap(db, data)); err != nil {
+			return err
+		}
+		vf.Set(m, version+1)
+		return nil
+	}
+
+	return dsgorm.SetEntity(db.Table(table), m).Where(m.Unique()).Update(data).Error
 }
 
 func (r *BaseRepository) Get(c context.Context, m entity.Entity) error {
@@ -90,8 +121,9 @@ func (r *BaseRepository) Get(c context.Context, m entity.Entity) error {
 	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
 
 	db = opentracing.SetSpanToGorm(c, db)
+	db = applySoftDelete(db.Table(table), scope.GetModelStruct(), false)
 
-	return db.Table(table).Where(m.Unique()).Take(m).Error
+	return db.Where(m.Unique()).Take(m).Error
 }
 
 func (r *BaseRepository) Delete(c context.Context, m entity.Entity) error {
@@ -102,17 +134,8 @@ func (r *BaseRepository) Delete(c context.Context, m entity.Entity) error {
 	scope := db.NewScope(m)
 	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
 
-	// 主键保护，如果 m 什么都没设置，这里将会删除表的所有记录
-	ms := db.NewScope(m).GetModelStruct()
-	for _, pf := range ms.PrimaryFields {
-		value, err := breflect.GetStructField(m, pf.Name)
-		if err != nil {
-			return err
-		}
-
-		if breflect.IsBlank(value) {
-			return errors.New(fmt.Sprintf("primary key %s must set for delete", pf.Name))
-		}
+	if err = checkPrimaryKey(scope.GetModelStruct(), m, "delete"); err != nil {
+		return err
 	}
 
 	return db.Table(table).Delete(m).Error
@@ -3,6 +3,7 @@ package gorm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/duolacloud/microbase/domain/entity"
 	"github.com/duolacloud/microbase/domain/repository"
 	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/duolacloud/microbase/outbox"
+	outboxgorm "github.com/duolacloud/microbase/outbox/gorm"
 	_gorm "github.com/jinzhu/gorm"
 	"github.com/micro/go-micro/v2/config"
 	"github.com/micro/go-micro/v2/config/source/memory"
@@ -69,6 +72,9 @@ type EntityMap struct {
 func (EntityMap) GetEntities() []interface{} {
 	return []interface{}{
 		User{},
+		Comment{},
+		Account{},
+		Order{},
 	}
 }
 
@@ -445,3 +451,484 @@ func TestPape(t *testing.T) {
 	}
 
 }
+
+func TestRunInTx(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+
+	userRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+	txManager := gorm.NewTxManager(tenancy)
+
+	committed := false
+	user1 := &User{Name: "张飞", Age: 30}
+	user2 := &User{Name: "刘备", Age: 35}
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		gorm.AfterCommit(ctx, func() { committed = true })
+
+		if err := userRepo.Create(ctx, user1); err != nil {
+			return err
+		}
+
+		// savepoint 回滚不影响外层事务
+		rolledBack := false
+		err := txManager.RunInTx(ctx, func(ctx context.Context) error {
+			gorm.AfterRollback(ctx, func() { rolledBack = true })
+			if err := userRepo.Create(ctx, user2); err != nil {
+				return err
+			}
+			return errors.New("rollback savepoint")
+		})
+		assert.Error(t, err)
+		assert.True(t, rolledBack)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, committed)
+
+	assert.NoError(t, userRepo.Get(ctx, &User{ID: user1.ID}))
+	assert.Error(t, userRepo.Get(ctx, &User{ID: user2.ID}))
+
+	// 外层回滚时所有写入都被撤销
+	user3 := &User{Name: "曹操", Age: 40}
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := userRepo.Create(ctx, user3); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+	assert.Error(t, userRepo.Get(ctx, &User{ID: user3.ID}))
+}
+
+func TestNestedFilter(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+
+	userRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+
+	// 每次运行使用不同的名字前缀, 不受已有数据影响
+	prefix := uuid.NewV4().String()[:8]
+	for i, age := range []int{10, 20, 30, 40} {
+		user := &User{Name: fmt.Sprintf("%s-%d", prefix, i), Age: age}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	byPrefix := map[string]interface{}{"LIKE": prefix + "-%"}
+	cases := []struct {
+		filter map[string]interface{}
+		total  int64
+	}{
+		{
+			filter: map[string]interface{}{
+				"name": byPrefix,
+				"OR": []interface{}{
+					map[string]interface{}{"age": 10},
+					map[string]interface{}{"age": 40},
+				},
+			},
+			total: 2,
+		},
+		{
+			// age < 15 OR (age > 25 AND age < 35)
+			filter: map[string]interface{}{
+				"name": byPrefix,
+				"OR": []interface{}{
+					map[string]interface{}{"age": map[string]interface{}{"LT": 15}},
+					map[string]interface{}{
+						"AND": []interface{}{
+							map[string]interface{}{"age": map[string]interface{}{"GT": 25}},
+							map[string]interface{}{"age": map[string]interface{}{"LT": 35}},
+						},
+					},
+				},
+			},
+			total: 2,
+		},
+		{
+			filter: map[string]interface{}{
+				"name": byPrefix,
+				"NOR": []interface{}{
+					map[string]interface{}{"age": 10},
+					map[string]interface{}{"age": map[string]interface{}{"GTE": 20, "LTE": 30}},
+				},
+			},
+			total: 1,
+		},
+		{
+			filter: map[string]interface{}{
+				"AND": []interface{}{
+					map[string]interface{}{"name": byPrefix},
+					map[string]interface{}{
+						"NOR": []interface{}{
+							map[string]interface{}{
+								"OR": []interface{}{
+									map[string]interface{}{"age": 10},
+									map[string]interface{}{"age": 20},
+								},
+							},
+						},
+					},
+				},
+			},
+			total: 2,
+		},
+	}
+
+	for _, c := range cases {
+		items := make([]*User, 0)
+		total, err := userRepo.Page(ctx, &User{}, &entity.PageQuery{
+			Filter:   c.filter,
+			PageNo:   1,
+			PageSize: 10,
+		}, &items)
+		assert.NoError(t, err)
+		assert.Equal(t, c.total, total)
+		assert.Equal(t, int(c.total), len(items))
+
+		items = make([]*User, 0)
+		extra, err := userRepo.List(ctx, &entity.CursorQuery{
+			NeedTotal: true,
+			Filter:    c.filter,
+			Orders:    []*entity.Order{{Field: "name"}},
+			Size:      10,
+		}, &User{}, &items)
+		assert.NoError(t, err)
+		assert.Equal(t, c.total, extra.Total)
+		assert.Equal(t, int(c.total), len(items))
+
+		first := 10
+		conn, err := userRepo.Connection(ctx, &entity.ConnectionQuery{
+			NeedTotal: true,
+			Filter:    c.filter,
+			Orders:    []*entity.Order{{Field: "name"}},
+			First:     &first,
+		}, &User{})
+		assert.NoError(t, err)
+		assert.Equal(t, c.total, conn.Total)
+		assert.Equal(t, int(c.total), len(conn.Edges))
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+
+	userRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+
+	prefix := uuid.NewV4().String()[:8]
+	now := time.Now()
+	for i, age := range []int{10, 20, 20, 40} {
+		user := &User{Name: fmt.Sprintf("%s-%d", prefix, i), Age: age, CreateTime: now}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buckets, err := userRepo.Aggregate(ctx, &User{}, &entity.AggregateQuery{
+		Filter:  map[string]interface{}{"name": map[string]interface{}{"LIKE": prefix + "-%"}},
+		GroupBy: []string{"age"},
+		Metrics: []*entity.Metric{
+			{Func: entity.AggregateFuncCount},
+			{Func: entity.AggregateFuncSum, Field: "age"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, buckets, 3)
+	assert.Equal(t, float64(2), buckets[1].Metrics["count"])
+	assert.Equal(t, float64(40), buckets[1].Metrics["sum_age"])
+
+	buckets, err = userRepo.Aggregate(ctx, &User{}, &entity.AggregateQuery{
+		Filter:    map[string]interface{}{"name": map[string]interface{}{"LIKE": prefix + "-%"}},
+		Histogram: &entity.DateHistogram{Field: "ctime", Interval: entity.DateIntervalDay},
+		Metrics:   []*entity.Metric{{Func: entity.AggregateFuncMax, Field: "age", Alias: "oldest"}},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, buckets)
+	assert.Equal(t, float64(40), buckets[len(buckets)-1].Metrics["oldest"])
+}
+
+func TestSoftDelete(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+
+	userRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+
+	user := &User{Name: uuid.NewV4().String()[:8], Age: 30}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	filter := map[string]interface{}{"name": user.Name}
+
+	// 删除后默认查询不到
+	assert.NoError(t, userRepo.Delete(ctx, &User{ID: user.ID}))
+	assert.Error(t, userRepo.Get(ctx, &User{ID: user.ID}))
+	assert.Equal(t, repository.ErrNotFound, userRepo.FindOne(ctx, &User{}, filter))
+
+	var users []*User
+	_, err = userRepo.Page(ctx, &User{}, &entity.PageQuery{Filter: filter, PageNo: 1, PageSize: 10}, &users)
+	assert.NoError(t, err)
+	assert.Len(t, users, 0)
+
+	_, err = userRepo.Page(ctx, &User{}, &entity.PageQuery{Filter: filter, PageNo: 1, PageSize: 10, IncludeDeleted: true}, &users)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	// 恢复后可以查询到, 再次恢复返回 ErrNotFound
+	assert.NoError(t, userRepo.Restore(ctx, &User{ID: user.ID}))
+	assert.NoError(t, userRepo.Get(ctx, &User{ID: user.ID}))
+	assert.Equal(t, repository.ErrNotFound, userRepo.Restore(ctx, &User{ID: user.ID}))
+
+	assert.NoError(t, userRepo.HardDelete(ctx, &User{ID: user.ID}))
+	_, err = userRepo.Page(ctx, &User{}, &entity.PageQuery{Filter: filter, PageNo: 1, PageSize: 10, IncludeDeleted: true}, &users)
+	assert.NoError(t, err)
+	assert.Len(t, users, 0)
+}
+
+// Comment 只有删除标记, 没有删除时间
+type Comment struct {
+	ID      string `json:"id" gorm:"primary_key"`
+	Content string `json:"content"`
+	Deleted bool   `json:"deleted"`
+}
+
+func (c *Comment) Unique() interface{} {
+	return map[string]interface{}{
+		"id": c.ID,
+	}
+}
+
+func TestBatchCreatePartial(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+	commentRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+
+	existing := &Comment{ID: uuid.NewV4().String(), Content: "已存在"}
+	assert.NoError(t, commentRepo.Create(ctx, existing))
+
+	// 主键冲突的行失败, 同一批的其他行正常写入
+	comments := []entity.Entity{
+		&Comment{ID: uuid.NewV4().String(), Content: "a"},
+		&Comment{ID: existing.ID, Content: "冲突"},
+		&Comment{ID: uuid.NewV4().String(), Content: "b"},
+	}
+	results, err := commentRepo.BatchCreate(ctx, comments)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Error)
+	assert.Error(t, results[1].Error)
+	assert.NoError(t, results[2].Error)
+
+	assert.NoError(t, commentRepo.Get(ctx, &Comment{ID: comments[0].(*Comment).ID}))
+	assert.NoError(t, commentRepo.Get(ctx, &Comment{ID: comments[2].(*Comment).ID}))
+
+	got := &Comment{ID: existing.ID}
+	assert.NoError(t, commentRepo.Get(ctx, got))
+	assert.Equal(t, "已存在", got.Content)
+}
+
+type Order struct {
+	ID            string `json:"id" gorm:"primary_key"`
+	Amount        int    `json:"amount"`
+	outbox.Events `json:"-" gorm:"-"`
+}
+
+func (o *Order) Unique() interface{} {
+	return map[string]interface{}{
+		"id": o.ID,
+	}
+}
+
+func TestBatchDeleteEvents(t *testing.T) {
+	config, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`{
+		"db": {
+			"driver": "mysql",
+			"connection_string": "root:debezium@tcp(localhost:3306)/test?charset=utf8mb4&parseTime=True&loc=Local",
+			"outbox": true
+		}
+	}`)
+	if err = config.Load(memory.NewSource(memory.WithJSON(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+	orderRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+
+	var orders []entity.Entity
+	for i := 0; i < 3; i++ {
+		order := &Order{ID: uuid.NewV4().String(), Amount: i}
+		assert.NoError(t, orderRepo.Create(ctx, order))
+		order.Record("order.deleted", map[string]interface{}{"id": order.ID})
+		orders = append(orders, order)
+	}
+
+	results, err := orderRepo.BatchDelete(ctx, orders)
+	assert.NoError(t, err)
+	for _, result := range results {
+		assert.NoError(t, result.Error)
+	}
+
+	// 删除的每个实体的事件都写入 outbox
+	messages, err := outboxgorm.NewStore(tenancy).Pending(ctx, 1000)
+	assert.NoError(t, err)
+
+	deleted := make(map[string]bool)
+	for _, message := range messages {
+		if message.Topic == "order.deleted" {
+			deleted[message.AggregateID] = true
+		}
+	}
+	for _, order := range orders {
+		assert.True(t, deleted[order.(*Order).ID])
+	}
+}
+
+func TestSoftDeleteFlag(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+
+	commentRepo := NewBaseRepository(repository.NewMultitenancyProvider(tenancy))
+
+	comment := &Comment{ID: uuid.NewV4().String(), Content: "hello"}
+	assert.NoError(t, commentRepo.Create(ctx, comment))
+
+	// 删除只设置 deleted, 记录仍然在表中
+	assert.NoError(t, commentRepo.Delete(ctx, &Comment{ID: comment.ID}))
+	assert.Error(t, commentRepo.Get(ctx, &Comment{ID: comment.ID}))
+
+	filter := map[string]interface{}{"id": comment.ID}
+	var comments []*Comment
+	_, err = commentRepo.Page(ctx, &Comment{}, &entity.PageQuery{Filter: filter, PageNo: 1, PageSize: 10, IncludeDeleted: true}, &comments)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, true, comments[0].Deleted)
+
+	assert.NoError(t, commentRepo.Restore(ctx, &Comment{ID: comment.ID}))
+	assert.NoError(t, commentRepo.Get(ctx, &Comment{ID: comment.ID}))
+
+	assert.NoError(t, commentRepo.HardDelete(ctx, &Comment{ID: comment.ID}))
+	_, err = commentRepo.Page(ctx, &Comment{}, &entity.PageQuery{Filter: filter, PageNo: 1, PageSize: 10, IncludeDeleted: true}, &comments)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 0)
+}
+
+type Account struct {
+	ID      string `json:"id" gorm:"primary_key"`
+	Balance int    `json:"balance"`
+	Version int64  `json:"version" repo:"version"`
+}
+
+func (a *Account) Unique() interface{} {
+	return map[string]interface{}{
+		"id": a.ID,
+	}
+}
+
+func TestVersion(t *testing.T) {
+	config, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := getTenancy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant-id", "tenantId1")
+
+	provider := repository.NewMultitenancyProvider(tenancy)
+	accountRepo := NewBaseRepository(provider)
+
+	account := &Account{ID: uuid.NewV4().String(), Balance: 100}
+	assert.NoError(t, accountRepo.Create(ctx, account))
+	assert.Equal(t, int64(1), account.Version)
+
+	// 两个请求读取同一个版本, 后写入的返回 ErrConflict
+	stale := *account
+	assert.NoError(t, accountRepo.Update(ctx, account, map[string]interface{}{"balance": 80}))
+	assert.Equal(t, int64(2), account.Version)
+	assert.Equal(t, repository.ErrConflict, accountRepo.Update(ctx, &stale, map[string]interface{}{"balance": 50}))
+
+	account.Balance = 60
+	_, err = accountRepo.Upsert(ctx, account)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), account.Version)
+
+	_, err = accountRepo.Upsert(ctx, &stale)
+	assert.Equal(t, repository.ErrConflict, err)
+
+	// 批量写入同样按版本号比较
+	results, err := accountRepo.BatchUpsert(ctx, []entity.Entity{&stale})
+	assert.NoError(t, err)
+	assert.Equal(t, repository.ErrConflict, results[0].Error)
+
+	// UpdateMany 把版本号加一, 之前读取的版本失效
+	_, err = accountRepo.UpdateMany(ctx, &Account{}, map[string]interface{}{"id": account.ID}, map[string]interface{}{"balance": 40})
+	assert.NoError(t, err)
+	assert.Equal(t, repository.ErrConflict, accountRepo.Update(ctx, account, map[string]interface{}{"balance": 30}))
+}
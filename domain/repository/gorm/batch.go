@@ -0,0 +1,380 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/duolacloud/microbase/audit"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/datasource/gorm/opentracing"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/logger"
+	_gorm "github.com/jinzhu/gorm"
+)
+
+// BatchSize 批量写入时单条 SQL 最多包含的行数
+var BatchSize = 500
+
+// batchRow 待写入的一行, 列相同的行合并为一条 INSERT
+type batchRow struct {
+	index    int
+	scope    *_gorm.Scope
+	creating bool
+	columns  []string
+	values   []interface{}
+}
+
+type batchGroup struct {
+	table   string
+	columns []string
+	primary map[string]bool
+	rows    []*batchRow
+}
+
+// BatchCreate 使用多行 INSERT 写入, 自增主键不会回填
+func (r *BaseRepository) BatchCreate(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	return r.batchInsert(c, ms, false)
+}
+
+// BatchUpsert 使用 INSERT ... ON DUPLICATE KEY UPDATE 写入, 已存在的记录保留 ctime,
+// 有版本字段的实体逐条通过 Upsert 按版本号写入
+func (r *BaseRepository) BatchUpsert(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+	rest, positions := repository.SplitVersioned(ms, results, func(m entity.Entity) error {
+		_, err := r.Upsert(c, m)
+		return err
+	})
+
+	restResults, err := r.batchInsert(c, rest, true)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range positions {
+		results[i] = restResults[j]
+	}
+	return results, nil
+}
+
+func (r *BaseRepository) batchInsert(c context.Context, ms []entity.Entity, upsert bool) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+	if len(ms) == 0 {
+		return results, nil
+	}
+
+	db, err := r.DB(c)
+	if err != nil {
+		return nil, err
+	}
+	db = opentracing.SetSpanToGorm(c, db)
+
+	now := time.Now()
+	var groups []*batchGroup
+	index := make(map[string]*batchGroup)
+	for i, m := range ms {
+		if !upsert {
+			if err := repository.InitVersion(m); err != nil {
+				results[i].Error = err
+				continue
+			}
+		}
+
+		scope := db.NewScope(m)
+
+		// 和 Save 一样, 主键为空时按新建处理
+		creating := !upsert || scope.PrimaryKeyZero()
+		scope.CallMethod("BeforeSave")
+		if creating {
+			scope.CallMethod("BeforeCreate")
+		} else {
+			scope.CallMethod("BeforeUpdate")
+		}
+		if scope.HasError() {
+			results[i].Error = scope.DB().Error
+			continue
+		}
+
+		row := newBatchRow(i, scope, creating, now)
+		table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
+		key := fmt.Sprintf("%s:%s", table, strings.Join(row.columns, ","))
+
+		group, ok := index[key]
+		if !ok {
+			group = &batchGroup{
+				table:   table,
+				columns: row.columns,
+				primary: make(map[string]bool),
+			}
+			for _, field := range scope.PrimaryFields() {
+				group.primary[field.DBName] = true
+			}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.rows = append(group.rows, row)
+	}
+
+	for _, group := range groups {
+		for start := 0; start < len(group.rows); start += BatchSize {
+			end := start + BatchSize
+			if end > len(group.rows) {
+				end = len(group.rows)
+			}
+			rows := group.rows[start:end]
+
+			err := chunkTx(c, db, func(tx *_gorm.DB) error {
+				return group.insert(c, tx, rows, upsert)
+			})
+			if err == nil {
+				continue
+			}
+			if len(rows) == 1 {
+				results[rows[0].index].Error = err
+				continue
+			}
+
+			// 整批已回滚, 逐行重试, 只有写入失败的行返回错误
+			for _, row := range rows {
+				row := row
+				results[row.index].Error = chunkTx(c, db, func(tx *_gorm.DB) error {
+					return group.insert(c, tx, []*batchRow{row}, upsert)
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// insert 写入一批行, 批量写入不经过 gorm 的钩子, 逐条记录审计和写入事件
+func (g *batchGroup) insert(c context.Context, db *_gorm.DB, rows []*batchRow, upsert bool) error {
+	sql, vars := g.insertSQL(rows, upsert)
+	if err := db.Exec(sql, vars...).Error; err != nil {
+		return err
+	}
+
+	op := audit.OperationCreate
+	if upsert {
+		op = audit.OperationUpsert
+	}
+	for _, row := range rows {
+		if row.creating {
+			row.scope.CallMethod("AfterCreate")
+		} else {
+			row.scope.CallMethod("AfterUpdate")
+		}
+		row.scope.CallMethod("AfterSave")
+		if row.scope.HasError() {
+			return row.scope.DB().Error
+		}
+
+		if err := dsgorm.AuditCreate(c, db, row.scope.Value, op); err != nil {
+			return err
+		}
+		if err := dsgorm.SaveEvents(c, db, row.scope.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkTx 一批写入和它的审计、事件在同一个事务中提交, 任何一步失败时整批回滚;
+// ctx 中已有 RunInTx 开启的事务时使用 savepoint, 只回滚这一批
+func chunkTx(c context.Context, db *_gorm.DB, fn func(tx *_gorm.DB) error) error {
+	if dsgorm.InTx(c) {
+		if err := db.Exec("SAVEPOINT batch_chunk").Error; err != nil {
+			return err
+		}
+		if err := fn(db); err != nil {
+			if err := db.Exec("ROLLBACK TO SAVEPOINT batch_chunk").Error; err != nil {
+				logger.Errorf("gorm rollback to savepoint batch_chunk error: %v", err)
+			}
+			return err
+		}
+		return db.Exec("RELEASE SAVEPOINT batch_chunk").Error
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := fn(tx); err != nil {
+		if err := tx.Rollback().Error; err != nil {
+			logger.Errorf("gorm rollback error: %v", err)
+		}
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// newBatchRow 按 gorm 新建记录的规则选择列: 空值且有默认值的列、空的主键不写入
+func newBatchRow(index int, scope *_gorm.Scope, creating bool, now time.Time) *batchRow {
+	row := &batchRow{
+		index:    index,
+		scope:    scope,
+		creating: creating,
+	}
+
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored {
+			continue
+		}
+
+		switch field.DBName {
+		case "ctime":
+			if field.IsBlank {
+				field.Set(now)
+			}
+		case "utime":
+			if field.IsBlank || !creating {
+				field.Set(now)
+			}
+		}
+
+		if field.IsBlank && (field.HasDefaultValue || field.IsPrimaryKey) {
+			continue
+		}
+
+		row.columns = append(row.columns, field.DBName)
+		row.values = append(row.values, field.Field.Interface())
+	}
+
+	return row
+}
+
+func (g *batchGroup) insertSQL(rows []*batchRow, upsert bool) (string, []interface{}) {
+	scope := rows[0].scope
+
+	columns := make([]string, len(g.columns))
+	for i, column := range g.columns {
+		columns[i] = scope.Quote(column)
+	}
+
+	placeholder := fmt.Sprintf("(%s)", strings.TrimSuffix(strings.Repeat("?,", len(columns)), ","))
+	placeholders := make([]string, len(rows))
+	vars := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		placeholders[i] = placeholder
+		vars = append(vars, row.values...)
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", scope.Quote(g.table), strings.Join(columns, ","), strings.Join(placeholders, ","))
+	if !upsert {
+		return sql, vars
+	}
+
+	var updates []string
+	for _, column := range g.columns {
+		if g.primary[column] || column == "ctime" {
+			continue
+		}
+		updates = append(updates, fmt.Sprintf("%s=VALUES(%s)", scope.Quote(column), scope.Quote(column)))
+	}
+	if len(updates) == 0 {
+		// 只有主键时保持原记录不变
+		updates = append(updates, fmt.Sprintf("%s=%s", columns[0], columns[0]))
+	}
+
+	return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s", sql, strings.Join(updates, ",")), vars
+}
+
+// BatchDelete 单主键的实体按 IN 条件批量删除, 同样会经过软删除的钩子
+func (r *BaseRepository) BatchDelete(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+	if len(ms) == 0 {
+		return results, nil
+	}
+
+	db, err := r.DB(c)
+	if err != nil {
+		return nil, err
+	}
+	db = opentracing.SetSpanToGorm(c, db)
+
+	type deleteGroup struct {
+		table   string
+		model   reflect.Type
+		primary string
+		indexes []int
+		ids     []interface{}
+	}
+
+	var groups []*deleteGroup
+	index := make(map[string]*deleteGroup)
+	for i, m := range ms {
+		scope := db.NewScope(m)
+		table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
+
+		// 主键保护，如果 m 什么都没设置，这里将会删除表的所有记录
+		primaryFields := scope.PrimaryFields()
+		if len(primaryFields) == 0 {
+			results[i].Error = errors.New(fmt.Sprintf("no primary key found for %s", table))
+			continue
+		}
+		for _, field := range primaryFields {
+			if field.IsBlank {
+				results[i].Error = errors.New(fmt.Sprintf("primary key %s must set for delete", field.Name))
+				break
+			}
+		}
+		if results[i].Error != nil {
+			continue
+		}
+
+		if len(primaryFields) > 1 {
+			// 联合主键逐条删除
+			results[i].Error = db.Table(table).Delete(m).Error
+			continue
+		}
+
+		group, ok := index[table]
+		if !ok {
+			group = &deleteGroup{
+				table:   table,
+				model:   reflect.TypeOf(m),
+				primary: primaryFields[0].DBName,
+			}
+			index[table] = group
+			groups = append(groups, group)
+		}
+		group.indexes = append(group.indexes, i)
+		group.ids = append(group.ids, primaryFields[0].Field.Interface())
+	}
+
+	for _, group := range groups {
+		for start := 0; start < len(group.ids); start += BatchSize {
+			end := start + BatchSize
+			if end > len(group.ids) {
+				end = len(group.ids)
+			}
+
+			// 使用零值实体, 删除条件只来自 IN, 软删除和删除钩子中的审计在同一个事务中;
+			// 零值实体上没有事件, 逐条写入这一批实体上的事件
+			model := reflect.New(group.model.Elem()).Interface()
+			query := fmt.Sprintf("%s IN (?)", db.NewScope(model).Quote(group.primary))
+			indexes := group.indexes[start:end]
+			err := chunkTx(c, db, func(tx *_gorm.DB) error {
+				if err := tx.Table(group.table).Where(query, group.ids[start:end]).Delete(model).Error; err != nil {
+					return err
+				}
+				for _, i := range indexes {
+					if err := dsgorm.SaveEvents(c, tx, ms[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				for _, i := range indexes {
+					results[i].Error = err
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
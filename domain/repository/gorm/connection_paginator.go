@@ -66,7 +66,7 @@ func (p *connectionPaginator) Paginate(c context.Context, query *entity.Connecti
 
 	p.ensureOrders(modelStruct, query)
 
-	dbHandler := db.Table(table)
+	dbHandler := applySoftDelete(db.Table(table), modelStruct, query.IncludeDeleted)
 	dbHandler, err = applyFilter(dbHandler, modelStruct, query.Filter)
 	if err != nil {
 		return nil, err
@@ -120,8 +120,14 @@ func (p *connectionPaginator) Paginate(c context.Context, query *entity.Connecti
 
 	dbHandler = dbHandler.Limit(limit)
 
-	if len(query.Fields) > 0 {
-		dbHandler = dbHandler.Select(query.Fields)
+	fields, err := query.ProjectFields(filterFields(modelStruct, dbHandler), primaryFields(modelStruct)...)
+	if err != nil {
+		return nil, err
+	}
+
+	dbHandler, err = applyFields(dbHandler, modelStruct, fields)
+	if err != nil {
+		return nil, err
 	}
 
 	resultPtr := breflect.MakeSlicePtr(p.entity, 0, limit)
@@ -64,7 +64,7 @@ func (p *cursorPaginator) Paginate(c context.Context, query *entity.CursorQuery,
 
 	extra = &entity.CursorExtra{}
 
-	dbHandler := db.Table(table)
+	dbHandler := applySoftDelete(db.Table(table), p.modelStruct, query.IncludeDeleted)
 	dbHandler, err = applyFilter(dbHandler, p.modelStruct, query.Filter)
 	if err != nil {
 		return nil, err
@@ -89,8 +89,14 @@ func (p *cursorPaginator) Paginate(c context.Context, query *entity.CursorQuery,
 	limit := query.Size + 1
 	dbHandler = dbHandler.Limit(limit)
 
-	if len(query.Fields) > 0 {
-		dbHandler = dbHandler.Select(query.Fields)
+	fields, err := query.ProjectFields(filterFields(p.modelStruct, dbHandler), primaryFields(p.modelStruct)...)
+	if err != nil {
+		return nil, err
+	}
+
+	dbHandler, err = applyFields(dbHandler, p.modelStruct, fields)
+	if err != nil {
+		return nil, err
 	}
 
 	if err = dbHandler.Find(resultPtr).Error; err != nil {
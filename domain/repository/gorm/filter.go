@@ -0,0 +1,107 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/duolacloud/microbase/datasource/gorm/opentracing"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	_gorm "github.com/jinzhu/gorm"
+)
+
+// filtered 返回已经应用过滤条件的 db, 默认排除软删除的记录, model 为 m 类型的零值, 用于经过 gorm 的钩子
+func (r *BaseRepository) filtered(c context.Context, m entity.Entity, filter map[string]interface{}, includeDeleted bool) (db *_gorm.DB, model interface{}, err error) {
+	db, err = r.DB(c)
+	if err != nil {
+		return
+	}
+	db = opentracing.SetSpanToGorm(c, db)
+
+	model = reflect.New(reflect.TypeOf(m).Elem()).Interface()
+	scope := db.NewScope(model)
+	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
+
+	db = applySoftDelete(db.Table(table), scope.GetModelStruct(), includeDeleted)
+	db, err = applyFilter(db, scope.GetModelStruct(), filter)
+	return
+}
+
+func (r *BaseRepository) Count(c context.Context, m entity.Entity, filter map[string]interface{}) (total int64, err error) {
+	db, _, err := r.filtered(c, m, filter, false)
+	if err != nil {
+		return
+	}
+
+	err = db.Count(&total).Error
+	return
+}
+
+func (r *BaseRepository) Exists(c context.Context, m entity.Entity, filter map[string]interface{}) (bool, error) {
+	db, _, err := r.filtered(c, m, filter, false)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := db.Select("1").Limit(1).Rows()
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	return rows.Next(), rows.Err()
+}
+
+func (r *BaseRepository) FindOne(c context.Context, m entity.Entity, filter map[string]interface{}) error {
+	db, _, err := r.filtered(c, m, filter, false)
+	if err != nil {
+		return err
+	}
+
+	err = db.Take(m).Error
+	if _gorm.IsRecordNotFoundError(err) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+func (r *BaseRepository) UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+
+	db, model, err := r.filtered(c, m, filter, false)
+	if err != nil {
+		return 0, err
+	}
+
+	// 有版本字段时同时把版本号加一, 持有旧版本号的写入会返回 ErrConflict
+	_, column, err := versionColumn(db.NewScope(model).GetModelStruct(), m)
+	if err != nil {
+		return 0, err
+	}
+	if column != nil {
+		values := updateMap(db, change)
+		values[column.DBName] = _gorm.Expr(fmt.Sprintf("%s + 1", db.Dialect().Quote(column.DBName)))
+		change = values
+	}
+
+	result := db.Model(model).Updates(change)
+	return result.RowsAffected, result.Error
+}
+
+func (r *BaseRepository) DeleteMany(c context.Context, m entity.Entity, filter map[string]interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+
+	db, model, err := r.filtered(c, m, filter, false)
+	if err != nil {
+		return 0, err
+	}
+
+	// 零值的 model 不会带上主键条件, 删除同样经过软删除的钩子
+	result := db.Delete(model)
+	return result.RowsAffected, result.Error
+}
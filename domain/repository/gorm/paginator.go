@@ -42,7 +42,7 @@ func (p *paginator) Paginate(c context.Context, query *entity.PageQuery, resultP
 		p.modelStruct = db.NewScope(p.entity).GetModelStruct()
 	}
 
-	dbHandler := db.Table(table)
+	dbHandler := applySoftDelete(db.Table(table), p.modelStruct, query.IncludeDeleted)
 	dbHandler, err = applyFilter(dbHandler, p.modelStruct, query.Filter)
 	if err != nil {
 		return
@@ -53,6 +53,16 @@ func (p *paginator) Paginate(c context.Context, query *entity.PageQuery, resultP
 		return
 	}
 
+	fields, err := query.ProjectFields(filterFields(p.modelStruct, dbHandler), primaryFields(p.modelStruct)...)
+	if err != nil {
+		return
+	}
+
+	dbHandler, err = applyFields(dbHandler, p.modelStruct, fields)
+	if err != nil {
+		return
+	}
+
 	total, err = pageQuery(dbHandler, query.PageNo, query.PageSize, resultPtr)
 	return
 }
@@ -0,0 +1,121 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duolacloud/microbase/audit"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/datasource/gorm/opentracing"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	breflect "github.com/duolacloud/microbase/reflect"
+	_gorm "github.com/jinzhu/gorm"
+)
+
+// softDeleteFields 软删除的字段, 和删除钩子一样按列名查找
+func softDeleteFields(ms *_gorm.ModelStruct) (deleteTime *_gorm.StructField, deleted *_gorm.StructField) {
+	for _, field := range ms.StructFields {
+		switch field.DBName {
+		case repository.SoftDeleteTimeField:
+			deleteTime = field
+		case repository.SoftDeletedField:
+			deleted = field
+		}
+	}
+	return
+}
+
+// applySoftDelete 排除已经软删除的记录, 删除钩子同时设置两个字段时使用 dtime 判断
+func applySoftDelete(db *_gorm.DB, ms *_gorm.ModelStruct, includeDeleted bool) *_gorm.DB {
+	if includeDeleted {
+		return db
+	}
+
+	deleteTime, deleted := softDeleteFields(ms)
+	if deleteTime != nil {
+		return db.Where(fmt.Sprintf("%s IS NULL", db.Dialect().Quote(deleteTime.DBName)))
+	}
+	if deleted != nil {
+		return db.Where(fmt.Sprintf("%s = ?", db.Dialect().Quote(deleted.DBName)), false)
+	}
+	return db
+}
+
+// checkPrimaryKey 主键保护，如果 m 什么都没设置，按主键的操作会影响表的所有记录
+func checkPrimaryKey(ms *_gorm.ModelStruct, m entity.Entity, op string) error {
+	for _, pf := range ms.PrimaryFields {
+		value, err := breflect.GetStructField(m, pf.Name)
+		if err != nil {
+			return err
+		}
+
+		if breflect.IsBlank(value) {
+			return errors.New(fmt.Sprintf("primary key %s must set for %s", pf.Name, op))
+		}
+	}
+	return nil
+}
+
+func (r *BaseRepository) Restore(c context.Context, m entity.Entity) error {
+	db, err := r.DB(c)
+	if err != nil {
+		return err
+	}
+	db = opentracing.SetSpanToGorm(c, db)
+
+	scope := db.NewScope(m)
+	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
+
+	ms := scope.GetModelStruct()
+	if err = checkPrimaryKey(ms, m, "restore"); err != nil {
+		return err
+	}
+
+	deleteTime, deleted := softDeleteFields(ms)
+	change := make(map[string]interface{})
+	db = dsgorm.SetAuditOperation(dsgorm.SetEntity(db.Table(table), m), audit.OperationRestore)
+	db = db.Where(m.Unique())
+
+	if deleteTime != nil {
+		change[deleteTime.DBName] = nil
+		db = db.Where(fmt.Sprintf("%s IS NOT NULL", db.Dialect().Quote(deleteTime.DBName)))
+	}
+	if deleted != nil {
+		change[deleted.DBName] = false
+		if deleteTime == nil {
+			db = db.Where(fmt.Sprintf("%s = ?", db.Dialect().Quote(deleted.DBName)), true)
+		}
+	}
+	if len(change) == 0 {
+		return repository.ErrNotSoftDeletable
+	}
+
+	result := db.Updates(change)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *BaseRepository) HardDelete(c context.Context, m entity.Entity) error {
+	db, err := r.DB(c)
+	if err != nil {
+		return err
+	}
+	db = opentracing.SetSpanToGorm(c, db)
+
+	scope := db.NewScope(m)
+	table := r.DataSourceProvider.ProvideTable(c, scope.TableName())
+
+	if err = checkPrimaryKey(scope.GetModelStruct(), m, "delete"); err != nil {
+		return err
+	}
+
+	// Unscoped 跳过删除钩子中的软删除
+	return db.Table(table).Unscoped().Delete(m).Error
+}
@@ -3,12 +3,10 @@ package gorm
 import (
 	"errors"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/duolacloud/microbase/domain/entity"
-	"github.com/duolacloud/microbase/domain/repository"
 	"github.com/duolacloud/microbase/logger"
-	"github.com/duolacloud/microbase/types/smarttime"
 	_gorm "github.com/jinzhu/gorm"
 )
 
@@ -18,6 +16,12 @@ var (
 
 // 约定 name为小写
 func FindField(name string, ms *_gorm.ModelStruct, dbHandler *_gorm.DB) (*_gorm.StructField, bool) {
+	field, ok := fieldsOf(ms, dbHandler)[name]
+	return field, ok
+}
+
+// fieldsOf 按 json tag 索引的字段
+func fieldsOf(ms *_gorm.ModelStruct, dbHandler *_gorm.DB) map[string]*_gorm.StructField {
 	tableName := ms.TableName(dbHandler)
 	fieldsMap := fieldsCache[tableName]
 	if fieldsMap == nil {
@@ -31,8 +35,41 @@ func FindField(name string, ms *_gorm.ModelStruct, dbHandler *_gorm.DB) (*_gorm.
 
 		fieldsCache[tableName] = fieldsMap
 	}
-	field, ok := fieldsMap[name]
-	return field, ok
+	return fieldsMap
+}
+
+// filterFields 过滤条件可以使用的字段
+func filterFields(ms *_gorm.ModelStruct, dbHandler *_gorm.DB) entity.FilterFields {
+	fields := make(entity.FilterFields)
+	for name, field := range fieldsOf(ms, dbHandler) {
+		fields[name] = field.Struct.Type
+	}
+	return fields
+}
+
+// condition 带参数的 SQL 条件, 组合条件带括号, 可以直接嵌套
+type condition struct {
+	sql  string
+	args []interface{}
+}
+
+// joinConditions 使用 op 连接多个条件
+func joinConditions(conds []*condition, op string) *condition {
+	if len(conds) == 1 {
+		return conds[0]
+	}
+
+	sqls := make([]string, len(conds))
+	var args []interface{}
+	for i, cond := range conds {
+		sqls[i] = cond.sql
+		args = append(args, cond.args...)
+	}
+
+	return &condition{
+		sql:  fmt.Sprintf("(%s)", strings.Join(sqls, fmt.Sprintf(" %s ", op))),
+		args: args,
+	}
 }
 
 func applyFilter(db *_gorm.DB, ms *_gorm.ModelStruct, filters map[string]interface{}) (*_gorm.DB, error) {
@@ -40,143 +77,135 @@ func applyFilter(db *_gorm.DB, ms *_gorm.ModelStruct, filters map[string]interfa
 		return db, nil
 	}
 
-	var err error
-	for key, value := range filters {
-		db, err = gormFilter(db, ms, key, value)
-		if err != nil {
-			return nil, err
-		}
+	filter, err := entity.ParseFilter(filters, filterFields(ms, db))
+	if err != nil {
+		return nil, err
 	}
 
-	return db, nil
-}
+	result, err := filter.Accept(&conditionVisitor{db: db, ms: ms})
+	if err != nil {
+		return nil, err
+	}
 
-func gormFilter(db *_gorm.DB, ms *_gorm.ModelStruct, key string, value interface{}) (*_gorm.DB, error) {
-	filterType := entity.FilterType(key)
-
-	switch filterType {
-	case entity.FilterType_AND:
-		{
-			/* TODO 暂时默认就是 AND
-			subFilters := v.([]interface{})
-			for _, item := range subFilters {
-				db = buildQuery(db, subFilter, ms)
-			}*/
-		}
-	case entity.FilterType_OR:
-		{
-			/* TODO  暂时不支持 page 中支持 or
-			for _, item := range subFilters {
-				db := buildQuery(db, subFilter, ms)
-				orCond = orCond.Or(subCond)
-			}
-			*/
-		}
-	default:
-		{
-			field, ok := FindField(key, ms, db)
-			if !ok {
-				err := errors.New(fmt.Sprintf("ERR_DB_UNKNOWN_FIELD %s", key))
-				return nil, err
-			}
-
-			fieldName := field.DBName
-
-			vMap, ok := value.(map[string]interface{})
-			if !ok {
-				switch field.Struct.Type.String() {
-				case "time.Time", "*time.Time":
-					v, err := smarttime.Parse(value)
-					if err == nil {
-						value = time.Time(v)
-					}
-				}
-
-				return db.Where(fmt.Sprintf("%s = ?", fieldName), value), nil
-			}
-
-			for vKey, vValue := range vMap {
-				switch field.Struct.Type.String() {
-				case "time.Time", "*time.Time":
-					v, err := smarttime.Parse(vValue)
-					if err == nil {
-						vValue = v
-					}
-				}
-
-				filterType = entity.FilterType(vKey)
-				switch filterType {
-				case entity.FilterType_EQ:
-					return db.Where(fmt.Sprintf("%s = ?", fieldName), vValue), nil
-				case entity.FilterType_NE:
-					return db.Where(fmt.Sprintf("%s != ?", fieldName), vValue), nil
-				case entity.FilterType_GT:
-					return db.Where(fmt.Sprintf("%s > ?", fieldName), vValue), nil
-				case entity.FilterType_GTE:
-					return db.Where(fmt.Sprintf("%s >= ?", fieldName), vValue), nil
-				case entity.FilterType_LT:
-					return db.Where(fmt.Sprintf("%s < ?", fieldName), vValue), nil
-				case entity.FilterType_LTE:
-					return db.Where(fmt.Sprintf("%s <= ?", fieldName), vValue), nil
-				case entity.FilterType_LIKE:
-					return db.Where(fmt.Sprintf("%s LIKE ?", fieldName), vValue), nil
-				case entity.FilterType_MATCH:
-					return db.Where(fmt.Sprintf("%s LIKE ?", fieldName), vValue), nil
-				case entity.FilterType_NOT_LIKE:
-					return db.Not(fmt.Sprintf("%s LIKE ?", fieldName), vValue), nil
-				case entity.FilterType_IN:
-					return gormFilterIn(db, fieldName, vValue)
-				case entity.FilterType_NOT_IN:
-					return gormFilterNotIn(db, fieldName, vValue)
-				case entity.FilterType_BETWEEN:
-					return gormFilterBetween(db, fieldName, vValue)
-				case entity.FilterType_IS_NULL:
-					return db.Where(fmt.Sprintf("%s IS NULL", fieldName)), nil
-				case entity.FilterType_NOT_NULL:
-					return db.Where(fmt.Sprintf("%s IS NOT NULL", fieldName)), nil
-				}
-			}
-		}
+	cond := result.(*condition)
+	if cond == nil {
+		return db, nil
 	}
+	return db.Where(cond.sql, cond.args...), nil
+}
 
-	return db, nil
+// conditionVisitor 把过滤条件编译为 SQL 条件
+type conditionVisitor struct {
+	db *_gorm.DB
+	ms *_gorm.ModelStruct
 }
 
-func gormFilterIn(db *_gorm.DB, key string, value interface{}) (*_gorm.DB, error) {
-	values, ok := value.([]interface{})
-	if !ok {
-		return nil, repository.ErrFilterValueType
+func (v *conditionVisitor) VisitGroup(f *entity.GroupFilter, children []interface{}) (interface{}, error) {
+	var conds []*condition
+	for _, child := range children {
+		if cond := child.(*condition); cond != nil {
+			conds = append(conds, cond)
+		}
 	}
 
-	return db.Where(fmt.Sprintf("%s IN (?)", key), values), nil
-}
-
-func gormFilterNotIn(db *_gorm.DB, key string, value interface{}) (*_gorm.DB, error) {
-	values, ok := value.([]interface{})
-	if !ok {
-		return nil, repository.ErrFilterValueType
+	// 空的组合条件不限制结果
+	if len(conds) == 0 {
+		return (*condition)(nil), nil
 	}
 
-	return db.Where(fmt.Sprintf("%s NOT IN (?)", key), values), nil
+	switch f.Type {
+	case entity.FilterType_OR:
+		return joinConditions(conds, "OR"), nil
+	case entity.FilterType_NOR:
+		cond := joinConditions(conds, "OR")
+		return &condition{
+			sql:  fmt.Sprintf("NOT (%s)", cond.sql),
+			args: cond.args,
+		}, nil
+	default:
+		return joinConditions(conds, "AND"), nil
+	}
 }
 
-func gormFilterBetween(db *_gorm.DB, key string, value interface{}) (*_gorm.DB, error) {
-	values, ok := value.([]interface{})
+func (v *conditionVisitor) VisitField(f *entity.FieldFilter) (interface{}, error) {
+	field, ok := FindField(f.Field, v.ms, v.db)
 	if !ok {
-		return nil, repository.ErrFilterValueType
+		err := errors.New(fmt.Sprintf("ERR_DB_UNKNOWN_FIELD %s", f.Field))
+		return nil, err
 	}
-	if len(values) != 2 {
-		return nil, repository.ErrFilterValueSize
+
+	fieldName := field.DBName
+
+	switch f.Op {
+	case entity.FilterType_EQ:
+		return &condition{sql: fmt.Sprintf("%s = ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_NE:
+		return &condition{sql: fmt.Sprintf("%s != ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_GT:
+		return &condition{sql: fmt.Sprintf("%s > ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_GTE:
+		return &condition{sql: fmt.Sprintf("%s >= ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_LT:
+		return &condition{sql: fmt.Sprintf("%s < ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_LTE:
+		return &condition{sql: fmt.Sprintf("%s <= ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_LIKE, entity.FilterType_MATCH:
+		return &condition{sql: fmt.Sprintf("%s LIKE ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_NOT_LIKE:
+		return &condition{sql: fmt.Sprintf("%s NOT LIKE ?", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_IN:
+		return &condition{sql: fmt.Sprintf("%s IN (?)", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_NOT_IN:
+		return &condition{sql: fmt.Sprintf("%s NOT IN (?)", fieldName), args: []interface{}{f.Value}}, nil
+	case entity.FilterType_BETWEEN:
+		return gormFilterBetween(fieldName, f.Value.([]interface{})), nil
+	case entity.FilterType_IS_NULL:
+		return &condition{sql: fmt.Sprintf("%s IS NULL", fieldName)}, nil
+	case entity.FilterType_NOT_NULL:
+		return &condition{sql: fmt.Sprintf("%s IS NOT NULL", fieldName)}, nil
+	default:
+		return nil, &entity.FilterError{Path: f.Field, Err: entity.ErrUnknownOperator}
 	}
+}
+
+// gormFilterBetween 任意一端为 null 时不限制该端
+func gormFilterBetween(key string, values []interface{}) *condition {
 	if values[0] != nil && values[1] != nil {
-		return db.Where(fmt.Sprintf("%s between ? and ?", key), values[0], values[1]), nil
+		return &condition{sql: fmt.Sprintf("%s between ? and ?", key), args: []interface{}{values[0], values[1]}}
 	} else if values[0] != nil && values[1] == nil {
-		return db.Where(fmt.Sprintf("%s >= ?", key), values[0]), nil
+		return &condition{sql: fmt.Sprintf("%s >= ?", key), args: []interface{}{values[0]}}
 	} else if values[0] == nil && values[1] != nil {
-		return db.Where(fmt.Sprintf("%s <= ?", key), values[1]), nil
+		return &condition{sql: fmt.Sprintf("%s <= ?", key), args: []interface{}{values[1]}}
 	} else {
+		return nil
+	}
+}
+
+// applyFields 只查询指定的字段, 字段名为 json tag, 映射为列名后 SELECT
+func applyFields(db *_gorm.DB, ms *_gorm.ModelStruct, fields []string) (*_gorm.DB, error) {
+	if len(fields) == 0 {
 		return db, nil
 	}
+
+	columns := make([]string, len(fields))
+	for i, name := range fields {
+		field, ok := FindField(name, ms, db)
+		if !ok || field.IsIgnored || !field.IsNormal {
+			return nil, errors.New(fmt.Sprintf("unknown field: %s", name))
+		}
+		columns[i] = field.DBName
+	}
+
+	return db.Select(columns), nil
+}
+
+// primaryFields 主键字段, 投影时必须包含, 保证结果可以定位到记录
+func primaryFields(ms *_gorm.ModelStruct) []string {
+	fields := make([]string, len(ms.PrimaryFields))
+	for i, field := range ms.PrimaryFields {
+		fields[i] = field.Tag.Get("json")
+	}
+	return fields
 }
 
 func applyOrders(dbHandler *_gorm.DB, ms *_gorm.ModelStruct, orders []*entity.Order) (*_gorm.DB, error) {
@@ -0,0 +1,92 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	_gorm "github.com/jinzhu/gorm"
+)
+
+// versionColumn 实体的版本字段和对应的列, 实体没有版本字段时返回 nil
+func versionColumn(ms *_gorm.ModelStruct, m entity.Entity) (*repository.VersionField, *_gorm.StructField, error) {
+	vf, err := repository.FindVersionField(m)
+	if err != nil || vf == nil {
+		return nil, nil, err
+	}
+
+	for _, field := range ms.StructFields {
+		if field.Name == vf.Name && field.IsNormal && !field.IsIgnored {
+			return vf, field, nil
+		}
+	}
+	return nil, nil, errors.New(fmt.Sprintf("version field %s is not a column", vf.Name))
+}
+
+// updateMap 把 Update 的参数转换为列和值, 结构体和 gorm 的 Updates 一样忽略零值字段
+func updateMap(db *_gorm.DB, data interface{}) map[string]interface{} {
+	change := make(map[string]interface{})
+	if values, ok := data.(map[string]interface{}); ok {
+		for k, v := range values {
+			change[k] = v
+		}
+		return change
+	}
+
+	for _, field := range db.NewScope(data).Fields() {
+		if field.IsNormal && !field.IsIgnored && !field.IsBlank {
+			change[field.DBName] = field.Field.Interface()
+		}
+	}
+	return change
+}
+
+// updateVersion 只更新版本号为 version 的记录, 同时版本号加一, 没有匹配的记录时返回 ErrConflict
+func updateVersion(db *_gorm.DB, m entity.Entity, column *_gorm.StructField, version int64, change map[string]interface{}) error {
+	quoted := db.Dialect().Quote(column.DBName)
+	change[column.DBName] = _gorm.Expr(fmt.Sprintf("%s + 1", quoted))
+
+	result := dsgorm.SetEntity(db, m).Where(m.Unique()).Where(fmt.Sprintf("%s = ?", quoted), version).Updates(change)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrConflict
+	}
+	return nil
+}
+
+// upsertVersion 版本号为 0 时新建, 否则和 Save 一样更新全部字段
+func upsertVersion(db *_gorm.DB, m entity.Entity, vf *repository.VersionField, column *_gorm.StructField) (*repository.ChangeInfo, error) {
+	version := vf.Get(m)
+	if version == 0 {
+		vf.Set(m, 1)
+		if err := db.Create(m).Error; err != nil {
+			vf.Set(m, 0)
+
+			// 记录已经存在, 说明被其它请求抢先创建
+			var n int
+			if db.Where(m.Unique()).Count(&n); n > 0 {
+				return nil, repository.ErrConflict
+			}
+			return nil, err
+		}
+		return &repository.ChangeInfo{Updated: 1}, nil
+	}
+
+	change := make(map[string]interface{})
+	for _, field := range db.NewScope(m).Fields() {
+		if field.IsNormal && !field.IsIgnored && !field.IsPrimaryKey {
+			change[field.DBName] = field.Field.Interface()
+		}
+	}
+
+	if err := updateVersion(db, m, column, version, change); err != nil {
+		return nil, err
+	}
+	vf.Set(m, version+1)
+
+	return &repository.ChangeInfo{Updated: 1, Matched: 1}, nil
+}
@@ -0,0 +1,409 @@
+package indexed
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/datasource"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/logger"
+)
+
+// 实体通过 tag 开启索引同步, 写在任意一个字段上, 例如
+//
+//	ID string `json:"id" gorm:"primary_key" repo:"index"`
+//
+// repo:"index,async" 表示异步同步, 也可以通过 datasource.Entities.SyncIndex 声明
+const (
+	IndexTagValue = "index"
+	AsyncTagValue = "async"
+)
+
+// BaseRepository 把写入同步到搜索服务索引的仓储, 通常包装 gorm.BaseRepository,
+// 读操作直接访问被包装的仓储
+type BaseRepository struct {
+	repo      repository.BaseRepository
+	indexer   *Indexer
+	entityMap datasource.EntityMap
+}
+
+// NewBaseRepository 只同步通过 tag 或者 entityMap 开启了同步的实体,
+// 同步模式下索引写入失败时返回错误, 此时数据库的写入已经生效;
+// 在 dsgorm.TxManager 开启的事务中写入时, 事务提交后才写入索引
+func NewBaseRepository(repo repository.BaseRepository, indexer *Indexer, entityMap datasource.EntityMap) repository.BaseRepository {
+	return &BaseRepository{
+		repo:      repo,
+		indexer:   indexer,
+		entityMap: entityMap,
+	}
+}
+
+// policy entityMap 的声明优先于 tag
+func (r *BaseRepository) policy(m interface{}) (datasource.IndexSync, bool) {
+	if syncs, ok := r.entityMap.(datasource.IndexSyncMap); ok {
+		if sync, ok := syncs.GetIndexSync(m); ok {
+			return sync, true
+		}
+	}
+
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return datasource.IndexSync{}, false
+	}
+	return tagPolicy(t)
+}
+
+func tagPolicy(t reflect.Type) (datasource.IndexSync, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		values := strings.Split(field.Tag.Get(repository.TagKey), ",")
+		if values[0] == IndexTagValue {
+			sync := datasource.IndexSync{Mode: datasource.IndexSyncSync}
+			for _, v := range values[1:] {
+				if strings.TrimSpace(v) == AsyncTagValue {
+					sync.Mode = datasource.IndexSyncAsync
+				}
+			}
+			return sync, true
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if sync, ok := tagPolicy(field.Type); ok {
+				return sync, true
+			}
+		}
+	}
+	return datasource.IndexSync{}, false
+}
+
+// partition 按实体的同步方式分组, 批量写入中可能有不同类型的实体
+func (r *BaseRepository) partition(ms []entity.Entity) (syncs, asyncs []entity.Entity) {
+	for _, m := range ms {
+		sync, ok := r.policy(m)
+		if !ok {
+			continue
+		}
+		if sync.Mode == datasource.IndexSyncAsync {
+			asyncs = append(asyncs, m)
+		} else {
+			syncs = append(syncs, m)
+		}
+	}
+	return
+}
+
+func (r *BaseRepository) index(c context.Context, ms ...entity.Entity) error {
+	return r.sync(c, ms, nil)
+}
+
+func (r *BaseRepository) remove(c context.Context, ms ...entity.Entity) error {
+	return r.sync(c, nil, ms)
+}
+
+// sync 写入 index 的文档并删除 remove 的文档, 调用时已经序列化; ctx 中有事务时在提交后写入,
+// 回滚时不写入, 提交后写入失败只能记录日志
+func (r *BaseRepository) sync(c context.Context, index, remove []entity.Entity) error {
+	syncIndex, asyncIndex := r.partition(index)
+	syncRemove, asyncRemove := r.partition(remove)
+
+	syncTask, err := r.indexer.prepare(c, syncIndex, syncRemove)
+	if err != nil {
+		return err
+	}
+	asyncTask, err := r.indexer.prepare(cache.Detach(c), asyncIndex, asyncRemove)
+	if err != nil {
+		return err
+	}
+
+	run := func() error {
+		r.indexer.enqueue(asyncTask)
+		return r.indexer.run(syncTask)
+	}
+	if !dsgorm.InTx(c) {
+		return run()
+	}
+
+	dsgorm.AfterCommit(c, func() {
+		if err := run(); err != nil {
+			logger.Errorf("index after commit error: %v", err)
+		}
+	})
+	return nil
+}
+
+// reindex 按主键重新读取同一类型的实体后写入索引, 读取不到说明已经被删除
+func (r *BaseRepository) reindex(c context.Context, ms ...entity.Entity) error {
+	if len(ms) == 0 {
+		return nil
+	}
+	if _, ok := r.policy(ms[0]); !ok {
+		return nil
+	}
+
+	first := len(ms)
+	conn, err := r.repo.Connection(c, &entity.ConnectionQuery{
+		Filter: keyFilter(ms),
+		First:  &first,
+	}, ms[0])
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(conn.Edges))
+	current := make([]entity.Entity, 0, len(conn.Edges))
+	for _, edge := range conn.Edges {
+		m := edge.Node.(entity.Entity)
+		found[documentID(m)] = true
+		current = append(current, m)
+	}
+
+	var removed []entity.Entity
+	for _, m := range ms {
+		if !found[documentID(m)] {
+			removed = append(removed, m)
+		}
+	}
+	return r.sync(c, current, removed)
+}
+
+// eachPage 按主键顺序分批读取满足条件的实体, 每批调用一次 fn
+func (r *BaseRepository) eachPage(c context.Context, m entity.Entity, filter map[string]interface{}, fn func(ms []entity.Entity) error) error {
+	first := r.indexer.options.BatchSize
+	query := &entity.ConnectionQuery{
+		Filter: filter,
+		First:  &first,
+	}
+
+	for {
+		conn, err := r.repo.Connection(c, query, m)
+		if err != nil {
+			return err
+		}
+
+		ms := make([]entity.Entity, 0, len(conn.Edges))
+		for _, edge := range conn.Edges {
+			ms = append(ms, edge.Node.(entity.Entity))
+		}
+		if len(ms) > 0 {
+			if err = fn(ms); err != nil {
+				return err
+			}
+		}
+
+		if !conn.PageInfo.HasNext || len(conn.Edges) == 0 {
+			return nil
+		}
+		after := conn.PageInfo.EndCursor
+		query.After = &after
+	}
+}
+
+// keyFilter 按主键匹配 ms 的过滤条件, 单主键为 IN, 联合主键为每个实体的 Unique() 的 OR
+func keyFilter(ms []entity.Entity) map[string]interface{} {
+	uniques := make([]interface{}, len(ms))
+	values := make([]interface{}, len(ms))
+	var key string
+	single := true
+	for i, m := range ms {
+		uniques[i] = m.Unique()
+
+		keys, ok := uniques[i].(map[string]interface{})
+		if !ok || len(keys) != 1 {
+			single = false
+			continue
+		}
+		for k, v := range keys {
+			if i > 0 && k != key {
+				single = false
+			}
+			key = k
+			values[i] = v
+		}
+	}
+
+	if single {
+		return map[string]interface{}{
+			key: map[string]interface{}{string(entity.FilterType_IN): values},
+		}
+	}
+	return map[string]interface{}{string(entity.FilterType_OR): uniques}
+}
+
+func (r *BaseRepository) Get(c context.Context, m entity.Entity) error {
+	return r.repo.Get(c, m)
+}
+
+func (r *BaseRepository) Create(c context.Context, m entity.Entity) error {
+	if err := r.repo.Create(c, m); err != nil {
+		return err
+	}
+
+	return r.index(c, m)
+}
+
+func (r *BaseRepository) Upsert(c context.Context, m entity.Entity) (*repository.ChangeInfo, error) {
+	change, err := r.repo.Upsert(c, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return change, r.index(c, m)
+}
+
+// Update 只写入 data 中的字段, 重新读取完整的实体后写入索引
+func (r *BaseRepository) Update(c context.Context, m entity.Entity, data interface{}) error {
+	if err := r.repo.Update(c, m, data); err != nil {
+		return err
+	}
+
+	return r.reindex(c, m)
+}
+
+// Delete 软删除的记录同样从索引中删除
+func (r *BaseRepository) Delete(c context.Context, m entity.Entity) error {
+	if err := r.repo.Delete(c, m); err != nil {
+		return err
+	}
+
+	return r.remove(c, m)
+}
+
+func (r *BaseRepository) Restore(c context.Context, m entity.Entity) error {
+	if err := r.repo.Restore(c, m); err != nil {
+		return err
+	}
+
+	return r.reindex(c, m)
+}
+
+func (r *BaseRepository) HardDelete(c context.Context, m entity.Entity) error {
+	if err := r.repo.HardDelete(c, m); err != nil {
+		return err
+	}
+
+	return r.remove(c, m)
+}
+
+func (r *BaseRepository) BatchCreate(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchCreate(c, ms)
+	if err != nil {
+		return results, err
+	}
+
+	return results, r.index(c, succeeded(ms, results)...)
+}
+
+func (r *BaseRepository) BatchUpsert(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchUpsert(c, ms)
+	if err != nil {
+		return results, err
+	}
+
+	return results, r.index(c, succeeded(ms, results)...)
+}
+
+func (r *BaseRepository) BatchDelete(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results, err := r.repo.BatchDelete(c, ms)
+	if err != nil {
+		return results, err
+	}
+
+	return results, r.remove(c, succeeded(ms, results)...)
+}
+
+func (r *BaseRepository) Count(c context.Context, m entity.Entity, filter map[string]interface{}) (int64, error) {
+	return r.repo.Count(c, m, filter)
+}
+
+func (r *BaseRepository) Exists(c context.Context, m entity.Entity, filter map[string]interface{}) (bool, error) {
+	return r.repo.Exists(c, m, filter)
+}
+
+func (r *BaseRepository) FindOne(c context.Context, m entity.Entity, filter map[string]interface{}) error {
+	return r.repo.FindOne(c, m, filter)
+}
+
+// UpdateMany 按主键顺序分批更新满足条件的实体, 每批更新后按主键重新读取并写入索引,
+// 没有开启同步的实体直接更新
+func (r *BaseRepository) UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+	if _, ok := r.policy(m); !ok {
+		return r.repo.UpdateMany(c, m, filter, change, opts...)
+	}
+
+	var total int64
+	err := r.eachPage(c, m, filter, func(ms []entity.Entity) error {
+		n, err := r.repo.UpdateMany(c, m, pageFilter(filter, ms), change, opts...)
+		if err != nil || n == 0 {
+			return err
+		}
+		total += n
+		return r.reindex(c, ms...)
+	})
+	return total, err
+}
+
+// DeleteMany 和 UpdateMany 一样分批删除, 每批删除后从索引中删除
+func (r *BaseRepository) DeleteMany(c context.Context, m entity.Entity, filter map[string]interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+	if _, ok := r.policy(m); !ok {
+		return r.repo.DeleteMany(c, m, filter, opts...)
+	}
+
+	var total int64
+	err := r.eachPage(c, m, filter, func(ms []entity.Entity) error {
+		n, err := r.repo.DeleteMany(c, m, pageFilter(filter, ms), opts...)
+		if err != nil || n == 0 {
+			return err
+		}
+		total += n
+		return r.remove(c, ms...)
+	})
+	return total, err
+}
+
+func (r *BaseRepository) Page(c context.Context, m entity.Entity, query *entity.PageQuery, resultPtr interface{}) (total int64, err error) {
+	return r.repo.Page(c, m, query, resultPtr)
+}
+
+func (r *BaseRepository) List(c context.Context, query *entity.CursorQuery, m entity.Entity, resultPtr interface{}) (*entity.CursorExtra, error) {
+	return r.repo.List(c, query, m, resultPtr)
+}
+
+func (r *BaseRepository) Connection(c context.Context, query *entity.ConnectionQuery, m entity.Entity) (*entity.Connection, error) {
+	return r.repo.Connection(c, query, m)
+}
+
+func (r *BaseRepository) Aggregate(c context.Context, m entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	return r.repo.Aggregate(c, m, query)
+}
+
+// pageFilter 只写入读取到的这一批实体, 读取之后不再满足条件的实体不会被写入
+func pageFilter(filter map[string]interface{}, ms []entity.Entity) map[string]interface{} {
+	return map[string]interface{}{
+		string(entity.FilterType_AND): []interface{}{filter, keyFilter(ms)},
+	}
+}
+
+// succeeded 批量写入中成功的实体
+func succeeded(ms []entity.Entity, results []*repository.BatchResult) []entity.Entity {
+	var ok []entity.Entity
+	for i, result := range results {
+		if i < len(ms) && result.Error == nil {
+			ok = append(ok, ms[i])
+		}
+	}
+	return ok
+}
@@ -0,0 +1,242 @@
+package indexed
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/datasource"
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/domain/repository/gorm"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/config/source/memory"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID   int64  `json:"id" repo:"index"`
+	Name string `json:"name"`
+}
+
+func (u *User) Unique() interface{} {
+	return map[string]interface{}{
+		"id": u.ID,
+	}
+}
+
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (t *Tag) Unique() interface{} {
+	return map[string]interface{}{
+		"id": t.ID,
+	}
+}
+
+// fakeRepository 只支持 User 的内存仓储
+type fakeRepository struct {
+	repository.BaseRepository
+	users map[int64]User
+}
+
+func (r *fakeRepository) Create(c context.Context, m entity.Entity) error {
+	if u, ok := m.(*User); ok {
+		r.users[u.ID] = *u
+	}
+	return nil
+}
+
+func (r *fakeRepository) Get(c context.Context, m entity.Entity) error {
+	u := m.(*User)
+	found, ok := r.users[u.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	*u = found
+	return nil
+}
+
+func (r *fakeRepository) Update(c context.Context, m entity.Entity, data interface{}) error {
+	u := m.(*User)
+	r.users[u.ID] = User{ID: u.ID, Name: data.(string)}
+	return nil
+}
+
+func (r *fakeRepository) Delete(c context.Context, m entity.Entity) error {
+	delete(r.users, m.(*User).ID)
+	return nil
+}
+
+// Connection 按 id 顺序分页返回用户, 游标为 id, 只支持 reindex 按主键读取的过滤条件
+func (r *fakeRepository) Connection(c context.Context, query *entity.ConnectionQuery, m entity.Entity) (*entity.Connection, error) {
+	var ids []int64
+	if in, ok := query.Filter["id"].(map[string]interface{}); ok {
+		for _, id := range in["IN"].([]interface{}) {
+			ids = append(ids, id.(int64))
+		}
+	} else {
+		for id := range r.users {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var after int64
+	if query.After != nil {
+		after, _ = strconv.ParseInt(*query.After, 10, 64)
+	}
+
+	conn := &entity.Connection{}
+	for _, id := range ids {
+		u, ok := r.users[id]
+		if !ok || (query.After != nil && id <= after) {
+			continue
+		}
+		if query.First != nil && len(conn.Edges) == *query.First {
+			conn.PageInfo.HasNext = true
+			break
+		}
+
+		cursor := strconv.FormatInt(id, 10)
+		conn.Edges = append(conn.Edges, &entity.Edge{Node: &u, Cursor: cursor})
+		conn.PageInfo.EndCursor = cursor
+	}
+	return conn, nil
+}
+
+// fakeSearchClient 按索引和 id 保存文档
+type fakeSearchClient struct {
+	search.SearchClient
+	documents map[string]map[string]interface{}
+}
+
+func (s *fakeSearchClient) BatchUpsert(c context.Context, documents []*search.Document) ([]error, error) {
+	for _, doc := range documents {
+		s.documents[doc.Index+"/"+doc.Fields["id"].(string)] = doc.Fields
+	}
+	return make([]error, len(documents)), nil
+}
+
+func (s *fakeSearchClient) BatchDelete(c context.Context, keys []*search.DocumentKey) ([]error, error) {
+	for _, key := range keys {
+		delete(s.documents, key.Index+"/"+key.Id)
+	}
+	return make([]error, len(keys)), nil
+}
+
+type fakeProvider struct {
+	client search.SearchClient
+}
+
+func (p *fakeProvider) ProvideDB(ctx context.Context) (interface{}, error) {
+	return p.client, nil
+}
+
+func (p *fakeProvider) ProvideTable(ctx context.Context, tableName string) string {
+	tenantId, _ := multitenancy.FromContext(ctx)
+	return tableName + "_" + tenantId
+}
+
+func TestIndexedRepository(t *testing.T) {
+	ctx := multitenancy.WithContext(context.Background(), "t1")
+	client := &fakeSearchClient{documents: map[string]map[string]interface{}{}}
+	indexer := NewIndexer(&fakeProvider{client})
+	repo := NewBaseRepository(&fakeRepository{users: map[int64]User{}}, indexer, datasource.NewEntities())
+
+	assert.NoError(t, repo.Create(ctx, &User{ID: 1, Name: "tom"}))
+	assert.Equal(t, map[string]interface{}{"id": "1", "name": "tom"}, client.documents["users_t1/1"])
+
+	// 只写入部分字段, 重新读取后写入完整的文档
+	assert.NoError(t, repo.Update(ctx, &User{ID: 1}, "jerry"))
+	assert.Equal(t, "jerry", client.documents["users_t1/1"]["name"])
+
+	assert.NoError(t, repo.Delete(ctx, &User{ID: 1}))
+	assert.Empty(t, client.documents)
+
+	// 没有开启同步的实体
+	assert.NoError(t, repo.Create(ctx, &Tag{ID: "a"}))
+	assert.Empty(t, client.documents)
+}
+
+func TestIndexedRepositoryAsync(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSearchClient{documents: map[string]map[string]interface{}{}}
+	indexer := NewIndexer(&fakeProvider{client})
+
+	entities := datasource.NewEntities().Register(&User{}).SyncIndex(&User{}, datasource.IndexSync{Mode: datasource.IndexSyncAsync})
+	repo := NewBaseRepository(&fakeRepository{users: map[int64]User{}}, indexer, entities)
+
+	assert.NoError(t, repo.Create(ctx, &User{ID: 2, Name: "tom"}))
+
+	// Close 等待队列中的写入完成
+	assert.NoError(t, indexer.Close())
+	assert.Equal(t, "tom", client.documents["users_/2"]["name"])
+}
+
+type Member struct {
+	ID   string `json:"id" gorm:"primary_key" repo:"index"`
+	Name string `json:"name"`
+}
+
+func (m *Member) Unique() interface{} {
+	return map[string]interface{}{
+		"id": m.ID,
+	}
+}
+
+// TestIndexedRepositoryTx 需要本地的 mysql, 事务提交后才写入索引, 回滚时不写入
+func TestIndexedRepositoryTx(t *testing.T) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{
+		"db": {
+			"driver": "mysql",
+			"connection_string": "root:debezium@tcp(localhost:3306)/test?charset=utf8mb4&parseTime=True&loc=Local"
+		}
+	}`)
+	if err = cfg.Load(memory.NewSource(memory.WithJSON(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	tenancy, err := dsgorm.NewGormTenancy(cfg, datasource.NewEntities().Register(&Member{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := multitenancy.WithContext(context.Background(), "t1")
+	client := &fakeSearchClient{documents: map[string]map[string]interface{}{}}
+	repo := NewBaseRepository(gorm.NewBaseRepository(repository.NewMultitenancyProvider(tenancy)), NewIndexer(&fakeProvider{client}), datasource.NewEntities())
+	txManager := dsgorm.NewTxManager(tenancy)
+
+	committed := &Member{ID: uuid.NewV4().String(), Name: "tom"}
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, committed); err != nil {
+			return err
+		}
+		assert.Empty(t, client.documents)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "tom", client.documents["members_t1/"+committed.ID]["name"])
+
+	rolledBack := &Member{ID: uuid.NewV4().String(), Name: "jerry"}
+	err = txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, rolledBack); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+	assert.NotContains(t, client.documents, "members_t1/"+rolledBack.ID)
+}
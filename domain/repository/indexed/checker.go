@@ -0,0 +1,288 @@
+package indexed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/multitenancy"
+)
+
+// Report 一个租户中实体表和索引的差异, 记录文档 id
+type Report struct {
+	Tenant string `json:"tenant"`
+	Entity string `json:"entity"`
+	// Rows 表中的记录数, Documents 索引中的文档数
+	Rows      int64 `json:"rows"`
+	Documents int64 `json:"documents"`
+	// Missing 索引中缺少的文档, Extra 表中已经不存在的文档, Stale 内容与表中不一致的文档
+	Missing []string `json:"missing"`
+	Extra   []string `json:"extra"`
+	Stale   []string `json:"stale"`
+	// Repaired 修复模式下写入或删除的文档数
+	Repaired int64 `json:"repaired"`
+}
+
+func (r *Report) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Stale) == 0
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("tenant %s entity %s: rows %d, documents %d, missing %d, extra %d, stale %d, repaired %d",
+		r.Tenant, r.Entity, r.Rows, r.Documents, len(r.Missing), len(r.Extra), len(r.Stale), r.Repaired)
+}
+
+// Check 比较 source 中实体的全部记录和 ctx 中租户的索引, repair 为 true 时修复差异.
+//
+// 索引中的 id 是字符串, 与数字主键的顺序不同, 无法同时遍历两边, 所以分两遍按块比较:
+// 先按主键顺序遍历表, 每块按 id 读取索引中对应的文档, 找出缺少和不一致的文档;
+// 再按 id 顺序遍历索引, 每块按主键读取表中对应的记录, 找出多余的文档. 内存占用只与块大小和差异数有关
+func (i *Indexer) Check(c context.Context, source repository.BaseRepository, m entity.Entity, repair bool) (*Report, error) {
+	tenantId, _ := multitenancy.FromContext(c)
+	key := i.DocumentKey(c, m)
+
+	report := &Report{
+		Tenant: tenantId,
+		Entity: key.Type,
+	}
+
+	client, err := i.client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = i.checkRows(c, client, source, m, report); err != nil {
+		return nil, err
+	}
+	if err = i.checkDocuments(c, client, source, m, report); err != nil {
+		return nil, err
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Stale)
+
+	if repair {
+		err = i.repair(c, source, m, report)
+	}
+	return report, err
+}
+
+// checkRows 按主键顺序遍历表, 每块按 id 读取索引中的文档
+func (i *Indexer) checkRows(c context.Context, client search.SearchClient, source repository.BaseRepository, m entity.Entity, report *Report) error {
+	key := i.DocumentKey(c, m)
+
+	first := i.options.BatchSize
+	query := &entity.ConnectionQuery{
+		First: &first,
+	}
+
+	for {
+		conn, err := source.Connection(c, query, m)
+		if err != nil {
+			return err
+		}
+
+		hashes := make(map[string]uint64, len(conn.Edges))
+		ids := make([]interface{}, 0, len(conn.Edges))
+		for _, edge := range conn.Edges {
+			doc, err := i.document(c, edge.Node.(entity.Entity))
+			if err != nil {
+				return err
+			}
+			id := doc.Fields["id"].(string)
+			hashes[id] = contentHash(doc.Fields)
+			ids = append(ids, id)
+		}
+		report.Rows += int64(len(ids))
+
+		if len(ids) > 0 {
+			docs, err := documents(c, client, key, ids)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				fields, ok := docs[id.(string)]
+				switch {
+				case !ok:
+					report.Missing = append(report.Missing, id.(string))
+				case hashes[id.(string)] != contentHash(fields):
+					report.Stale = append(report.Stale, id.(string))
+				}
+			}
+		}
+
+		if !conn.PageInfo.HasNext || len(conn.Edges) == 0 {
+			return nil
+		}
+		after := conn.PageInfo.EndCursor
+		query.After = &after
+	}
+}
+
+// documents 按 id 读取索引中的文档, 包括软删除的记录的文档
+func documents(c context.Context, client search.SearchClient, key *search.DocumentKey, ids []interface{}) (map[string]map[string]interface{}, error) {
+	first := len(ids)
+	conn, err := client.Connection(c, &entity.ConnectionQuery{
+		Filter: map[string]interface{}{
+			"id": map[string]interface{}{string(entity.FilterType_IN): ids},
+		},
+		First:          &first,
+		IncludeDeleted: true,
+	}, key.Index, key.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]map[string]interface{}, len(conn.Edges))
+	for _, edge := range conn.Edges {
+		doc := edge.Node.(*search.Document)
+		docs[fmt.Sprint(doc.Fields["id"])] = doc.Fields
+	}
+	return docs, nil
+}
+
+// checkDocuments 按 id 顺序遍历索引, 每块按主键读取表中的记录, 软删除的记录不应该留在索引中
+func (i *Indexer) checkDocuments(c context.Context, client search.SearchClient, source repository.BaseRepository, m entity.Entity, report *Report) error {
+	key := i.DocumentKey(c, m)
+
+	first := i.options.BatchSize
+	query := &entity.ConnectionQuery{
+		First: &first,
+		Orders: []*entity.Order{
+			{Field: "id", Direction: entity.OrderDirectionAsc},
+		},
+		IncludeDeleted: true,
+	}
+
+	for {
+		conn, err := client.Connection(c, query, key.Index, key.Type)
+		if err != nil {
+			return err
+		}
+
+		ms := make([]entity.Entity, 0, len(conn.Edges))
+		for _, edge := range conn.Edges {
+			id := fmt.Sprint(edge.Node.(*search.Document).Fields["id"])
+			k, err := keyEntity(m, id)
+			if err != nil {
+				return errors.New(fmt.Sprintf("parse document id %s error: %v", id, err))
+			}
+			ms = append(ms, k)
+		}
+		report.Documents += int64(len(ms))
+
+		if len(ms) > 0 {
+			first := len(ms)
+			rows, err := source.Connection(c, &entity.ConnectionQuery{
+				Filter: keyFilter(ms),
+				First:  &first,
+			}, m)
+			if err != nil {
+				return err
+			}
+
+			found := make(map[string]bool, len(rows.Edges))
+			for _, edge := range rows.Edges {
+				found[documentID(edge.Node.(entity.Entity))] = true
+			}
+			for _, k := range ms {
+				if id := documentID(k); !found[id] {
+					report.Extra = append(report.Extra, id)
+				}
+			}
+		}
+
+		if !conn.PageInfo.HasNext || len(conn.Edges) == 0 {
+			return nil
+		}
+		after := conn.PageInfo.EndCursor
+		query.After = &after
+	}
+}
+
+// keyEntity 按文档 id 构造只有主键的实体, 是 documentID 的逆运算
+func keyEntity(m entity.Entity, id string) (entity.Entity, error) {
+	k := reflect.New(reflect.TypeOf(m).Elem()).Interface().(entity.Entity)
+
+	keys, ok := m.Unique().(map[string]interface{})
+	if !ok || len(keys) != 1 {
+		// 联合主键的文档 id 为主键的 json
+		return k, json.Unmarshal([]byte(id), k)
+	}
+
+	var b []byte
+	for name, v := range keys {
+		if reflect.ValueOf(v).Kind() == reflect.String {
+			b, _ = json.Marshal(map[string]interface{}{name: id})
+		} else {
+			b, _ = json.Marshal(map[string]interface{}{name: json.RawMessage(id)})
+		}
+	}
+	return k, json.Unmarshal(b, k)
+}
+
+// repair 删除多余的文档, 按主键重新读取缺少和不一致的记录写入索引, 和 Index、Remove 一样分批写入, 遇到错误时停止
+func (i *Indexer) repair(c context.Context, source repository.BaseRepository, m entity.Entity, report *Report) error {
+	key := i.DocumentKey(c, m)
+
+	t := &task{ctx: c}
+	for _, id := range report.Extra {
+		t.keys = append(t.keys, &search.DocumentKey{Index: key.Index, Type: key.Type, Id: id})
+	}
+	if err := i.run(t); err != nil {
+		return err
+	}
+	report.Repaired += int64(len(t.keys))
+
+	ids := append(append([]string{}, report.Missing...), report.Stale...)
+	for start := 0; start < len(ids); start += i.options.BatchSize {
+		end := start + i.options.BatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		ms := make([]entity.Entity, 0, end-start)
+		for _, id := range ids[start:end] {
+			k, err := keyEntity(m, id)
+			if err != nil {
+				return errors.New(fmt.Sprintf("parse document id %s error: %v", id, err))
+			}
+			ms = append(ms, k)
+		}
+
+		// 表中的记录在比较之后可能已经变化, 重新读取, 读取不到的记录不再写入
+		first := len(ms)
+		conn, err := source.Connection(c, &entity.ConnectionQuery{
+			Filter: keyFilter(ms),
+			First:  &first,
+		}, m)
+		if err != nil {
+			return err
+		}
+
+		current := make([]entity.Entity, 0, len(conn.Edges))
+		for _, edge := range conn.Edges {
+			current = append(current, edge.Node.(entity.Entity))
+		}
+		if err = i.Index(c, current...); err != nil {
+			return err
+		}
+		report.Repaired += int64(len(current))
+	}
+	return nil
+}
+
+// contentHash 文档字段的哈希, json 序列化时 map 按 key 排序, 表和索引中的文档都经过 json 转换, 类型一致
+func contentHash(fields map[string]interface{}) uint64 {
+	b, _ := json.Marshal(fields)
+
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
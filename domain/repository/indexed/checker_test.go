@@ -0,0 +1,116 @@
+package indexed
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// Connection 按 id 的字符串顺序分页返回索引中的文档, 游标为 id, 只支持按 id 的 IN 条件过滤
+func (s *fakeSearchClient) Connection(c context.Context, query *entity.ConnectionQuery, index, typ string) (*entity.Connection, error) {
+	var ids []string
+	if in, ok := query.Filter["id"].(map[string]interface{}); ok {
+		for _, id := range in["IN"].([]interface{}) {
+			ids = append(ids, id.(string))
+		}
+	} else {
+		for key := range s.documents {
+			if strings.HasPrefix(key, index+"/") {
+				ids = append(ids, strings.TrimPrefix(key, index+"/"))
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	conn := &entity.Connection{}
+	for _, id := range ids {
+		fields, ok := s.documents[index+"/"+id]
+		if !ok || (query.After != nil && id <= *query.After) {
+			continue
+		}
+		if query.First != nil && len(conn.Edges) == *query.First {
+			conn.PageInfo.HasNext = true
+			break
+		}
+
+		conn.Edges = append(conn.Edges, &entity.Edge{Node: &search.Document{Index: index, Type: typ, Fields: fields}, Cursor: id})
+		conn.PageInfo.EndCursor = id
+	}
+	return conn, nil
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSearchClient{documents: map[string]map[string]interface{}{
+		"users_/1": {"id": "1", "name": "tom"},
+		"users_/2": {"id": "2", "name": "old"},
+		"users_/9": {"id": "9", "name": "gone"},
+	}}
+	indexer := NewIndexer(&fakeProvider{client})
+	source := &fakeRepository{users: map[int64]User{
+		1:  {ID: 1, Name: "tom"},
+		2:  {ID: 2, Name: "jerry"},
+		10: {ID: 10, Name: "spike"},
+	}}
+
+	report, err := indexer.Check(ctx, source, &User{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "users", report.Entity)
+	assert.Equal(t, int64(3), report.Rows)
+	assert.Equal(t, int64(3), report.Documents)
+	assert.Equal(t, []string{"10"}, report.Missing)
+	assert.Equal(t, []string{"9"}, report.Extra)
+	assert.Equal(t, []string{"2"}, report.Stale)
+	assert.Len(t, client.documents, 3)
+
+	report, err = indexer.Check(ctx, source, &User{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), report.Repaired)
+
+	report, err = indexer.Check(ctx, source, &User{}, false)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+}
+
+func TestCheckBatches(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSearchClient{documents: map[string]map[string]interface{}{
+		"users_/1":  {"id": "1", "name": "u1"},
+		"users_/11": {"id": "11", "name": "gone"},
+		"users_/2":  {"id": "2", "name": "old"},
+		"users_/3":  {"id": "3", "name": "u3"},
+		"users_/5":  {"id": "5", "name": "u5"},
+		"users_/6":  {"id": "6", "name": "old"},
+		"users_/8":  {"id": "8", "name": "gone"},
+	}}
+	source := &fakeRepository{users: map[int64]User{}}
+	for id := int64(1); id <= 6; id++ {
+		source.users[id] = User{ID: id, Name: "u" + strconv.FormatInt(id, 10)}
+	}
+
+	// 每块 2 条, 缺少、多余和不一致的文档分布在不同的块中
+	indexer := NewIndexer(&fakeProvider{client}, WithBatchSize(2))
+
+	report, err := indexer.Check(ctx, source, &User{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), report.Rows)
+	assert.Equal(t, int64(7), report.Documents)
+	assert.Equal(t, []string{"4"}, report.Missing)
+	assert.Equal(t, []string{"11", "8"}, report.Extra)
+	assert.Equal(t, []string{"2", "6"}, report.Stale)
+
+	report, err = indexer.Check(ctx, source, &User{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), report.Repaired)
+	assert.Len(t, client.documents, 6)
+
+	report, err = indexer.Check(ctx, source, &User{}, false)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+}
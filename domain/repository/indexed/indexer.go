@@ -0,0 +1,323 @@
+package indexed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/duolacloud/microbase/audit"
+	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/duolacloud/microbase/logger"
+)
+
+type Options struct {
+	// QueueSize 异步写入的队列长度, 队列满时入队阻塞, 直到后台写入腾出位置
+	QueueSize int
+	// BatchSize 回填和批量写入时每批的文档数
+	BatchSize int
+}
+
+type Option func(o *Options)
+
+func WithQueueSize(size int) Option {
+	return func(o *Options) {
+		o.QueueSize = size
+	}
+}
+
+func WithBatchSize(size int) Option {
+	return func(o *Options) {
+		o.BatchSize = size
+	}
+}
+
+// task 异步写入的一批文档, 入队时已经序列化, 之后修改实体不影响写入的内容
+type task struct {
+	ctx       context.Context
+	documents []*search.Document
+	keys      []*search.DocumentKey
+}
+
+// Indexer 把实体写入搜索服务中租户的索引, 索引名和类型与 search.BaseRepository 一致
+type Indexer struct {
+	provider repository.DataSourceProvider
+	options  Options
+
+	mu     sync.Mutex
+	queue  chan *task
+	closed bool
+	done   chan struct{}
+	// sending 已经取得队列、正在入队的调用, Close 等待它们完成后才关闭队列
+	sending sync.WaitGroup
+}
+
+// NewIndexer provider 为搜索服务的 DataSourceProvider, ProvideDB 返回 search.SearchClient
+func NewIndexer(provider repository.DataSourceProvider, opts ...Option) *Indexer {
+	options := Options{
+		QueueSize: 1024,
+		BatchSize: 500,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Indexer{
+		provider: provider,
+		options:  options,
+	}
+}
+
+func (i *Indexer) client(c context.Context) (search.SearchClient, error) {
+	o, err := i.provider.ProvideDB(c)
+	if err != nil {
+		return nil, err
+	}
+	return o.(search.SearchClient), nil
+}
+
+// DocumentKey 实体对应的文档, 文档 id 为主键的值, 联合主键为 json
+func (i *Indexer) DocumentKey(c context.Context, m entity.Entity) *search.DocumentKey {
+	typ := audit.EntityType(m)
+
+	return &search.DocumentKey{
+		Index: i.provider.ProvideTable(c, typ),
+		Type:  typ,
+		Id:    documentID(m),
+	}
+}
+
+func (i *Indexer) document(c context.Context, m entity.Entity) (*search.Document, error) {
+	fields, err := audit.Fields(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// 搜索服务按 id 字段确定文档, 必须为字符串
+	key := i.DocumentKey(c, m)
+	fields["id"] = key.Id
+
+	return &search.Document{
+		Index:  key.Index,
+		Type:   key.Type,
+		Fields: fields,
+	}, nil
+}
+
+func (i *Indexer) prepare(c context.Context, index, remove []entity.Entity) (*task, error) {
+	t := &task{ctx: c}
+	for _, m := range index {
+		doc, err := i.document(c, m)
+		if err != nil {
+			return nil, err
+		}
+		t.documents = append(t.documents, doc)
+	}
+	for _, m := range remove {
+		t.keys = append(t.keys, i.DocumentKey(c, m))
+	}
+	return t, nil
+}
+
+// Index 写入实体的文档, 已存在时覆盖
+func (i *Indexer) Index(c context.Context, ms ...entity.Entity) error {
+	t, err := i.prepare(c, ms, nil)
+	if err != nil {
+		return err
+	}
+	return i.run(t)
+}
+
+// Remove 删除实体的文档, 文档不存在不是错误
+func (i *Indexer) Remove(c context.Context, ms ...entity.Entity) error {
+	t, err := i.prepare(c, nil, ms)
+	if err != nil {
+		return err
+	}
+	return i.run(t)
+}
+
+// IndexAsync 在后台写入, 同一个 Indexer 的写入按入队顺序执行
+func (i *Indexer) IndexAsync(c context.Context, ms ...entity.Entity) error {
+	t, err := i.prepare(cache.Detach(c), ms, nil)
+	if err != nil {
+		return err
+	}
+	i.enqueue(t)
+	return nil
+}
+
+func (i *Indexer) RemoveAsync(c context.Context, ms ...entity.Entity) error {
+	t, err := i.prepare(cache.Detach(c), nil, ms)
+	if err != nil {
+		return err
+	}
+	i.enqueue(t)
+	return nil
+}
+
+func (i *Indexer) enqueue(t *task) {
+	if len(t.documents) == 0 && len(t.keys) == 0 {
+		return
+	}
+
+	i.mu.Lock()
+	if i.closed {
+		i.mu.Unlock()
+		i.runAndLog(t)
+		return
+	}
+
+	if i.queue == nil {
+		i.queue = make(chan *task, i.options.QueueSize)
+		i.done = make(chan struct{})
+		go i.work(i.queue, i.done)
+	}
+	queue := i.queue
+	i.sending.Add(1)
+	i.mu.Unlock()
+	defer i.sending.Done()
+
+	// 队列满时在锁外等待, 不改为同步写入, 否则会先于队列中更早的写入执行
+	queue <- t
+}
+
+func (i *Indexer) work(queue chan *task, done chan struct{}) {
+	defer close(done)
+
+	for t := range queue {
+		i.runAndLog(t)
+	}
+}
+
+func (i *Indexer) runAndLog(t *task) {
+	if err := i.run(t); err != nil {
+		logger.Errorf("indexer error: %v", err)
+	}
+}
+
+// Close 等待队列中的写入完成, 之后的异步写入改为同步执行
+func (i *Indexer) Close() error {
+	i.mu.Lock()
+	if i.closed {
+		i.mu.Unlock()
+		return nil
+	}
+	i.closed = true
+	queue, done := i.queue, i.done
+	i.mu.Unlock()
+
+	if queue != nil {
+		i.sending.Wait()
+		close(queue)
+		<-done
+	}
+	return nil
+}
+
+func (i *Indexer) run(t *task) error {
+	if len(t.documents) == 0 && len(t.keys) == 0 {
+		return nil
+	}
+
+	client, err := i.client(t.ctx)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(t.documents); start += i.options.BatchSize {
+		end := start + i.options.BatchSize
+		if end > len(t.documents) {
+			end = len(t.documents)
+		}
+
+		errs, err := client.BatchUpsert(t.ctx, t.documents[start:end])
+		if err = batchError("index", err, errs); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(t.keys); start += i.options.BatchSize {
+		end := start + i.options.BatchSize
+		if end > len(t.keys) {
+			end = len(t.keys)
+		}
+
+		errs, err := client.BatchDelete(t.ctx, t.keys[start:end])
+		if err = batchError("remove", err, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchError 返回整批的错误或者第一条失败的文档的错误
+func batchError(op string, err error, errs []error) error {
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	var first error
+	for _, e := range errs {
+		if e != nil {
+			if first == nil {
+				first = e
+			}
+			failed++
+		}
+	}
+	if first != nil {
+		return errors.New(fmt.Sprintf("%s %d documents failed: %v", op, failed, first))
+	}
+	return nil
+}
+
+// Backfill 按主键顺序读取 source 中实体的全部记录并写入索引, 用于开启同步前已有的数据和修复异步写入失败,
+// 不会删除索引中已经不存在的记录的文档, 返回写入的文档数
+func (i *Indexer) Backfill(c context.Context, source repository.BaseRepository, m entity.Entity) (int64, error) {
+	var total int64
+	first := i.options.BatchSize
+
+	query := &entity.ConnectionQuery{
+		First: &first,
+	}
+
+	for {
+		conn, err := source.Connection(c, query, m)
+		if err != nil {
+			return total, err
+		}
+
+		ms := make([]entity.Entity, 0, len(conn.Edges))
+		for _, edge := range conn.Edges {
+			ms = append(ms, edge.Node.(entity.Entity))
+		}
+
+		if err = i.Index(c, ms...); err != nil {
+			return total, err
+		}
+		total += int64(len(ms))
+
+		if !conn.PageInfo.HasNext || len(conn.Edges) == 0 {
+			return total, nil
+		}
+
+		after := conn.PageInfo.EndCursor
+		query.After = &after
+	}
+}
+
+// documentID Unique() 通常为主键字段的 map, 其它类型按 json 序列化
+func documentID(m entity.Entity) string {
+	if keys, ok := m.Unique().(map[string]interface{}); ok {
+		return audit.EntityID(keys)
+	}
+
+	b, _ := json.Marshal(m.Unique())
+	return string(b)
+}
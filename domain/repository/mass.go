@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/duolacloud/microbase/domain/entity"
+)
+
+// ErrEmptyFilter 批量更新或删除时没有过滤条件
+var ErrEmptyFilter = errors.New("filter must be set for mass operation")
+
+// MassOptions UpdateMany 和 DeleteMany 的选项
+type MassOptions struct {
+	// Force 允许不带过滤条件操作全部记录
+	Force bool
+}
+
+type MassOption func(o *MassOptions)
+
+// Force 允许 filter 为空, 此时更新或删除全部记录
+func Force() MassOption {
+	return func(o *MassOptions) {
+		o.Force = true
+	}
+}
+
+func NewMassOptions(opts ...MassOption) MassOptions {
+	var o MassOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// GuardFilter 和 Delete 的主键保护一样, 没有过滤条件时拒绝操作全部记录,
+// 按解析后的条件判断, {"AND": []} 和 {"name": {}} 这样不包含字段条件的 filter 同样视为空
+func GuardFilter(filter map[string]interface{}, opts ...MassOption) error {
+	if NewMassOptions(opts...).Force {
+		return nil
+	}
+
+	group, err := entity.ParseFilter(filter, nil)
+	if err != nil {
+		return err
+	}
+	if group.Empty() {
+		return ErrEmptyFilter
+	}
+	return nil
+}
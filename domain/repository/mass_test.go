@@ -0,0 +1,33 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardFilter(t *testing.T) {
+	empty := []map[string]interface{}{
+		nil,
+		{},
+		{"AND": []interface{}{}},
+		{"OR": []interface{}{}},
+		{"NOR": []interface{}{}},
+		{"name": map[string]interface{}{}},
+		{"OR": []interface{}{map[string]interface{}{}}},
+		{"AND": []interface{}{map[string]interface{}{"OR": []interface{}{}}}},
+	}
+	for _, filter := range empty {
+		assert.Equal(t, repository.ErrEmptyFilter, repository.GuardFilter(filter), "%v", filter)
+		assert.NoError(t, repository.GuardFilter(filter, repository.Force()), "%v", filter)
+	}
+
+	assert.NoError(t, repository.GuardFilter(map[string]interface{}{"name": "a"}))
+	assert.NoError(t, repository.GuardFilter(map[string]interface{}{
+		"OR": []interface{}{map[string]interface{}{"name": map[string]interface{}{"IS_NULL": true}}},
+	}))
+
+	// 格式错误的条件返回解析错误
+	assert.Error(t, repository.GuardFilter(map[string]interface{}{"OR": "a"}))
+}
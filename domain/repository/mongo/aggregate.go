@@ -0,0 +1,173 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	reflect2 "github.com/duolacloud/microbase/domain/repository/mongo/reflect"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Aggregate 使用聚合管道分组统计, $match 过滤后 $group, 分组的 _id 为分组字段组成的文档
+func (r *baseRepository) Aggregate(c context.Context, m entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	ms, err := reflect2.GetStructInfo(m, nil)
+	if err != nil {
+		return nil, err
+	}
+	collection := TheNamingStrategy.Table(ms.Name)
+
+	if err = query.Validate(filterFields(ms)); err != nil {
+		return nil, err
+	}
+
+	match, err := buildQuery(ms, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := aggregatePipeline(applySoftDelete(ms, match, query.IncludeDeleted), query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []bson.M
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		return c.Pipe(pipeline).All(&results)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]*entity.AggregateBucket, len(results))
+	for i, result := range results {
+		bucket := &entity.AggregateBucket{
+			Keys:    make(map[string]interface{}),
+			Metrics: make(map[string]float64, len(query.Metrics)),
+		}
+
+		if id, ok := result["_id"].(bson.M); ok {
+			for j, name := range query.KeyFields() {
+				bucket.Keys[name] = id[groupKey(j)]
+			}
+		}
+
+		for j, metric := range query.Metrics {
+			bucket.Metrics[metric.Name()] = toFloat(result[metricKey(j)])
+		}
+
+		buckets[i] = bucket
+	}
+
+	return buckets, nil
+}
+
+// groupKey, metricKey 字段名可能包含 ".", 不能直接作为 $group 的字段名, 按位置命名
+func groupKey(i int) string {
+	return fmt.Sprintf("k%d", i)
+}
+
+func metricKey(i int) string {
+	return fmt.Sprintf("m%d", i)
+}
+
+func aggregatePipeline(match interface{}, query *entity.AggregateQuery) ([]bson.M, error) {
+	id := bson.M{}
+	for i, field := range query.GroupBy {
+		id[groupKey(i)] = "$" + field
+	}
+
+	if query.Histogram != nil {
+		expr, err := truncateDate("$"+query.Histogram.Field, query.Histogram.Interval)
+		if err != nil {
+			return nil, err
+		}
+		id[groupKey(len(query.GroupBy))] = expr
+	}
+
+	group := bson.M{"_id": id}
+	for i, metric := range query.Metrics {
+		switch metric.Func {
+		case entity.AggregateFuncCount:
+			if metric.Field == "" {
+				group[metricKey(i)] = bson.M{"$sum": 1}
+			} else {
+				// 只统计字段存在且不为 null 的文档
+				group[metricKey(i)] = bson.M{"$sum": bson.M{"$cond": []interface{}{
+					bson.M{"$gt": []interface{}{"$" + metric.Field, nil}}, 1, 0,
+				}}}
+			}
+		case entity.AggregateFuncSum:
+			group[metricKey(i)] = bson.M{"$sum": "$" + metric.Field}
+		case entity.AggregateFuncAvg:
+			group[metricKey(i)] = bson.M{"$avg": "$" + metric.Field}
+		case entity.AggregateFuncMin:
+			group[metricKey(i)] = bson.M{"$min": "$" + metric.Field}
+		case entity.AggregateFuncMax:
+			group[metricKey(i)] = bson.M{"$max": "$" + metric.Field}
+		}
+	}
+
+	sort := bson.D{}
+	for i := range query.KeyFields() {
+		sort = append(sort, bson.DocElem{Name: "_id." + groupKey(i), Value: 1})
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": group},
+	}
+	if len(sort) > 0 {
+		pipeline = append(pipeline, bson.M{"$sort": sort})
+	}
+	if query.Size > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": query.Size})
+	}
+	return pipeline, nil
+}
+
+// truncateDate 截断到分桶起始时间的表达式, 周按 ISO 周从周一开始
+func truncateDate(field string, interval entity.DateInterval) (bson.M, error) {
+	if interval == entity.DateIntervalWeek {
+		return bson.M{"$dateFromParts": bson.M{
+			"isoWeekYear": bson.M{"$isoWeekYear": field},
+			"isoWeek":     bson.M{"$isoWeek": field},
+		}}, nil
+	}
+
+	parts := []struct {
+		name string
+		op   string
+		from entity.DateInterval
+	}{
+		{"year", "$year", entity.DateIntervalYear},
+		{"month", "$month", entity.DateIntervalMonth},
+		{"day", "$dayOfMonth", entity.DateIntervalDay},
+		{"hour", "$hour", entity.DateIntervalHour},
+		{"minute", "$minute", entity.DateIntervalMinute},
+	}
+
+	expr := bson.M{}
+	for _, part := range parts {
+		expr[part.name] = bson.M{part.op: field}
+		if part.from == interval {
+			return bson.M{"$dateFromParts": expr}, nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("unknown date interval %s", interval))
+}
+
+func toFloat(v interface{}) float64 {
+	switch i := v.(type) {
+	case int:
+		return float64(i)
+	case int64:
+		return float64(i)
+	case float64:
+		return i
+	default:
+		return 0
+	}
+}
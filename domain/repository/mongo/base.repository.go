@@ -32,6 +32,13 @@ func (r *baseRepository) Create(c context.Context, m entity.Model) error {
 
 	// TODO 找出 ctime, utime 的 tag 进行设置
 
+	if _, _, err = versionColumn(ms, m); err != nil {
+		return err
+	}
+	if err = repository.InitVersion(m); err != nil {
+		return err
+	}
+
 	return Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
 		return c.Insert(m)
 	})
@@ -44,6 +51,18 @@ func (r *baseRepository) Upsert(c context.Context, m entity.Model) (changeInfo *
 	}
 	collection := TheNamingStrategy.Table(ms.Name)
 
+	vf, column, err := versionColumn(ms, m)
+	if err != nil {
+		return
+	}
+	if vf != nil {
+		err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+			changeInfo, err = upsertVersion(c, m, vf, column)
+			return err
+		})
+		return
+	}
+
 	Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
 		var change *mgo.ChangeInfo
 		change, err = c.Upsert(m.Unique(), m)
@@ -69,7 +88,21 @@ func (r *baseRepository) Update(c context.Context, m entity.Model, change interf
 	}
 	collection := TheNamingStrategy.Table(ms.Name)
 
+	vf, column, err := versionColumn(ms, m)
+	if err != nil {
+		return err
+	}
+
 	return Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		if vf != nil {
+			version := vf.Get(m)
+			if err := updateVersion(c, m, column, version, change); err != nil {
+				return err
+			}
+			vf.Set(m, version+1)
+			return nil
+		}
+
 		return c.Update(m.Unique(), bson.M{
 			"$set": change,
 		})
@@ -84,7 +117,7 @@ func (r *baseRepository) Get(c context.Context, m entity.Model) error {
 	collection := TheNamingStrategy.Table(ms.Name)
 
 	return Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
-		return c.Find(m.Unique()).One(m)
+		return c.Find(applySoftDelete(ms, m.Unique(), false)).One(m)
 	})
 }
 
@@ -95,7 +128,15 @@ func (r *baseRepository) Delete(c context.Context, m entity.Model) error {
 	}
 	collection := TheNamingStrategy.Table(ms.Name)
 
+	// 有软删除字段时和 gorm 的删除钩子一样只更新字段
+	change := softDeleteChange(ms, true)
+
 	return Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		if change != nil {
+			return c.Update(applySoftDelete(ms, m.Unique(), false), bson.M{
+				"$set": change,
+			})
+		}
 		return c.Remove(m.Unique())
 	})
 }
@@ -111,14 +152,20 @@ func (r *baseRepository) Page(c context.Context, m entity.Model, query *entity.P
 	if err != nil {
 		return
 	}
+	softFilters := applySoftDelete(ms, filters, query.IncludeDeleted)
 
 	sorts, err := applyOrders(ms, query.Sort)
 	if err != nil {
 		return
 	}
 
+	selector, err := projection(ms, query.Fields)
+	if err != nil {
+		return
+	}
+
 	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
-		total, err = c.Find(filters).Count()
+		total, err = c.Find(softFilters).Count()
 		if err != nil {
 			return err
 		}
@@ -139,7 +186,7 @@ func (r *baseRepository) Page(c context.Context, m entity.Model, query *entity.P
 			pageCount++
 		}
 
-		return c.Find(filters).Skip(offset).Limit(pageSize).Sort(sorts...).All(resultPtr)
+		return c.Find(softFilters).Select(selector).Skip(offset).Limit(pageSize).Sort(sorts...).All(resultPtr)
 	})
 
 	return
@@ -156,6 +203,7 @@ func (r *baseRepository) List(c context.Context, query *entity.CursorQuery, m en
 	if err != nil {
 		return
 	}
+	softFilters := applySoftDelete(ms, filters, query.IncludeDeleted)
 
 	cursorProp, ok := ms.FieldsMap[query.CursorSort.Property]
 	if !ok {
@@ -168,7 +216,13 @@ func (r *baseRepository) List(c context.Context, query *entity.CursorQuery, m en
 		return
 	}
 
-	filters = bson.M{"$and": []bson.M{cursorFilter, filters}}
+	filters = bson.M{"$and": []interface{}{cursorFilter, softFilters}}
+
+	// 游标由游标字段的值编码, 必须获取
+	selector, err := projection(ms, query.Fields, cursorProp.TableFieldName)
+	if err != nil {
+		return
+	}
 
 	size := query.Size
 	if size > 1000 {
@@ -179,7 +233,7 @@ func (r *baseRepository) List(c context.Context, query *entity.CursorQuery, m en
 
 	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
 		// 多取一个，用于判断是否有更多数据
-		return c.Find(filters).Limit(size).Sort(sort).All(resultPtr)
+		return c.Find(filters).Select(selector).Limit(size).Sort(sort).All(resultPtr)
 	})
 
 	if err != nil {
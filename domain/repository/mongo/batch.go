@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	reflect2 "github.com/duolacloud/microbase/domain/repository/mongo/reflect"
+	"gopkg.in/mgo.v2"
+)
+
+// BatchCreate 和 Create 一样, 版本号为 0 的实体从 1 开始
+func (r *baseRepository) BatchCreate(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+
+	var rest []entity.Entity
+	var positions []int
+	for i, m := range ms {
+		if err := repository.InitVersion(m); err != nil {
+			results[i].Error = err
+			continue
+		}
+		rest = append(rest, m)
+		positions = append(positions, i)
+	}
+
+	restResults, err := r.bulk(rest, func(b *mgo.Bulk, m entity.Entity) {
+		b.Insert(m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range positions {
+		results[i] = restResults[j]
+	}
+	return results, nil
+}
+
+// BatchUpsert 有版本字段的实体逐条通过 Upsert 按版本号写入
+func (r *baseRepository) BatchUpsert(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+	rest, positions := repository.SplitVersioned(ms, results, func(m entity.Entity) error {
+		_, err := r.Upsert(c, m)
+		return err
+	})
+
+	restResults, err := r.bulk(rest, func(b *mgo.Bulk, m entity.Entity) {
+		b.Upsert(m.Unique(), m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range positions {
+		results[i] = restResults[j]
+	}
+	return results, nil
+}
+
+func (r *baseRepository) BatchDelete(c context.Context, ms []entity.Entity) ([]*repository.BatchResult, error) {
+	return r.bulk(ms, func(b *mgo.Bulk, m entity.Entity) {
+		b.Remove(m.Unique())
+	})
+}
+
+// bulk 按集合分组, 每个集合使用一个无序的 bulk writer, 每条数据对应 bulk 中的一个操作
+func (r *baseRepository) bulk(ms []entity.Entity, add func(b *mgo.Bulk, m entity.Entity)) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ms))
+
+	var collections []string
+	groups := make(map[string][]int)
+	for i, m := range ms {
+		info, err := reflect2.GetStructInfo(m, nil)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		collection := TheNamingStrategy.Table(info.Name)
+		if _, ok := groups[collection]; !ok {
+			collections = append(collections, collection)
+		}
+		groups[collection] = append(groups[collection], i)
+	}
+
+	for _, collection := range collections {
+		indexes := groups[collection]
+
+		err := Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+			b := c.Bulk()
+			b.Unordered()
+			for _, i := range indexes {
+				add(b, ms[i])
+			}
+			_, err := b.Run()
+			return err
+		})
+		if err == nil {
+			continue
+		}
+
+		bulkErr, ok := err.(*mgo.BulkError)
+		if !ok {
+			for _, i := range indexes {
+				results[i].Error = err
+			}
+			continue
+		}
+
+		for _, ec := range bulkErr.Cases() {
+			if ec.Index >= 0 && ec.Index < len(indexes) {
+				results[indexes[ec.Index]].Error = ec.Err
+			}
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,136 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	reflect2 "github.com/duolacloud/microbase/domain/repository/mongo/reflect"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// filtered 返回 m 对应的集合和由 filter 生成的查询条件, includeDeleted 为 false 时排除软删除的文档
+func filtered(m entity.Entity, filter map[string]interface{}, includeDeleted bool) (collection string, query interface{}, err error) {
+	ms, err := reflect2.GetStructInfo(m, nil)
+	if err != nil {
+		return
+	}
+	collection = TheNamingStrategy.Table(ms.Name)
+
+	match, err := buildQuery(ms, filter)
+	if err != nil {
+		return
+	}
+
+	query = applySoftDelete(ms, match, includeDeleted)
+	return
+}
+
+func (r *baseRepository) Count(c context.Context, m entity.Entity, filter map[string]interface{}) (total int64, err error) {
+	collection, query, err := filtered(m, filter, false)
+	if err != nil {
+		return
+	}
+
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		n, err := c.Find(query).Count()
+		total = int64(n)
+		return err
+	})
+	return
+}
+
+func (r *baseRepository) Exists(c context.Context, m entity.Entity, filter map[string]interface{}) (bool, error) {
+	collection, query, err := filtered(m, filter, false)
+	if err != nil {
+		return false, err
+	}
+
+	var n int
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		n, err = c.Find(query).Limit(1).Count()
+		return err
+	})
+	return n > 0, err
+}
+
+func (r *baseRepository) FindOne(c context.Context, m entity.Entity, filter map[string]interface{}) error {
+	collection, query, err := filtered(m, filter, false)
+	if err != nil {
+		return err
+	}
+
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		return c.Find(query).One(m)
+	})
+	if err == mgo.ErrNotFound {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+func (r *baseRepository) UpdateMany(c context.Context, m entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (updated int64, err error) {
+	if err = repository.GuardFilter(filter, opts...); err != nil {
+		return
+	}
+
+	collection, query, err := filtered(m, filter, false)
+	if err != nil {
+		return
+	}
+
+	update, err := updateManyChange(m, change)
+	if err != nil {
+		return
+	}
+
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		info, err := c.UpdateAll(query, update)
+		if err != nil {
+			return err
+		}
+		updated = int64(info.Updated)
+		return nil
+	})
+	return
+}
+
+func (r *baseRepository) DeleteMany(c context.Context, m entity.Entity, filter map[string]interface{}, opts ...repository.MassOption) (removed int64, err error) {
+	if err = repository.GuardFilter(filter, opts...); err != nil {
+		return
+	}
+
+	collection, query, err := filtered(m, filter, false)
+	if err != nil {
+		return
+	}
+
+	ms, err := reflect2.GetStructInfo(m, nil)
+	if err != nil {
+		return
+	}
+	// 和 Delete 一样, 有软删除字段时只更新字段
+	change := softDeleteChange(ms, true)
+
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		if change != nil {
+			info, err := c.UpdateAll(query, bson.M{
+				"$set": change,
+			})
+			if err != nil {
+				return err
+			}
+			removed = int64(info.Updated)
+			return nil
+		}
+
+		info, err := c.RemoveAll(query)
+		if err != nil {
+			return err
+		}
+		removed = int64(info.Removed)
+		return nil
+	})
+	return
+}
@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	reflect2 "github.com/duolacloud/microbase/domain/repository/mongo/reflect"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// softDeleteFields 集合是否有软删除的字段, 和 gorm 的删除钩子使用相同的字段名
+func softDeleteFields(ms *reflect2.StructInfo) (hasDeleteTime bool, hasDeleted bool) {
+	_, hasDeleteTime = ms.FieldsMap[repository.SoftDeleteTimeField]
+	_, hasDeleted = ms.FieldsMap[repository.SoftDeletedField]
+	return
+}
+
+// applySoftDelete 在查询条件上追加排除软删除文档的条件, 字段不存在的文档也视为没有删除
+func applySoftDelete(ms *reflect2.StructInfo, query interface{}, includeDeleted bool) interface{} {
+	if includeDeleted {
+		return query
+	}
+
+	hasDeleteTime, hasDeleted := softDeleteFields(ms)
+	if hasDeleteTime {
+		return bson.M{"$and": []interface{}{query, bson.M{repository.SoftDeleteTimeField: nil}}}
+	}
+	if hasDeleted {
+		return bson.M{"$and": []interface{}{query, bson.M{repository.SoftDeletedField: bson.M{"$ne": true}}}}
+	}
+	return query
+}
+
+// softDeleteChange 软删除或者恢复时更新的字段, 没有软删除字段时返回 nil
+func softDeleteChange(ms *reflect2.StructInfo, deleted bool) bson.M {
+	hasDeleteTime, hasDeleted := softDeleteFields(ms)
+	if !hasDeleteTime && !hasDeleted {
+		return nil
+	}
+
+	change := bson.M{}
+	if hasDeleteTime {
+		if deleted {
+			change[repository.SoftDeleteTimeField] = time.Now()
+		} else {
+			change[repository.SoftDeleteTimeField] = nil
+		}
+	}
+	if hasDeleted {
+		change[repository.SoftDeletedField] = deleted
+	}
+	return change
+}
+
+func (r *baseRepository) Restore(c context.Context, m entity.Entity) error {
+	ms, err := reflect2.GetStructInfo(m, nil)
+	if err != nil {
+		return err
+	}
+	collection := TheNamingStrategy.Table(ms.Name)
+
+	change := softDeleteChange(ms, false)
+	if change == nil {
+		return repository.ErrNotSoftDeletable
+	}
+
+	// 只恢复已经删除的文档, 和 applySoftDelete 的条件相反
+	deleted := bson.M{repository.SoftDeletedField: true}
+	if hasDeleteTime, _ := softDeleteFields(ms); hasDeleteTime {
+		deleted = bson.M{repository.SoftDeleteTimeField: bson.M{"$ne": nil}}
+	}
+	query := bson.M{"$and": []interface{}{m.Unique(), deleted}}
+
+	err = Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		return c.Update(query, bson.M{
+			"$set": change,
+		})
+	})
+	if err == mgo.ErrNotFound {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+func (r *baseRepository) HardDelete(c context.Context, m entity.Entity) error {
+	ms, err := reflect2.GetStructInfo(m, nil)
+	if err != nil {
+		return err
+	}
+	collection := TheNamingStrategy.Table(ms.Name)
+
+	return Execute(r.db.Session, r.db.Name, collection, func(c *mgo.Collection) error {
+		return c.Remove(m.Unique())
+	})
+}
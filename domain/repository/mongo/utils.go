@@ -3,156 +3,158 @@ package mongo
 import (
 	"errors"
 	"fmt"
-	"time"
+	"regexp"
+	"strings"
 
 	"github.com/duolacloud/microbase/domain/entity"
 	"github.com/duolacloud/microbase/domain/repository/mongo/reflect"
-	"github.com/duolacloud/microbase/types/smarttime"
 	"gopkg.in/mgo.v2/bson"
 )
 
 func buildQuery(ms *reflect.StructInfo, filters map[string]interface{}) (bson.M, error) {
-	bFilters := bson.M{}
 	if filters == nil || len(filters) == 0 {
-		return bFilters, nil
+		return bson.M{}, nil
 	}
 
-	for k, v := range filters {
-		filterType := entity.FilterType(k)
+	filter, err := entity.ParseFilter(filters, filterFields(ms))
+	if err != nil {
+		return nil, err
+	}
 
-		switch filterType {
-		case entity.FilterType_AND:
-			subFilters, ok := v.([]interface{})
-			if !ok {
-				return nil, errors.New("ERR_MALFORMED_PARAMETERS")
-			}
+	query, err := filter.Accept(&queryVisitor{})
+	if err != nil {
+		return nil, err
+	}
+	return query.(bson.M), nil
+}
 
-			subBFilters := make([]bson.M, len(subFilters))
-			for i, sub := range subFilters {
-				subFilter, ok := sub.(map[string]interface{})
-				if !ok {
-					return nil, errors.New("ERR_MALFORMED_PARAMETERS")
-				}
-
-				subBFilter, err := buildQuery(ms, subFilter)
-				if err != nil {
-					return nil, err
-				}
-				subBFilters[i] = subBFilter
-			}
-			bFilters["$and"] = subBFilters
-		case entity.FilterType_OR:
-			subFilters, ok := v.([]interface{})
-			if !ok {
-				return nil, errors.New("ERR_MALFORMED_PARAMETERS")
-			}
+// filterFields 过滤条件和投影可以使用的字段, 字段名为 bson tag
+func filterFields(ms *reflect.StructInfo) entity.FilterFields {
+	fields := make(entity.FilterFields, len(ms.FieldsMap))
+	for name, field := range ms.FieldsMap {
+		fields[name] = field.FieldType
+	}
+	return fields
+}
 
-			subBFilters := make([]bson.M, len(subFilters))
-			for i, sub := range subFilters {
-				subFilter, ok := sub.(map[string]interface{})
-				if !ok {
-					return nil, errors.New("ERR_MALFORMED_PARAMETERS")
-				}
-
-				subBFilter, err := buildQuery(ms, subFilter)
-				if err != nil {
-					return nil, err
-				}
-				subBFilters[i] = subBFilter
-				bFilters["$or"] = subBFilters
-			}
-		case entity.FilterType_NOR:
-			subFilters, ok := v.([]interface{})
-			if !ok {
-				return nil, errors.New("ERR_MALFORMED_PARAMETERS")
-			}
+// projection 只获取指定字段, 总是包含主键和 required 字段, fields 为空时返回 nil 获取全部字段
+func projection(ms *reflect.StructInfo, fields []string, required ...string) (bson.M, error) {
+	for name, field := range ms.FieldsMap {
+		if field.Primary {
+			required = append(required, name)
+		}
+	}
 
-			subBFilters := make([]bson.M, len(subFilters))
-			for i, sub := range subFilters {
-				subFilter, ok := sub.(map[string]interface{})
-				if !ok {
-					return nil, errors.New("ERR_MALFORMED_PARAMETERS")
-				}
-
-				subBFilter, err := buildQuery(ms, subFilter)
-				if err != nil {
-					return nil, err
-				}
-				subBFilters[i] = subBFilter
-				bFilters["$nor"] = subBFilters
-			}
-		default:
-			field, ok := ms.FieldsMap[k]
-			if !ok {
-				err := errors.New(fmt.Sprintf("ERR_DB_UNKNOWN_FIELD %s", k))
-				return nil, err
-			}
+	fields, err := entity.ProjectFields(fields, filterFields(ms), required...)
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
 
-			bFilter, err := buildMongoFilter(field, v)
-			if err != nil {
-				return nil, err
-			}
-			bFilters[k] = bFilter
-		}
+	selector := make(bson.M, len(fields))
+	for _, field := range fields {
+		selector[field] = 1
 	}
-	return bFilters, nil
+	return selector, nil
 }
 
-func buildMongoFilter(field *reflect.StructField, value interface{}) (bson.M, error) {
-	vMap, ok := value.(map[string]interface{})
-	if !ok {
-		switch field.FieldType.String() {
-		case "time.Time", "*time.Time":
-			v, err := smarttime.Parse(value)
-			if err == nil {
-				value = time.Time(v)
-			}
+// queryVisitor 把过滤条件编译为 mongo 查询
+type queryVisitor struct {
+}
+
+func (v *queryVisitor) VisitGroup(f *entity.GroupFilter, children []interface{}) (interface{}, error) {
+	queries := make([]bson.M, 0, len(children))
+	for _, child := range children {
+		if query := child.(bson.M); len(query) > 0 {
+			queries = append(queries, query)
 		}
-		return bson.M{"$eq": value}, nil
 	}
 
-	for vKey, vValue := range vMap {
-		switch field.FieldType.String() {
-		case "time.Time", "*time.Time":
-			v, err := smarttime.Parse(vValue)
-			if err == nil {
-				vValue = time.Time(v)
-			}
+	// 空的组合条件不限制结果
+	if len(queries) == 0 {
+		return bson.M{}, nil
+	}
+
+	switch f.Type {
+	case entity.FilterType_OR:
+		return bson.M{"$or": queries}, nil
+	case entity.FilterType_NOR:
+		return bson.M{"$nor": queries}, nil
+	default:
+		if len(queries) == 1 {
+			return queries[0], nil
 		}
+		return bson.M{"$and": queries}, nil
+	}
+}
+
+func (v *queryVisitor) VisitField(f *entity.FieldFilter) (interface{}, error) {
+	switch f.Op {
+	case entity.FilterType_EQ:
+		return bson.M{f.Field: bson.M{"$eq": f.Value}}, nil
+	case entity.FilterType_NE:
+		return bson.M{f.Field: bson.M{"$ne": f.Value}}, nil
+	case entity.FilterType_GT:
+		return bson.M{f.Field: bson.M{"$gt": f.Value}}, nil
+	case entity.FilterType_GTE:
+		return bson.M{f.Field: bson.M{"$gte": f.Value}}, nil
+	case entity.FilterType_LT:
+		return bson.M{f.Field: bson.M{"$lt": f.Value}}, nil
+	case entity.FilterType_LTE:
+		return bson.M{f.Field: bson.M{"$lte": f.Value}}, nil
+	case entity.FilterType_LIKE:
+		return bson.M{f.Field: bson.M{"$regex": likeToRegex(f.Value.(string))}}, nil
+	case entity.FilterType_NOT_LIKE:
+		return bson.M{f.Field: bson.M{"$not": bson.RegEx{Pattern: likeToRegex(f.Value.(string))}}}, nil
+	case entity.FilterType_MATCH:
+		return bson.M{f.Field: bson.M{"$regex": f.Value}}, nil
+	case entity.FilterType_IN:
+		return bson.M{f.Field: bson.M{"$in": f.Value}}, nil
+	case entity.FilterType_NOT_IN:
+		return bson.M{f.Field: bson.M{"$nin": f.Value}}, nil
+	case entity.FilterType_BETWEEN:
+		values := f.Value.([]interface{})
+		query := bson.M{}
+		if values[0] != nil {
+			query["$gte"] = values[0]
+		}
+		if values[1] != nil {
+			query["$lte"] = values[1]
+		}
+		if len(query) == 0 {
+			return bson.M{}, nil
+		}
+		return bson.M{f.Field: query}, nil
+	case entity.FilterType_IS_NULL:
+		return bson.M{f.Field: bson.M{"$exists": false}}, nil
+	case entity.FilterType_NOT_NULL:
+		return bson.M{f.Field: bson.M{"$exists": true}}, nil
+	default:
+		return nil, &entity.FilterError{Path: f.Field, Err: entity.ErrUnknownOperator}
+	}
+}
 
-		filterType := entity.FilterType(vKey)
-		switch filterType {
-		case entity.FilterType_EQ:
-			return bson.M{"$eq": vValue}, nil
-		case entity.FilterType_NE:
-			return bson.M{"$ne": vValue}, nil
-		case entity.FilterType_GT:
-			return bson.M{"$gt": vValue}, nil
-		case entity.FilterType_GTE:
-			return bson.M{"$gte": vValue}, nil
-		case entity.FilterType_LT:
-			return bson.M{"$lt": vValue}, nil
-		case entity.FilterType_LTE:
-			return bson.M{"$lte": vValue}, nil
-		case entity.FilterType_LIKE:
-			return bson.M{"$regex": vValue}, nil
-		case entity.FilterType_MATCH:
-			return bson.M{"$regex": vValue}, nil
-		case entity.FilterType_NOT_LIKE:
-			return bson.M{"$not": bson.M{"$regex": vValue}}, nil
-		case entity.FilterType_IN:
-			return bson.M{"$in": vValue}, nil
-		case entity.FilterType_NOT_IN:
-			return bson.M{"$nin": vValue}, nil
-		case entity.FilterType_IS_NULL:
-			return bson.M{"$exists": false}, nil
-		case entity.FilterType_NOT_NULL:
-			return bson.M{"$exists": true}, nil
+// likeToRegex 把 SQL LIKE 的模式转换为正则表达式
+func likeToRegex(like string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	escaped := false
+	for _, r := range like {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
 		default:
-			return nil, errors.New("ERR_MALFORMED_FILTER_TYPE")
+			b.WriteString(regexp.QuoteMeta(string(r)))
 		}
 	}
-	return bson.M{}, nil
+	b.WriteString("$")
+	return b.String()
 }
 
 func applyOrders(ms *reflect.StructInfo, sorts []*entity.Order) ([]string, error) {
@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	reflect2 "github.com/duolacloud/microbase/domain/repository/mongo/reflect"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// versionColumn 实体的版本字段和对应的文档字段, 实体没有版本字段时返回 nil
+func versionColumn(ms *reflect2.StructInfo, m entity.Entity) (*repository.VersionField, string, error) {
+	vf, err := repository.FindVersionField(m)
+	if err != nil || vf == nil {
+		return nil, "", err
+	}
+
+	for column, field := range ms.FieldsMap {
+		if field.Name == vf.Name {
+			return vf, column, nil
+		}
+	}
+	return nil, "", errors.New(fmt.Sprintf("version field %s has no bson tag", vf.Name))
+}
+
+// updateVersion 只更新版本号为 version 的文档, 同时版本号加一, 没有匹配的文档时返回 ErrConflict
+func updateVersion(c *mgo.Collection, m entity.Entity, column string, version int64, change interface{}) error {
+	update, err := incVersion(change, column)
+	if err != nil {
+		return err
+	}
+
+	err = c.Update(bson.M{"$and": []interface{}{m.Unique(), bson.M{column: version}}}, update)
+	if err == mgo.ErrNotFound {
+		return repository.ErrConflict
+	}
+	return err
+}
+
+// upsertVersion 版本号为 0 时新建, 否则替换版本号一致的文档
+func upsertVersion(c *mgo.Collection, m entity.Entity, vf *repository.VersionField, column string) (*repository.ChangeInfo, error) {
+	version := vf.Get(m)
+	vf.Set(m, version+1)
+
+	if version == 0 {
+		err := c.Insert(m)
+		if mgo.IsDup(err) {
+			err = repository.ErrConflict
+		}
+		if err != nil {
+			vf.Set(m, version)
+			return nil, err
+		}
+		return &repository.ChangeInfo{Updated: 1}, nil
+	}
+
+	err := c.Update(bson.M{"$and": []interface{}{m.Unique(), bson.M{column: version}}}, m)
+	if err == mgo.ErrNotFound {
+		err = repository.ErrConflict
+	}
+	if err != nil {
+		vf.Set(m, version)
+		return nil, err
+	}
+	return &repository.ChangeInfo{Updated: 1, Matched: 1}, nil
+}
+
+// updateManyChange UpdateMany 的更新语句, 有版本字段时同时把版本号加一
+func updateManyChange(m entity.Entity, change interface{}) (bson.M, error) {
+	ms, err := reflect2.GetStructInfo(m, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, column, err := versionColumn(ms, m)
+	if err != nil {
+		return nil, err
+	}
+	if column == "" {
+		return bson.M{"$set": change}, nil
+	}
+
+	return incVersion(change, column)
+}
+
+// incVersion 写入 change 的同时版本号加一
+func incVersion(change interface{}, column string) (bson.M, error) {
+	// change 可能是实体本身, 去掉其中的版本号, 否则和 $inc 冲突
+	b, err := bson.Marshal(change)
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{}
+	if err = bson.Unmarshal(b, set); err != nil {
+		return nil, err
+	}
+	delete(set, column)
+
+	update := bson.M{"$inc": bson.M{column: 1}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	return update, nil
+}
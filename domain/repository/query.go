@@ -9,3 +9,22 @@ var (
 	ErrFilterValueSize = errors.New("过滤值大小错误")
 	ErrFilterOperate   = errors.New("过滤操作错误")
 )
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// IsNotFound 判断是否为记录不存在的错误, 兼容 gorm 和 mgo 返回的错误
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == ErrNotFound {
+		return true
+	}
+
+	switch err.Error() {
+	case "record not found", "not found":
+		return true
+	}
+	return false
+}
@@ -48,6 +48,10 @@ func (r *BaseRepository) Create(c context.Context, ent entity.Entity) error {
 	typ := breflect.TheNamingStrategy.Table(ms.Name)
 	index := r.DataSourceProvider.ProvideTable(c, typ)
 
+	if err = repository.InitVersion(ent); err != nil {
+		return err
+	}
+
 	buf, err := json.Marshal(ent)
 	if err != nil {
 		return err
@@ -90,11 +94,21 @@ func (r *BaseRepository) Upsert(c context.Context, ent entity.Entity) (*reposito
 		return nil, err
 	}
 
-	err = searchClient.Upsert(c, &search.Document{
+	doc := &search.Document{
 		Index:  index,
 		Type:   typ,
 		Fields: fields,
-	})
+	}
+
+	vf, err := repository.FindVersionField(ent)
+	if err != nil {
+		return nil, err
+	}
+	if vf != nil {
+		err = r.writeVersion(c, searchClient, ent, vf, doc, true)
+	} else {
+		err = searchClient.Upsert(c, doc)
+	}
 
 	if err != nil {
 		return nil, err
@@ -129,11 +143,21 @@ func (r *BaseRepository) Update(c context.Context, ent entity.Entity, data inter
 		return err
 	}
 
-	return searchClient.Update(c, &search.Document{
+	doc := &search.Document{
 		Index:  index,
 		Type:   typ,
 		Fields: fields,
-	})
+	}
+
+	vf, err := repository.FindVersionField(ent)
+	if err != nil {
+		return err
+	}
+	if vf != nil {
+		return r.writeVersion(c, searchClient, ent, vf, doc, false)
+	}
+
+	return searchClient.Update(c, doc)
 }
 
 func (r *BaseRepository) Get(c context.Context, ent entity.Entity) error {
@@ -164,6 +188,10 @@ func (r *BaseRepository) Get(c context.Context, ent entity.Entity) error {
 		return nil
 	}
 
+	if isSoftDeleted(ent, doc) {
+		return repository.ErrNotFound
+	}
+
 	b, err := json.Marshal(doc.Fields)
 	if err != nil {
 		return err
@@ -196,6 +224,11 @@ func (r *BaseRepository) Delete(c context.Context, ent entity.Entity) error {
 		return errors.New(fmt.Sprintf("no id field for entity %v", ent))
 	}
 
+	soft, err := r.softDelete(c, searchClient, ent, index, typ, id)
+	if err != nil || soft {
+		return err
+	}
+
 	return searchClient.Delete(c, index, typ, id)
 }
 
@@ -215,8 +248,15 @@ func (r *BaseRepository) Page(c context.Context, ent entity.Entity, query *entit
 	typ := breflect.TheNamingStrategy.Table(ms.Name)
 	index := r.DataSourceProvider.ProvideTable(c, typ)
 
+	if _, err = query.ProjectFields(documentFields(ent)); err != nil {
+		return
+	}
+
+	q := *query
+	q.Filter = softDeleteFilter(ent, query.Filter, query.IncludeDeleted)
+
 	var docs []*search.Document
-	docs, total, err = searchClient.Page(c, query, index, typ)
+	docs, total, err = searchClient.Page(c, &q, index, typ)
 	if err != nil {
 		return
 	}
@@ -261,8 +301,15 @@ func (r *BaseRepository) List(c context.Context, query *entity.CursorQuery, ent
 	typ := breflect.TheNamingStrategy.Table(ms.Name)
 	index := r.DataSourceProvider.ProvideTable(c, typ)
 
+	if _, err = query.ProjectFields(documentFields(ent)); err != nil {
+		return
+	}
+
+	q := *query
+	q.Filter = softDeleteFilter(ent, query.Filter, query.IncludeDeleted)
+
 	var docs []*search.Document
-	docs, extra, err = searchClient.List(c, query, index, typ)
+	docs, extra, err = searchClient.List(c, &q, index, typ)
 	if err != nil {
 		return
 	}
@@ -305,7 +352,14 @@ func (r *BaseRepository) Connection(c context.Context, query *entity.ConnectionQ
 	typ := breflect.TheNamingStrategy.Table(ms.Name)
 	index := r.DataSourceProvider.ProvideTable(c, typ)
 
-	conn, err := searchClient.Connection(c, query, index, typ)
+	if _, err = query.ProjectFields(documentFields(ent)); err != nil {
+		return nil, err
+	}
+
+	q := *query
+	q.Filter = softDeleteFilter(ent, query.Filter, query.IncludeDeleted)
+
+	conn, err := searchClient.Connection(c, &q, index, typ)
 	if err != nil {
 		return nil, err
 	}
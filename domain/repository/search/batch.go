@@ -0,0 +1,167 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+	breflect "github.com/duolacloud/microbase/reflect"
+
+	"github.com/thoas/go-funk"
+)
+
+// index 返回实体的 index 和 type, index 按 ProvideTable 区分租户
+func (r *BaseRepository) index(c context.Context, ent entity.Entity) (index string, typ string, err error) {
+	ms, err := breflect.GetStructInfo(ent, nil)
+	if err != nil {
+		return
+	}
+
+	typ = breflect.TheNamingStrategy.Table(ms.Name)
+	index = r.DataSourceProvider.ProvideTable(c, typ)
+	return
+}
+
+// BatchCreate 以 bulk create 写入, 已经存在的文档不覆盖, 对应的结果为 search.ErrExists
+func (r *BaseRepository) BatchCreate(c context.Context, ents []entity.Entity) ([]*repository.BatchResult, error) {
+	return r.batchUpsert(c, ents, true)
+}
+
+// BatchUpsert 有版本字段的实体逐条通过 Upsert 按版本号写入
+func (r *BaseRepository) BatchUpsert(c context.Context, ents []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ents))
+	rest, positions := repository.SplitVersioned(ents, results, func(ent entity.Entity) error {
+		_, err := r.Upsert(c, ent)
+		return err
+	})
+
+	restResults, err := r.batchUpsert(c, rest, false)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range positions {
+		results[i] = restResults[j]
+	}
+	return results, nil
+}
+
+func (r *BaseRepository) batchUpsert(c context.Context, ents []entity.Entity, create bool) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ents))
+	if len(ents) == 0 {
+		return results, nil
+	}
+
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []int
+	var docs []*search.Document
+	for i, ent := range ents {
+		if create {
+			if err := repository.InitVersion(ent); err != nil {
+				results[i].Error = err
+				continue
+			}
+		}
+
+		index, typ, err := r.index(c, ent)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		buf, err := json.Marshal(ent)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(buf, &fields); err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		positions = append(positions, i)
+		docs = append(docs, &search.Document{
+			Index:  index,
+			Type:   typ,
+			Fields: fields,
+		})
+	}
+
+	if len(docs) == 0 {
+		return results, nil
+	}
+
+	batch := searchClient.BatchUpsert
+	if create {
+		batch = searchClient.BatchCreate
+	}
+
+	errs, err := batch(c, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range positions {
+		results[i].Error = errs[j]
+	}
+	return results, nil
+}
+
+func (r *BaseRepository) BatchDelete(c context.Context, ents []entity.Entity) ([]*repository.BatchResult, error) {
+	results := repository.NewBatchResults(len(ents))
+	if len(ents) == 0 {
+		return results, nil
+	}
+
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []int
+	var keys []*search.DocumentKey
+	for i, ent := range ents {
+		index, typ, err := r.index(c, ent)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		// 命名约定，必须有 id 字段
+		id, ok := funk.Get(ent, "ID").(string)
+		if !ok {
+			results[i].Error = errors.New(fmt.Sprintf("no id field for entity %v", ent))
+			continue
+		}
+
+		positions = append(positions, i)
+		keys = append(keys, &search.DocumentKey{
+			Index: index,
+			Type:  typ,
+			Id:    id,
+		})
+	}
+
+	if len(keys) == 0 {
+		return results, nil
+	}
+
+	errs, err := searchClient.BatchDelete(c, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range positions {
+		results[i].Error = errs[j]
+	}
+	return results, nil
+}
@@ -0,0 +1,154 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+)
+
+func (r *BaseRepository) Count(c context.Context, ent entity.Entity, filter map[string]interface{}) (int64, error) {
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return 0, err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return 0, err
+	}
+
+	return searchClient.Count(c, softDeleteFilter(ent, filter, false), index, typ)
+}
+
+func (r *BaseRepository) Exists(c context.Context, ent entity.Entity, filter map[string]interface{}) (bool, error) {
+	count, err := r.Count(c, ent, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *BaseRepository) FindOne(c context.Context, ent entity.Entity, filter map[string]interface{}) error {
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return err
+	}
+
+	docs, _, err := searchClient.List(c, &entity.CursorQuery{
+		Filter: softDeleteFilter(ent, filter, false),
+		Size:   1,
+	}, index, typ)
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return repository.ErrNotFound
+	}
+
+	b, err := json.Marshal(docs[0].Fields)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, ent)
+}
+
+// UpdateMany change 按 json 序列化后的字段合并到匹配的文档中
+func (r *BaseRepository) UpdateMany(c context.Context, ent entity.Entity, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return 0, err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return 0, err
+	}
+
+	buf, err := json.Marshal(change)
+	if err != nil {
+		return 0, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return 0, err
+	}
+
+	// 有版本字段时同时把版本号加一, 持有旧版本号的写入会返回 ErrConflict
+	vf, err := repository.FindVersionField(ent)
+	if err != nil {
+		return 0, err
+	}
+	var increments []string
+	if vf != nil {
+		delete(fields, vf.JSONName())
+		increments = append(increments, vf.JSONName())
+	}
+
+	return searchClient.UpdateByQuery(c, softDeleteFilter(ent, filter, false), fields, index, typ, increments...)
+}
+
+func (r *BaseRepository) DeleteMany(c context.Context, ent entity.Entity, filter map[string]interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := repository.GuardFilter(filter, opts...); err != nil {
+		return 0, err
+	}
+
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return 0, err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return 0, err
+	}
+
+	// 和 Delete 一样, 有软删除字段时只更新字段
+	hasDeleteTime, hasDeleted := softDeleteFields(ent)
+	if hasDeleteTime || hasDeleted {
+		fields := make(map[string]interface{})
+		if hasDeleteTime {
+			fields[repository.SoftDeleteTimeField] = time.Now()
+		}
+		if hasDeleted {
+			fields[repository.SoftDeletedField] = true
+		}
+		return searchClient.UpdateByQuery(c, softDeleteFilter(ent, filter, false), fields, index, typ)
+	}
+
+	return searchClient.DeleteByQuery(c, filter, index, typ)
+}
+
+func (r *BaseRepository) Aggregate(c context.Context, ent entity.Entity, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	if err := query.Validate(documentFields(ent)); err != nil {
+		return nil, err
+	}
+
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return nil, err
+	}
+
+	q := *query
+	q.Filter = softDeleteFilter(ent, query.Filter, query.IncludeDeleted)
+	return searchClient.Aggregate(c, &q, index, typ)
+}
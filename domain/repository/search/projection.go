@@ -0,0 +1,47 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/duolacloud/microbase/domain/entity"
+)
+
+// documentFields 文档的字段, 文档按 json 序列化写入索引, 字段名为 json tag
+func documentFields(ent entity.Entity) entity.FilterFields {
+	fields := make(entity.FilterFields)
+	collectFields(reflect.TypeOf(ent).Elem(), fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, fields entity.FilterFields) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// 匿名结构体的字段展开到外层
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fields)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+
+	"github.com/thoas/go-funk"
+)
+
+// softDeleteFields 文档是否有软删除的字段, 和 gorm 的删除钩子使用相同的字段名
+func softDeleteFields(ent entity.Entity) (hasDeleteTime bool, hasDeleted bool) {
+	fields := documentFields(ent)
+	_, hasDeleteTime = fields[repository.SoftDeleteTimeField]
+	_, hasDeleted = fields[repository.SoftDeletedField]
+	return
+}
+
+// softDeleteFilter 在过滤条件上追加排除软删除文档的条件, 不修改传入的 filter
+func softDeleteFilter(ent entity.Entity, filter map[string]interface{}, includeDeleted bool) map[string]interface{} {
+	if includeDeleted {
+		return filter
+	}
+
+	var soft map[string]interface{}
+	hasDeleteTime, hasDeleted := softDeleteFields(ent)
+	if hasDeleteTime {
+		soft = map[string]interface{}{repository.SoftDeleteTimeField: nil}
+	} else if hasDeleted {
+		// 老的文档可能没有 deleted 字段, 不能用 EQ false
+		soft = map[string]interface{}{repository.SoftDeletedField: map[string]interface{}{"NE": true}}
+	} else {
+		return filter
+	}
+
+	if len(filter) == 0 {
+		return soft
+	}
+	return map[string]interface{}{"AND": []interface{}{filter, soft}}
+}
+
+// isSoftDeleted 文档是否已经被软删除
+func isSoftDeleted(ent entity.Entity, doc *search.Document) bool {
+	hasDeleteTime, hasDeleted := softDeleteFields(ent)
+	if hasDeleteTime {
+		return doc.Fields[repository.SoftDeleteTimeField] != nil
+	}
+	if hasDeleted {
+		deleted, _ := doc.Fields[repository.SoftDeletedField].(bool)
+		return deleted
+	}
+	return false
+}
+
+func documentID(ent entity.Entity) (string, error) {
+	// 命名约定，必须有 id 字段
+	id, ok := funk.Get(ent, "ID").(string)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("no id field for entity %v", ent))
+	}
+	return id, nil
+}
+
+// softDelete 文档有软删除字段时只更新字段, 返回 false 表示需要删除文档
+func (r *BaseRepository) softDelete(c context.Context, searchClient search.SearchClient, ent entity.Entity, index, typ, id string) (bool, error) {
+	hasDeleteTime, hasDeleted := softDeleteFields(ent)
+	if !hasDeleteTime && !hasDeleted {
+		return false, nil
+	}
+
+	fields := map[string]interface{}{"id": id}
+	if hasDeleteTime {
+		fields[repository.SoftDeleteTimeField] = time.Now()
+	}
+	if hasDeleted {
+		fields[repository.SoftDeletedField] = true
+	}
+
+	return true, searchClient.Update(c, &search.Document{
+		Index:  index,
+		Type:   typ,
+		Fields: fields,
+	})
+}
+
+func (r *BaseRepository) Restore(c context.Context, ent entity.Entity) error {
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return err
+	}
+
+	id, err := documentID(ent)
+	if err != nil {
+		return err
+	}
+
+	hasDeleteTime, hasDeleted := softDeleteFields(ent)
+	if !hasDeleteTime && !hasDeleted {
+		return repository.ErrNotSoftDeletable
+	}
+
+	doc, err := searchClient.Get(c, index, typ, id)
+	if err != nil {
+		return err
+	}
+	if doc == nil || !isSoftDeleted(ent, doc) {
+		return repository.ErrNotFound
+	}
+
+	fields := map[string]interface{}{"id": id}
+	if hasDeleteTime {
+		fields[repository.SoftDeleteTimeField] = nil
+	}
+	if hasDeleted {
+		fields[repository.SoftDeletedField] = false
+	}
+
+	return searchClient.Update(c, &search.Document{
+		Index:  index,
+		Type:   typ,
+		Fields: fields,
+	})
+}
+
+func (r *BaseRepository) HardDelete(c context.Context, ent entity.Entity) error {
+	searchClient, err := r.Client(c)
+	if err != nil {
+		return err
+	}
+
+	index, typ, err := r.index(c, ent)
+	if err != nil {
+		return err
+	}
+
+	id, err := documentID(ent)
+	if err != nil {
+		return err
+	}
+
+	return searchClient.Delete(c, index, typ, id)
+}
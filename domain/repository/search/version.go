@@ -0,0 +1,74 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/duolacloud/microbase/client/search"
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository"
+)
+
+// writeVersion 按版本号写入文档, 先读取文档比较版本号, 再带上读到的 seq_no 和 primary_term 写入,
+// 期间文档被其它请求修改时 es 返回冲突; upsert 为 true 时版本号为 0 表示新建
+func (r *BaseRepository) writeVersion(c context.Context, searchClient search.SearchClient, ent entity.Entity, vf *repository.VersionField, doc *search.Document, upsert bool) error {
+	id, err := documentID(ent)
+	if err != nil {
+		return err
+	}
+
+	current, err := searchClient.Get(c, doc.Index, doc.Type, id)
+	if err != nil {
+		return err
+	}
+
+	version := vf.Get(ent)
+	if current == nil {
+		if !upsert || version != 0 {
+			return repository.ErrConflict
+		}
+	} else {
+		if upsert && version == 0 {
+			return repository.ErrConflict
+		}
+		if documentVersion(current.Fields[vf.JSONName()]) != version {
+			return repository.ErrConflict
+		}
+		doc.SeqNo = current.SeqNo
+		doc.PrimaryTerm = current.PrimaryTerm
+	}
+
+	doc.Fields[vf.JSONName()] = version + 1
+
+	if upsert {
+		err = searchClient.Upsert(c, doc)
+	} else {
+		err = searchClient.Update(c, doc)
+	}
+	if err == search.ErrConflict {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	vf.Set(ent, version+1)
+	return nil
+}
+
+// documentVersion 文档中的版本号, 按 json 反序列化后为 float64, 没有版本号的文档视为 0
+func documentVersion(v interface{}) int64 {
+	switch i := v.(type) {
+	case float64:
+		return int64(i)
+	case int64:
+		return i
+	case int:
+		return int64(i)
+	case json.Number:
+		n, _ := i.Int64()
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "errors"
+
+// 软删除字段, 和 datasource/gorm 的删除钩子一致, dtime 为删除时间, deleted 为删除标记,
+// 实体有其中任意一个字段时 Delete 为软删除, 查询默认排除已删除的记录
+const (
+	SoftDeleteTimeField = "dtime"
+	SoftDeletedField    = "deleted"
+)
+
+// ErrNotSoftDeletable 实体没有软删除字段, 无法恢复
+var ErrNotSoftDeletable = errors.New("entity does not support soft delete")
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/duolacloud/microbase/domain/entity"
+)
+
+// 乐观锁的版本字段通过 tag 开启, 字段必须为整数, 例如
+//
+//	Version int64 `json:"version" gorm:"column:version" bson:"version" repo:"version"`
+//
+// Update 和 Upsert 只更新版本号与实体一致的记录, 写入后版本号加一, 没有匹配的记录时返回 ErrConflict
+const (
+	VersionTagKey   = TagKey
+	VersionTagValue = "version"
+)
+
+// ErrConflict 版本号不一致, 记录已经被其它请求修改
+var ErrConflict = errors.New("version conflict")
+
+// VersionField 实体的版本字段
+type VersionField struct {
+	reflect.StructField
+}
+
+// FindVersionField 查找实体的版本字段, 包括匿名嵌入的结构体, 没有版本字段时返回 nil
+func FindVersionField(m interface{}) (*VersionField, error) {
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	field, ok := findVersionField(t, nil)
+	if !ok {
+		return nil, nil
+	}
+
+	switch field.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &VersionField{field}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("version field %s must be an integer, got %s", field.Name, field.Type))
+	}
+}
+
+func findVersionField(t reflect.Type, index []int) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		field.Index = append(append([]int{}, index...), i)
+
+		if field.Tag.Get(VersionTagKey) == VersionTagValue {
+			return field, true
+		}
+
+		// 嵌入的指针可能为 nil, 只查找值类型的匿名结构体
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if f, ok := findVersionField(field.Type, field.Index); ok {
+				return f, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Get 实体当前的版本号
+func (f *VersionField) Get(m interface{}) int64 {
+	v := reflect.Indirect(reflect.ValueOf(m)).FieldByIndex(f.Index)
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	default:
+		return v.Int()
+	}
+}
+
+// Set 写入成功后更新实体的版本号
+func (f *VersionField) Set(m interface{}, version int64) {
+	v := reflect.Indirect(reflect.ValueOf(m)).FieldByIndex(f.Index)
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(version))
+	default:
+		v.SetInt(version)
+	}
+}
+
+// JSONName 字段按 json 序列化后的名称, search 的文档使用
+func (f *VersionField) JSONName() string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+// InitVersion 新建的实体版本号为 0 时从 1 开始
+func InitVersion(m interface{}) error {
+	vf, err := FindVersionField(m)
+	if err != nil || vf == nil {
+		return err
+	}
+
+	if vf.Get(m) == 0 {
+		vf.Set(m, 1)
+	}
+	return nil
+}
+
+// SplitVersioned 批量写入无法按版本号逐条比较, 有版本字段的实体交给 upsert 逐条写入,
+// 结果写入 results, 返回其余的实体和它们在 ms 中的位置
+func SplitVersioned(ms []entity.Entity, results []*BatchResult, upsert func(m entity.Entity) error) ([]entity.Entity, []int) {
+	var rest []entity.Entity
+	var positions []int
+	for i, m := range ms {
+		vf, err := FindVersionField(m)
+		switch {
+		case err != nil:
+			results[i].Error = err
+		case vf != nil:
+			results[i].Error = upsert(m)
+		default:
+			rest = append(rest, m)
+			positions = append(positions, i)
+		}
+	}
+	return rest, positions
+}
@@ -0,0 +1,43 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/duolacloud/microbase/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type Versioned struct {
+	Version uint32 `json:"ver" repo:"version"`
+}
+
+type Account struct {
+	ID string `json:"id"`
+	Versioned
+}
+
+func TestVersionField(t *testing.T) {
+	account := &Account{ID: "1"}
+
+	vf, err := repository.FindVersionField(account)
+	assert.NoError(t, err)
+	if assert.NotNil(t, vf) {
+		assert.Equal(t, "Version", vf.Name)
+		assert.Equal(t, "ver", vf.JSONName())
+	}
+
+	assert.NoError(t, repository.InitVersion(account))
+	assert.Equal(t, uint32(1), account.Version)
+
+	vf.Set(account, vf.Get(account)+1)
+	assert.Equal(t, uint32(2), account.Version)
+
+	vf, err = repository.FindVersionField(&struct{ ID string }{})
+	assert.NoError(t, err)
+	assert.Nil(t, vf)
+
+	_, err = repository.FindVersionField(&struct {
+		Version string `repo:"version"`
+	}{})
+	assert.Error(t, err)
+}
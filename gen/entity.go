@@ -0,0 +1,369 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 字段的 gen tag, gen:"-" 不生成任何代码, gen:"nofilter" 不允许出现在过滤条件中
+const (
+	TagKey        = "gen"
+	SkipValue     = "-"
+	NoFilterValue = "nofilter"
+)
+
+// Field 实体中映射到表的字段, 匿名结构体的字段已经展开
+type Field struct {
+	// Name Go 字段名, Type 字段类型的源码, 例如 time.Time
+	Name string
+	Type string
+	// Key 过滤条件中的字段名, 取自 Config.Tag 指定的 tag
+	Key string
+	// JSON json 字段名, 用于和 proto 字段对应
+	JSON string
+
+	Primary  bool
+	Unique   bool
+	Index    bool
+	NoFilter bool
+
+	expr ast.Expr
+}
+
+// Entity 实现了 entity.Entity 的结构体
+type Entity struct {
+	Name    string
+	Fields  []*Field
+	Primary []*Field
+
+	// imports 字段类型引用的包, 包名到导入路径
+	imports map[string]string
+}
+
+// Finders 生成 FindByX 的字段, 主键以外有唯一索引或者普通索引的字段
+func (e *Entity) Finders() []*Field {
+	var fields []*Field
+	for _, f := range e.Fields {
+		if !f.Primary && (f.Unique || f.Index) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+type source struct {
+	pkg     string
+	structs map[string]*ast.StructType
+	order   []string
+	// uniques 声明了 Unique 方法的类型
+	uniques map[string]bool
+	// imports 结构体所在文件的导入, 包名到导入路径
+	imports map[string]map[string]string
+	// basics 底层类型为内置类型的类型声明, 例如 proto 的枚举
+	basics map[string]string
+}
+
+// parsePackage 解析目录中的 go 文件, 忽略测试和生成的文件
+func parsePackage(dir string) (*source, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && !strings.HasSuffix(name, ".gen.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, errors.New(fmt.Sprintf("%s: expect one package, found %d", dir, len(pkgs)))
+	}
+
+	src := &source{
+		structs: map[string]*ast.StructType{},
+		uniques: map[string]bool{},
+		imports: map[string]map[string]string{},
+		basics:  map[string]string{},
+	}
+	for name, pkg := range pkgs {
+		src.pkg = name
+
+		files := make([]string, 0, len(pkg.Files))
+		for filename := range pkg.Files {
+			files = append(files, filename)
+		}
+		sort.Strings(files)
+
+		for _, filename := range files {
+			src.collect(pkg.Files[filename])
+		}
+	}
+	return src, nil
+}
+
+// parseDir 解析目录中的实体, names 为空时返回全部声明了 Unique 方法的结构体
+func parseDir(dir, tag string, names []string) (*source, []*Entity, error) {
+	src, err := parsePackage(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(names) == 0 {
+		for _, name := range src.order {
+			if src.uniques[name] {
+				names = append(names, name)
+			}
+		}
+	}
+
+	entities := make([]*Entity, 0, len(names))
+	for _, name := range names {
+		if _, ok := src.structs[name]; !ok {
+			return nil, nil, errors.New(fmt.Sprintf("%s: struct %s not found", dir, name))
+		}
+
+		e := &Entity{
+			Name:    name,
+			imports: map[string]string{},
+		}
+		src.fields(e, name, tag)
+		if len(e.Fields) == 0 {
+			return nil, nil, errors.New(fmt.Sprintf("%s: struct %s has no fields", dir, name))
+		}
+
+		// 没有声明主键时与 gorm 一致, 使用 ID 字段
+		if len(e.Primary) == 0 {
+			for _, f := range e.Fields {
+				if f.Name == "ID" {
+					f.Primary = true
+					e.Primary = append(e.Primary, f)
+				}
+			}
+		}
+		entities = append(entities, e)
+	}
+	return src, entities, nil
+}
+
+func (s *source) collect(file *ast.File) {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			imports[spec.Name.Name] = p
+		} else {
+			imports[packageName(p)] = p
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					s.structs[ts.Name.Name] = t
+					s.order = append(s.order, ts.Name.Name)
+					s.imports[ts.Name.Name] = imports
+				case *ast.Ident:
+					if types.Universe.Lookup(t.Name) != nil {
+						s.basics[ts.Name.Name] = t.Name
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil || d.Name.Name != "Unique" || len(d.Recv.List) == 0 {
+				continue
+			}
+			typ := d.Recv.List[0].Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if ident, ok := typ.(*ast.Ident); ok {
+				s.uniques[ident.Name] = true
+			}
+		}
+	}
+}
+
+// fields 展开同一个包中的匿名结构体, 其它包的匿名字段不映射到表, 例如 outbox.Events
+func (s *source) fields(e *Entity, name, tag string) {
+	imports := s.imports[name]
+
+	for _, field := range s.structs[name].Fields.List {
+		var tags reflect.StructTag
+		if field.Tag != nil {
+			v, _ := strconv.Unquote(field.Tag.Value)
+			tags = reflect.StructTag(v)
+		}
+
+		settings := strings.Split(tags.Get(TagKey), ",")
+		if settings[0] == SkipValue {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if ident, ok := typ.(*ast.Ident); ok {
+				if _, ok := s.structs[ident.Name]; ok {
+					s.fields(e, ident.Name, tag)
+				}
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			f := &Field{
+				Name: ident.Name,
+				Type: exprString(field.Type),
+				Key:  tagName(tags, tag, ident.Name),
+				JSON: tagName(tags, "json", ident.Name),
+				expr: field.Type,
+			}
+			if f.Key == "-" {
+				continue
+			}
+			for _, v := range settings[1:] {
+				if strings.TrimSpace(v) == NoFilterValue {
+					f.NoFilter = true
+				}
+			}
+			if settings[0] == NoFilterValue {
+				f.NoFilter = true
+			}
+
+			gorm := gormSettings(tags.Get("gorm"))
+			// gorm 忽略的字段不是表的列, 不能用于过滤
+			if _, ok := gorm["-"]; ok {
+				f.NoFilter = true
+			}
+			_, f.Primary = gorm["PRIMARY_KEY"]
+			if f.Key == "_id" {
+				f.Primary = true
+			}
+			_, unique := gorm["UNIQUE"]
+			_, uniqueIndex := gorm["UNIQUE_INDEX"]
+			f.Unique = unique || uniqueIndex
+			_, f.Index = gorm["INDEX"]
+
+			for pkg := range packages(field.Type) {
+				if p, ok := imports[pkg]; ok {
+					e.imports[pkg] = p
+				}
+			}
+
+			e.Fields = append(e.Fields, f)
+			if f.Primary {
+				e.Primary = append(e.Primary, f)
+			}
+		}
+	}
+}
+
+// tagName 与 encoding/json 一致, 没有 tag 时使用字段名
+func tagName(tags reflect.StructTag, key, name string) string {
+	v := strings.Split(tags.Get(key), ",")[0]
+	if v == "" {
+		return name
+	}
+	return v
+}
+
+// gormSettings 与 gorm 解析 tag 的方式一致, key 为大写
+func gormSettings(tag string) map[string]string {
+	settings := map[string]string{}
+	for _, item := range strings.Split(tag, ";") {
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, ":", 2)
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if len(kv) == 2 {
+			settings[key] = kv[1]
+		} else {
+			settings[key] = key
+		}
+	}
+	return settings
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// packageName 导入路径的默认包名, 忽略 /v2 这样的版本后缀
+func packageName(p string) string {
+	name := path.Base(p)
+	if versionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(p))
+	}
+	return strings.Replace(name, "-", "_", -1)
+}
+
+func exprString(expr ast.Expr) string {
+	var b strings.Builder
+	_ = printer.Fprint(&b, token.NewFileSet(), expr)
+	return b.String()
+}
+
+// packages 类型中引用的包名
+func packages(expr ast.Expr) map[string]bool {
+	pkgs := map[string]bool{}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				pkgs[ident.Name] = true
+			}
+			return false
+		}
+		return true
+	})
+	return pkgs
+}
+
+// qualify 生成的代码在其它包时, 给实体包中声明的类型加上包名
+func qualify(expr ast.Expr, pkg string) string {
+	if pkg == "" {
+		return exprString(expr)
+	}
+
+	var rewrite func(ast.Expr) ast.Expr
+	rewrite = func(expr ast.Expr) ast.Expr {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			if types.Universe.Lookup(e.Name) != nil {
+				return e
+			}
+			return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: e}
+		case *ast.StarExpr:
+			return &ast.StarExpr{X: rewrite(e.X)}
+		case *ast.ArrayType:
+			return &ast.ArrayType{Len: e.Len, Elt: rewrite(e.Elt)}
+		case *ast.MapType:
+			return &ast.MapType{Key: rewrite(e.Key), Value: rewrite(e.Value)}
+		case *ast.ChanType:
+			return &ast.ChanType{Dir: e.Dir, Value: rewrite(e.Value)}
+		}
+		return expr
+	}
+	return exprString(rewrite(expr))
+}
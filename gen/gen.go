@@ -0,0 +1,251 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	breflect "github.com/duolacloud/microbase/reflect"
+)
+
+type Config struct {
+	// Dir 实体所在的目录
+	Dir string
+	// Types 生成代码的实体, 为空时为目录中全部声明了 Unique 方法的结构体
+	Types []string
+	// Tag 过滤条件中的字段名使用的 tag, gorm 和 search 仓储为 json, mongo 仓储为 bson
+	Tag string
+
+	// Out 输出目录, 为空时与 Dir 相同, Package 为输出的包名, 输出到其它包时
+	// EntityImport 为实体包的导入路径
+	Out          string
+	Package      string
+	EntityImport string
+
+	// ProtoImport protoc-gen-go 生成的包的导入路径, ProtoDir 为其所在目录,
+	// 为空时不生成 proto 转换, message 与实体同名, 没有同名 message 的实体跳过
+	ProtoImport string
+	ProtoDir    string
+}
+
+// reserved 生成的代码引用的包名
+var reserved = map[string]bool{
+	"context":     true,
+	"reflect":     true,
+	"entity":      true,
+	"repository":  true,
+	"pagination":  true,
+	"timestamppb": true,
+	ProtoAlias:    true,
+}
+
+// File 一个实体生成的代码
+type File struct {
+	Name   string
+	Source []byte
+}
+
+type data struct {
+	Package string
+	// Std 标准库, Imports 其它包, 分两组导入
+	Std     []*imported
+	Imports []*imported
+
+	Entity  *Entity
+	Type    string
+	Filters []*Field
+	Message *Message
+}
+
+type imported struct {
+	Alias string
+	Path  string
+}
+
+// Generate 为每个实体生成 <表名>.gen.go, 包含过滤字段白名单、类型化仓储和 proto 转换
+func Generate(cfg *Config) ([]*File, error) {
+	if cfg.Tag == "" {
+		cfg.Tag = "json"
+	}
+
+	src, entities, err := parseDir(cfg.Dir, cfg.Tag, cfg.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := cfg.Package
+	if pkg == "" {
+		pkg = src.pkg
+	}
+
+	var entityPkg string
+	if cfg.EntityImport != "" && pkg != src.pkg {
+		entityPkg = packageName(cfg.EntityImport)
+		// 实体包通常也叫 entity, 与生成的代码引用的包重名时换一个别名
+		if reserved[entityPkg] {
+			entityPkg = "model"
+		}
+	}
+
+	var pb *source
+	if cfg.ProtoImport != "" {
+		if cfg.ProtoDir == "" {
+			return nil, errors.New("proto dir is required with proto import")
+		}
+		if pb, err = parsePackage(cfg.ProtoDir); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make([]*File, 0, len(entities))
+	for _, e := range entities {
+		d := &data{
+			Package: pkg,
+			Entity:  e,
+			Type:    e.Name,
+		}
+		if entityPkg != "" {
+			d.Type = entityPkg + "." + e.Name
+		}
+
+		imports := map[string]string{
+			"context":    "context",
+			"entity":     "github.com/duolacloud/microbase/domain/entity",
+			"repository": "github.com/duolacloud/microbase/domain/repository",
+		}
+		if entityPkg != "" {
+			imports[entityPkg] = cfg.EntityImport
+		}
+
+		for _, f := range e.Fields {
+			f.Type = qualify(f.expr, entityPkg)
+			if !f.NoFilter {
+				d.Filters = append(d.Filters, f)
+			}
+		}
+		if len(d.Filters) > 0 {
+			imports["reflect"] = "reflect"
+		}
+		// 只有过滤字段和 FindByX 的参数会引用字段类型的包
+		for _, f := range append(d.Filters, e.Finders()...) {
+			for name := range packages(f.expr) {
+				if p, ok := e.imports[name]; ok {
+					imports[name] = p
+				}
+			}
+		}
+		for _, f := range e.Primary {
+			for name := range packages(f.expr) {
+				if p, ok := e.imports[name]; ok {
+					imports[name] = p
+				}
+			}
+		}
+
+		if pb != nil {
+			if msg, ok := message(e, src, pb, entityPkg); ok {
+				d.Message = msg
+				imports[ProtoAlias] = cfg.ProtoImport
+				imports["pagination"] = "github.com/duolacloud/microbase/proto/pagination"
+				if msg.Timestamp {
+					imports["timestamppb"] = "google.golang.org/protobuf/types/known/timestamppb"
+				}
+			}
+		}
+
+		for alias, p := range imports {
+			if alias == packageName(p) {
+				alias = ""
+			}
+			if strings.Contains(strings.Split(p, "/")[0], ".") {
+				d.Imports = append(d.Imports, &imported{Alias: alias, Path: p})
+			} else {
+				d.Std = append(d.Std, &imported{Alias: alias, Path: p})
+			}
+		}
+		for _, list := range [][]*imported{d.Std, d.Imports} {
+			sort.Slice(list, func(i, j int) bool {
+				return list[i].Path < list[j].Path
+			})
+		}
+
+		var b bytes.Buffer
+		if err = repositoryTemplate.Execute(&b, d); err != nil {
+			return nil, err
+		}
+
+		source, err := format.Source(b.Bytes())
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("format %s error: %v", e.Name, err))
+		}
+
+		files = append(files, &File{
+			Name:   breflect.TheNamingStrategy.Table(e.Name) + ".gen.go",
+			Source: source,
+		})
+	}
+	return files, nil
+}
+
+// Write 生成并写入 Out 目录, 返回写入的文件
+func Write(cfg *Config) ([]string, error) {
+	files, err := Generate(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	out := cfg.Out
+	if out == "" {
+		out = cfg.Dir
+	}
+
+	var written []string
+	for _, f := range files {
+		filename := filepath.Join(out, f.Name)
+		if err = ioutil.WriteFile(filename, f.Source, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, filename)
+	}
+	return written, nil
+}
+
+// lowerFirst 生成的未导出名称
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	// 开头的缩写整体转换为小写, 例如 ID 转换为 id, URLPath 转换为 urlPath
+	runes := []rune(s)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		n--
+	}
+	for i := 0; i < n || i == 0; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	name := string(runes)
+	if token.Lookup(name).IsKeyword() {
+		name += "_"
+	}
+	return name
+}
+
+var funcs = template.FuncMap{
+	"lower": lowerFirst,
+	"join":  strings.Join,
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}
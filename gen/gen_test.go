@@ -0,0 +1,140 @@
+package gen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testImporter testdata 中的包按 pkgs 中的导入路径导入, 其它包从源码导入
+type testImporter struct {
+	types.ImporterFrom
+	pkgs map[string]*types.Package
+}
+
+func (im *testImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if pkg, ok := im.pkgs[path]; ok {
+		return pkg, nil
+	}
+	return im.ImporterFrom.ImportFrom(path, dir, mode)
+}
+
+// checkPackage 对 dir 中的 go 文件和生成的文件做类型检查, 生成的文件不写入 dir, dir 为空时只检查生成的文件
+func checkPackage(fset *token.FileSet, im *testImporter, path, dir string, generated ...*File) error {
+	var files []*ast.File
+	if dir != "" {
+		pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range pkgs {
+			for _, f := range pkg.Files {
+				files = append(files, f)
+			}
+		}
+	}
+	// 从源码导入时按文件所在的目录查找模块
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for _, g := range generated {
+		f, err := parser.ParseFile(fset, filepath.Join(abs, g.Name), g.Source, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	pkg, err := (&types.Config{Importer: im}).Check(path, fset, files, nil)
+	if err != nil {
+		return err
+	}
+	im.pkgs[path] = pkg
+	return nil
+}
+
+func newTestImporter(fset *token.FileSet) *testImporter {
+	return &testImporter{
+		ImporterFrom: importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		pkgs:         map[string]*types.Package{},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := Generate(&Config{
+		Dir:         "testdata/model",
+		ProtoImport: "example.com/user/proto/user",
+		ProtoDir:    "testdata/pb",
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, files, 1) {
+		return
+	}
+	assert.Equal(t, "users.gen.go", files[0].Name)
+
+	source := string(files[0].Source)
+	// 白名单不包含 gen:"nofilter", gorm:"-" 和 json:"-" 的字段, 匿名结构体的字段展开
+	assert.Contains(t, source, `"status": reflect.TypeOf((*Status)(nil)).Elem(),`)
+	assert.Contains(t, source, `"ctime":  reflect.TypeOf((*time.Time)(nil)).Elem(),`)
+	assert.NotContains(t, source, `"secret":`)
+	assert.NotContains(t, source, `"tags":`)
+	assert.NotContains(t, source, "Password")
+
+	assert.Contains(t, source, "Get(c context.Context, id int64) (*User, error)")
+	assert.Contains(t, source, "FindByName(c context.Context, name string) (*User, error)")
+	assert.Contains(t, source, "FindByAge(c context.Context, age int, query *entity.ConnectionQuery) (*UserConnection, error)")
+
+	assert.Contains(t, source, "o.Age = int32(m.Age)")
+	assert.Contains(t, source, "o.Status = pb.User_Status(m.Status)")
+	assert.Contains(t, source, "o.Ctime = timestamppb.New(m.CreateTime)")
+	assert.Contains(t, source, "m.DeleteTime = &t")
+	assert.Contains(t, source, "需要手写转换: Secret")
+
+	// 生成的代码和实体在同一个包中, 能通过类型检查
+	fset := token.NewFileSet()
+	im := newTestImporter(fset)
+	if assert.NoError(t, checkPackage(fset, im, "example.com/user/proto/user", "testdata/pb")) {
+		assert.NoError(t, checkPackage(fset, im, "example.com/user/model", "testdata/model", files[0]))
+	}
+}
+
+func TestGenerateOtherPackage(t *testing.T) {
+	files, err := Generate(&Config{
+		Dir:          "testdata/model",
+		Types:        []string{"User"},
+		Package:      "repository",
+		EntityImport: "example.com/user/domain/entity",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	source := string(files[0].Source)
+	assert.Contains(t, source, `model "example.com/user/domain/entity"`)
+	assert.Contains(t, source, "reflect.TypeOf((*model.Status)(nil))")
+	assert.Contains(t, source, "m := &model.User{}")
+	assert.NotContains(t, source, "ToPB")
+
+	// 实体包按 EntityImport 导入, 生成的代码单独成包
+	fset := token.NewFileSet()
+	im := newTestImporter(fset)
+	if assert.NoError(t, checkPackage(fset, im, "example.com/user/domain/entity", "testdata/model")) {
+		assert.NoError(t, checkPackage(fset, im, "example.com/user/domain/repository", "", files[0]))
+	}
+
+	_, err = Generate(&Config{Dir: "testdata/model", Types: []string{"Order"}})
+	assert.Error(t, err)
+}
+
+func TestLowerFirst(t *testing.T) {
+	assert.Equal(t, "id", lowerFirst("ID"))
+	assert.Equal(t, "urlPath", lowerFirst("URLPath"))
+	assert.Equal(t, "name", lowerFirst("Name"))
+	assert.Equal(t, "type_", lowerFirst("Type"))
+}
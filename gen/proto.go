@@ -0,0 +1,243 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ProtoAlias 生成的代码中 proto 包的别名
+const ProtoAlias = "pb"
+
+var timestampPackages = map[string]bool{
+	"github.com/golang/protobuf/ptypes/timestamp":        true,
+	"google.golang.org/protobuf/types/known/timestamppb": true,
+}
+
+// Mapping 一个字段的转换语句, 实体变量为 m, proto 变量为 o
+type Mapping struct {
+	ToPB   string
+	FromPB string
+}
+
+// Message 实体对应的 proto message
+type Message struct {
+	Name     string
+	Mappings []*Mapping
+	// Unmapped 没有对应的 proto 字段或者类型无法自动转换的实体字段, 需要手写转换
+	Unmapped []string
+	// Timestamp 转换中用到了 timestamppb
+	Timestamp bool
+}
+
+type protoField struct {
+	name string
+	json string
+	expr ast.Expr
+}
+
+// protoFields protoc-gen-go 生成的结构体的字段, 忽略内部字段
+func (s *source) protoFields(name string) ([]*protoField, bool) {
+	st, ok := s.structs[name]
+	if !ok {
+		return nil, false
+	}
+
+	var fields []*protoField
+	for _, field := range st.Fields.List {
+		var tags reflect.StructTag
+		if field.Tag != nil {
+			v, _ := strconv.Unquote(field.Tag.Value)
+			tags = reflect.StructTag(v)
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() || strings.HasPrefix(ident.Name, "XXX_") {
+				continue
+			}
+			fields = append(fields, &protoField{
+				name: ident.Name,
+				json: tagName(tags, "json", ident.Name),
+				expr: field.Type,
+			})
+		}
+	}
+	return fields, true
+}
+
+// message 按字段名或者 json 名和 proto 字段对应, proto 的 json tag 为 .proto 文件中的字段名
+func message(e *Entity, src, pb *source, entityPkg string) (*Message, bool) {
+	fields, ok := pb.protoFields(e.Name)
+	if !ok {
+		return nil, false
+	}
+
+	msg := &Message{Name: e.Name}
+	for _, f := range e.Fields {
+		var matched *protoField
+		for _, p := range fields {
+			if p.name == f.Name {
+				matched = p
+				break
+			}
+		}
+		if matched == nil {
+			for _, p := range fields {
+				if p.json == f.JSON {
+					matched = p
+					break
+				}
+			}
+		}
+		if matched == nil {
+			msg.Unmapped = append(msg.Unmapped, f.Name)
+			continue
+		}
+
+		mapping, timestamp := convert(f, matched, src, pb, e.imports, entityPkg)
+		if mapping == nil {
+			msg.Unmapped = append(msg.Unmapped, f.Name)
+			continue
+		}
+		msg.Mappings = append(msg.Mappings, mapping)
+		msg.Timestamp = msg.Timestamp || timestamp
+	}
+	return msg, true
+}
+
+// convert 相同类型直接赋值, 数字和字符串类型 (包括枚举) 之间强制转换, time.Time 和 Timestamp 互相转换
+func convert(f *Field, p *protoField, src, pb *source, imports map[string]string, entityPkg string) (*Mapping, bool) {
+	et := qualify(f.expr, entityPkg)
+	pt := qualify(p.expr, ProtoAlias)
+	to := fmt.Sprintf("o.%s", p.name)
+	from := fmt.Sprintf("m.%s", f.Name)
+
+	if isTimestamp(p.expr, pb) {
+		switch {
+		case isTime(f.expr, imports):
+			return &Mapping{
+				ToPB:   fmt.Sprintf("%s = timestamppb.New(%s)", to, from),
+				FromPB: fmt.Sprintf("if %s != nil {\n%s = %s.AsTime()\n}", to, from, to),
+			}, true
+		case isTimePtr(f.expr, imports):
+			return &Mapping{
+				ToPB:   fmt.Sprintf("if %s != nil {\n%s = timestamppb.New(*%s)\n}", from, to, from),
+				FromPB: fmt.Sprintf("if %s != nil {\nt := %s.AsTime()\n%s = &t\n}", to, to, from),
+			}, true
+		}
+		return nil, false
+	}
+
+	eb, pbb := src.basic(f.expr), pb.basic(p.expr)
+	if eb != "" && pbb != "" {
+		if et == pt {
+			return &Mapping{
+				ToPB:   fmt.Sprintf("%s = %s", to, from),
+				FromPB: fmt.Sprintf("%s = %s", from, to),
+			}, false
+		}
+		if kind(eb) != "" && kind(eb) == kind(pbb) {
+			return &Mapping{
+				ToPB:   fmt.Sprintf("%s = %s(%s)", to, pt, from),
+				FromPB: fmt.Sprintf("%s = %s(%s)", from, et, to),
+			}, false
+		}
+		return nil, false
+	}
+
+	// 其它类型只有在两边完全相同并且不引用各自包中的类型时才能直接赋值, 例如 []string
+	if et == pt && universal(f.expr) {
+		return &Mapping{
+			ToPB:   fmt.Sprintf("%s = %s", to, from),
+			FromPB: fmt.Sprintf("%s = %s", from, to),
+		}, false
+	}
+	return nil, false
+}
+
+// basic 内置类型或者底层为内置类型的类型声明的底层类型
+func (s *source) basic(expr ast.Expr) string {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if obj := types.Universe.Lookup(ident.Name); obj != nil {
+		if _, ok := obj.(*types.TypeName); ok {
+			return ident.Name
+		}
+		return ""
+	}
+	return s.basics[ident.Name]
+}
+
+// kind 可以互相转换的内置类型
+func kind(basic string) string {
+	switch basic {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "byte", "rune":
+		return "number"
+	case "string":
+		return "string"
+	case "bool":
+		return "bool"
+	}
+	return ""
+}
+
+func universal(expr ast.Expr) bool {
+	ok := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.SelectorExpr:
+			ok = false
+		case *ast.Ident:
+			if types.Universe.Lookup(e.Name) == nil {
+				ok = false
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+func selector(expr ast.Expr, imports map[string]string, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && imports[ident.Name] == pkg
+}
+
+func isTime(expr ast.Expr, imports map[string]string) bool {
+	return selector(expr, imports, "time", "Time")
+}
+
+func isTimePtr(expr ast.Expr, imports map[string]string) bool {
+	star, ok := expr.(*ast.StarExpr)
+	return ok && isTime(star.X, imports)
+}
+
+func isTimestamp(expr ast.Expr, pb *source) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Timestamp" {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	for _, imports := range pb.imports {
+		if timestampPackages[imports[ident.Name]] {
+			return true
+		}
+	}
+	return false
+}
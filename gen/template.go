@@ -0,0 +1,350 @@
+package gen
+
+import "text/template"
+
+var repositoryTemplate = template.Must(template.New("repository").Funcs(funcs).Parse(`// Code generated by microbase gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Std}}
+	{{if .Alias}}{{.Alias}} {{end}}{{quote .Path}}
+{{- end}}
+{{if .Imports}}
+{{- range .Imports}}
+	{{if .Alias}}{{.Alias}} {{end}}{{quote .Path}}
+{{- end}}
+{{- end}}
+)
+
+{{- $name := .Entity.Name}}
+{{- $type := .Type}}
+{{- $impl := printf "%sRepository" (lower $name)}}
+
+// {{$name}}FilterFields {{$name}} 的过滤条件中允许使用的字段
+var {{$name}}FilterFields = entity.FilterFields{
+{{- range .Filters}}
+	{{quote .Key}}: reflect.TypeOf((*{{.Type}})(nil)).Elem(),
+{{- end}}
+}
+
+type {{$name}}Edge struct {
+	Cursor string
+	Node   *{{$type}}
+}
+
+type {{$name}}Connection struct {
+	Total    int64
+	Edges    []*{{$name}}Edge
+	PageInfo entity.PageInfo
+}
+
+func (c *{{$name}}Connection) Nodes() []*{{$type}} {
+	nodes := make([]*{{$type}}, len(c.Edges))
+	for i, edge := range c.Edges {
+		nodes[i] = edge.Node
+	}
+	return nodes
+}
+
+// {{$name}}Repository {{$name}} 的类型化仓储, 带过滤条件的方法先按 {{$name}}FilterFields 校验字段
+type {{$name}}Repository interface {
+	Create(c context.Context, m *{{$type}}) error
+	Upsert(c context.Context, m *{{$type}}) (*repository.ChangeInfo, error)
+	Update(c context.Context, m *{{$type}}, change interface{}) error
+{{- if .Entity.Primary}}
+	Get(c context.Context{{range .Entity.Primary}}, {{lower .Name}} {{.Type}}{{end}}) (*{{$type}}, error)
+{{- end}}
+	Delete(c context.Context, m *{{$type}}) error
+	Restore(c context.Context, m *{{$type}}) error
+	HardDelete(c context.Context, m *{{$type}}) error
+	BatchCreate(c context.Context, ms []*{{$type}}) ([]*repository.BatchResult, error)
+	BatchUpsert(c context.Context, ms []*{{$type}}) ([]*repository.BatchResult, error)
+	BatchDelete(c context.Context, ms []*{{$type}}) ([]*repository.BatchResult, error)
+	Count(c context.Context, filter map[string]interface{}) (int64, error)
+	Exists(c context.Context, filter map[string]interface{}) (bool, error)
+	FindOne(c context.Context, filter map[string]interface{}) (*{{$type}}, error)
+	UpdateMany(c context.Context, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error)
+	DeleteMany(c context.Context, filter map[string]interface{}, opts ...repository.MassOption) (int64, error)
+	Page(c context.Context, query *entity.PageQuery) ([]*{{$type}}, int64, error)
+	List(c context.Context, query *entity.CursorQuery) ([]*{{$type}}, *entity.CursorExtra, error)
+	Connection(c context.Context, query *entity.ConnectionQuery) (*{{$name}}Connection, error)
+	Aggregate(c context.Context, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error)
+{{- range .Entity.Finders}}
+{{- if .Unique}}
+	FindBy{{.Name}}(c context.Context, {{lower .Name}} {{.Type}}) (*{{$type}}, error)
+{{- else}}
+	FindBy{{.Name}}(c context.Context, {{lower .Name}} {{.Type}}, query *entity.ConnectionQuery) (*{{$name}}Connection, error)
+{{- end}}
+{{- end}}
+
+	// Base 被包装的仓储
+	Base() repository.BaseRepository
+}
+
+type {{$impl}} struct {
+	repo repository.BaseRepository
+}
+
+func New{{$name}}Repository(repo repository.BaseRepository) {{$name}}Repository {
+	return &{{$impl}}{repo}
+}
+
+func (r *{{$impl}}) Base() repository.BaseRepository {
+	return r.repo
+}
+
+func (r *{{$impl}}) checkFilter(filter map[string]interface{}) error {
+	if len(filter) == 0 {
+		return nil
+	}
+	_, err := entity.ParseFilter(filter, {{$name}}FilterFields)
+	return err
+}
+
+func (r *{{$impl}}) Create(c context.Context, m *{{$type}}) error {
+	return r.repo.Create(c, m)
+}
+
+func (r *{{$impl}}) Upsert(c context.Context, m *{{$type}}) (*repository.ChangeInfo, error) {
+	return r.repo.Upsert(c, m)
+}
+
+func (r *{{$impl}}) Update(c context.Context, m *{{$type}}, change interface{}) error {
+	return r.repo.Update(c, m, change)
+}
+{{- if .Entity.Primary}}
+
+func (r *{{$impl}}) Get(c context.Context{{range .Entity.Primary}}, {{lower .Name}} {{.Type}}{{end}}) (*{{$type}}, error) {
+	m := &{{$type}}{}
+{{- range .Entity.Primary}}
+	m.{{.Name}} = {{lower .Name}}
+{{- end}}
+	if err := r.repo.Get(c, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- end}}
+
+func (r *{{$impl}}) Delete(c context.Context, m *{{$type}}) error {
+	return r.repo.Delete(c, m)
+}
+
+func (r *{{$impl}}) Restore(c context.Context, m *{{$type}}) error {
+	return r.repo.Restore(c, m)
+}
+
+func (r *{{$impl}}) HardDelete(c context.Context, m *{{$type}}) error {
+	return r.repo.HardDelete(c, m)
+}
+
+func (r *{{$impl}}) entities(ms []*{{$type}}) []entity.Entity {
+	es := make([]entity.Entity, len(ms))
+	for i, m := range ms {
+		es[i] = m
+	}
+	return es
+}
+
+func (r *{{$impl}}) BatchCreate(c context.Context, ms []*{{$type}}) ([]*repository.BatchResult, error) {
+	return r.repo.BatchCreate(c, r.entities(ms))
+}
+
+func (r *{{$impl}}) BatchUpsert(c context.Context, ms []*{{$type}}) ([]*repository.BatchResult, error) {
+	return r.repo.BatchUpsert(c, r.entities(ms))
+}
+
+func (r *{{$impl}}) BatchDelete(c context.Context, ms []*{{$type}}) ([]*repository.BatchResult, error) {
+	return r.repo.BatchDelete(c, r.entities(ms))
+}
+
+func (r *{{$impl}}) Count(c context.Context, filter map[string]interface{}) (int64, error) {
+	if err := r.checkFilter(filter); err != nil {
+		return 0, err
+	}
+	return r.repo.Count(c, &{{$type}}{}, filter)
+}
+
+func (r *{{$impl}}) Exists(c context.Context, filter map[string]interface{}) (bool, error) {
+	if err := r.checkFilter(filter); err != nil {
+		return false, err
+	}
+	return r.repo.Exists(c, &{{$type}}{}, filter)
+}
+
+func (r *{{$impl}}) FindOne(c context.Context, filter map[string]interface{}) (*{{$type}}, error) {
+	if err := r.checkFilter(filter); err != nil {
+		return nil, err
+	}
+
+	m := &{{$type}}{}
+	if err := r.repo.FindOne(c, m, filter); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *{{$impl}}) UpdateMany(c context.Context, filter map[string]interface{}, change interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := r.checkFilter(filter); err != nil {
+		return 0, err
+	}
+	return r.repo.UpdateMany(c, &{{$type}}{}, filter, change, opts...)
+}
+
+func (r *{{$impl}}) DeleteMany(c context.Context, filter map[string]interface{}, opts ...repository.MassOption) (int64, error) {
+	if err := r.checkFilter(filter); err != nil {
+		return 0, err
+	}
+	return r.repo.DeleteMany(c, &{{$type}}{}, filter, opts...)
+}
+
+func (r *{{$impl}}) Page(c context.Context, query *entity.PageQuery) ([]*{{$type}}, int64, error) {
+	if err := r.checkFilter(query.Filter); err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*{{$type}}, 0)
+	total, err := r.repo.Page(c, &{{$type}}{}, query, &items)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *{{$impl}}) List(c context.Context, query *entity.CursorQuery) ([]*{{$type}}, *entity.CursorExtra, error) {
+	if err := r.checkFilter(query.Filter); err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]*{{$type}}, 0)
+	extra, err := r.repo.List(c, query, &{{$type}}{}, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, extra, nil
+}
+
+func (r *{{$impl}}) Connection(c context.Context, query *entity.ConnectionQuery) (*{{$name}}Connection, error) {
+	if err := r.checkFilter(query.Filter); err != nil {
+		return nil, err
+	}
+	return r.connection(c, query)
+}
+
+func (r *{{$impl}}) connection(c context.Context, query *entity.ConnectionQuery) (*{{$name}}Connection, error) {
+	conn, err := r.repo.Connection(c, query, &{{$type}}{})
+	if err != nil {
+		return nil, err
+	}
+
+	typed := &{{$name}}Connection{
+		Total:    conn.Total,
+		Edges:    make([]*{{$name}}Edge, len(conn.Edges)),
+		PageInfo: conn.PageInfo,
+	}
+	for i, edge := range conn.Edges {
+		typed.Edges[i] = &{{$name}}Edge{
+			Cursor: edge.Cursor,
+			Node:   edge.Node.(*{{$type}}),
+		}
+	}
+	return typed, nil
+}
+
+func (r *{{$impl}}) Aggregate(c context.Context, query *entity.AggregateQuery) ([]*entity.AggregateBucket, error) {
+	if err := query.Validate({{$name}}FilterFields); err != nil {
+		return nil, err
+	}
+	return r.repo.Aggregate(c, &{{$type}}{}, query)
+}
+{{- range .Entity.Finders}}
+{{- if .Unique}}
+
+func (r *{{$impl}}) FindBy{{.Name}}(c context.Context, {{lower .Name}} {{.Type}}) (*{{$type}}, error) {
+	m := &{{$type}}{}
+	err := r.repo.FindOne(c, m, map[string]interface{}{
+		{{quote .Key}}: {{lower .Name}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- else}}
+
+// FindBy{{.Name}} query 为空时使用默认的分页, query 中 {{.Key}} 的条件被替换
+func (r *{{$impl}}) FindBy{{.Name}}(c context.Context, {{lower .Name}} {{.Type}}, query *entity.ConnectionQuery) (*{{$name}}Connection, error) {
+	q := entity.ConnectionQuery{}
+	if query != nil {
+		q = *query
+	}
+	if err := r.checkFilter(q.Filter); err != nil {
+		return nil, err
+	}
+
+	filter := map[string]interface{}{
+		{{quote .Key}}: {{lower .Name}},
+	}
+	for k, v := range q.Filter {
+		if k != {{quote .Key}} {
+			filter[k] = v
+		}
+	}
+	q.Filter = filter
+
+	return r.connection(c, &q)
+}
+{{- end}}
+{{- end}}
+{{- with .Message}}
+
+// {{$name}}ToPB 转换为 {{.Name}} message
+{{- if .Unmapped}}
+// 没有对应的字段或者类型无法自动转换, 需要手写转换: {{join .Unmapped ", "}}
+{{- end}}
+func {{$name}}ToPB(m *{{$type}}) *pb.{{.Name}} {
+	if m == nil {
+		return nil
+	}
+
+	o := &pb.{{.Name}}{}
+{{- range .Mappings}}
+	{{.ToPB}}
+{{- end}}
+	return o
+}
+
+func {{$name}}FromPB(o *pb.{{.Name}}) *{{$type}} {
+	if o == nil {
+		return nil
+	}
+
+	m := &{{$type}}{}
+{{- range .Mappings}}
+	{{.FromPB}}
+{{- end}}
+	return m
+}
+
+func {{$name}}ListToPB(ms []*{{$type}}) []*pb.{{.Name}} {
+	pbs := make([]*pb.{{.Name}}, len(ms))
+	for i, m := range ms {
+		pbs[i] = {{$name}}ToPB(m)
+	}
+	return pbs
+}
+
+func {{$name}}ListFromPB(pbs []*pb.{{.Name}}) []*{{$type}} {
+	ms := make([]*{{$type}}, len(pbs))
+	for i, o := range pbs {
+		ms[i] = {{$name}}FromPB(o)
+	}
+	return ms
+}
+
+func {{$name}}ConnectionToPB(conn *{{$name}}Connection) ([]*pb.{{.Name}}, *pagination.PageInfo) {
+	return {{$name}}ListToPB(conn.Nodes()), conn.PageInfo.ToPB()
+}
+{{- end}}
+`))
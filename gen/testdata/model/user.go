@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/duolacloud/microbase/outbox"
+)
+
+type Status int32
+
+type Base struct {
+	CreateTime time.Time  `json:"ctime"`
+	DeleteTime *time.Time `json:"dtime"`
+}
+
+type User struct {
+	ID       int64    `json:"id" gorm:"primary_key"`
+	Name     string   `json:"name" gorm:"unique_index"`
+	Age      int      `json:"age" gorm:"index"`
+	Status   Status   `json:"status"`
+	Tags     []string `json:"tags" gorm:"-"`
+	Password string   `json:"-"`
+	Secret   string   `json:"secret" gen:"nofilter"`
+	Profile  *Profile `json:"profile" gen:"-"`
+	Base
+	outbox.Events
+}
+
+func (u *User) Unique() interface{} {
+	return map[string]interface{}{
+		"id": u.ID,
+	}
+}
+
+// Profile 没有 Unique 方法, 不是实体
+type Profile struct {
+	Avatar string `json:"avatar"`
+}
@@ -0,0 +1,21 @@
+package user
+
+import (
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+)
+
+type User_Status int32
+
+type User struct {
+	state         int
+	sizeCache     int
+	unknownFields []byte
+
+	Id     int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name   string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Age    int32                `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
+	Status User_Status          `protobuf:"varint,4,opt,name=status,proto3,enum=user.User_Status" json:"status,omitempty"`
+	Tags   []string             `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Ctime  *timestamp.Timestamp `protobuf:"bytes,6,opt,name=ctime,proto3" json:"ctime,omitempty"`
+	Dtime  *timestamp.Timestamp `protobuf:"bytes,7,opt,name=dtime,proto3" json:"dtime,omitempty"`
+}
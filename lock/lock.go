@@ -0,0 +1,170 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/duolacloud/microbase/logger"
+	"github.com/duolacloud/microbase/multitenancy"
+)
+
+var (
+	// ErrNotAcquired 锁已被其他持有者占用
+	ErrNotAcquired = errors.New("lock: not acquired")
+	// ErrLockLost 锁已过期或被其他持有者占用
+	ErrLockLost = errors.New("lock: lost")
+)
+
+// Locker 分布式锁, 锁名按租户隔离, 同一租户同一时间只有一个持有者
+type Locker interface {
+	Init(opts ...Option) error
+	Options() Options
+	// Acquire 获取锁, 阻塞直到成功或 ctx 结束, 持有期间每 ttl/3 自动续期
+	Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+	// TryAcquire 只尝试一次, 锁被占用时返回 ErrNotAcquired
+	TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+	String() string
+}
+
+// Lock 已获取的锁
+type Lock interface {
+	Name() string
+	// Token fencing token, 同一个锁每次获取单调递增,
+	// 写入下游资源时携带它, 下游拒绝比已见过的更小的 token
+	Token() int64
+	// Lost 续期失败、锁已丢失时关闭
+	Lost() <-chan struct{}
+	// Release 停止续期并释放锁
+	Release(ctx context.Context) error
+}
+
+// Lease 后端对一次持有的续期和释放操作
+type Lease interface {
+	// Renew 续期, 锁已不属于自己时返回 ErrLockLost
+	Renew(ctx context.Context, ttl time.Duration) error
+	Release(ctx context.Context) error
+}
+
+// Key 锁在后端存储的名字: {prefix}:{name}:{tenant}
+func Key(ctx context.Context, prefix, name string) string {
+	key := name
+	if tenantId, _ := multitenancy.FromContext(ctx); tenantId != "" {
+		key = fmt.Sprintf("%s:%s", key, tenantId)
+	}
+	if prefix != "" {
+		key = fmt.Sprintf("%s:%s", prefix, key)
+	}
+	return key
+}
+
+// Wait 按 interval 重复调用 try, 直到获取成功、出现其他错误或 ctx 结束
+func Wait(ctx context.Context, interval time.Duration, try func() (Lock, error)) (Lock, error) {
+	for {
+		l, err := try()
+		if err != ErrNotAcquired {
+			return l, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+type lock struct {
+	name  string
+	token int64
+	ttl   time.Duration
+	lease Lease
+
+	lost      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewLock 包装后端的 Lease, 在后台自动续期
+func NewLock(name string, token int64, ttl time.Duration, lease Lease) Lock {
+	l := &lock{
+		name:  name,
+		token: token,
+		ttl:   ttl,
+		lease: lease,
+		lost:  make(chan struct{}),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	go l.keepalive()
+
+	return l
+}
+
+func (l *lock) Name() string {
+	return l.name
+}
+
+func (l *lock) Token() int64 {
+	return l.token
+}
+
+func (l *lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *lock) Release(ctx context.Context) error {
+	l.closeOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+
+	select {
+	case <-l.lost:
+		return ErrLockLost
+	default:
+	}
+
+	return l.lease.Release(ctx)
+}
+
+// keepalive 每 ttl/3 续期一次, 续期失败持续到 ttl 耗尽则认为锁已丢失
+func (l *lock) keepalive() {
+	defer close(l.done)
+
+	interval := l.ttl / 3
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := l.lease.Renew(ctx, l.ttl)
+		cancel()
+
+		if err == nil {
+			renewed = time.Now()
+			continue
+		}
+
+		logger.Warnf("lock %s renew error: %v", l.name, err)
+		if err == ErrLockLost || time.Since(renewed) >= l.ttl {
+			close(l.lost)
+			return
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duolacloud/microbase/lock"
+	uuid "github.com/satori/go.uuid"
+)
+
+type entry struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// MemoryLocker 进程内的锁, 用于测试和单实例部署
+type MemoryLocker struct {
+	options lock.Options
+
+	mu     sync.Mutex
+	locks  map[string]*entry
+	fences map[string]int64
+}
+
+func NewLocker(opts ...lock.Option) lock.Locker {
+	return &MemoryLocker{
+		options: lock.NewOptions(opts...),
+		locks:   make(map[string]*entry),
+		fences:  make(map[string]int64),
+	}
+}
+
+func (m *MemoryLocker) Init(opts ...lock.Option) error {
+	for _, o := range opts {
+		o(&m.options)
+	}
+	return nil
+}
+
+func (m *MemoryLocker) Options() lock.Options {
+	return m.options
+}
+
+func (m *MemoryLocker) String() string {
+	return "memory"
+}
+
+func (m *MemoryLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (lock.Lock, error) {
+	return lock.Wait(ctx, m.options.RetryInterval, func() (lock.Lock, error) {
+		return m.TryAcquire(ctx, name, ttl)
+	})
+}
+
+func (m *MemoryLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (lock.Lock, error) {
+	key := lock.Key(ctx, m.options.Prefix, name)
+	owner := uuid.NewV4().String()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.locks[key]; ok && time.Now().Before(e.expiresAt) {
+		return nil, lock.ErrNotAcquired
+	}
+
+	m.locks[key] = &entry{
+		owner:     owner,
+		expiresAt: time.Now().Add(ttl),
+	}
+	m.fences[key]++
+
+	return lock.NewLock(name, m.fences[key], ttl, &lease{m, key, owner}), nil
+}
+
+type lease struct {
+	m     *MemoryLocker
+	key   string
+	owner string
+}
+
+func (l *lease) Renew(ctx context.Context, ttl time.Duration) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+
+	e, ok := l.m.locks[l.key]
+	if !ok || e.owner != l.owner || time.Now().After(e.expiresAt) {
+		return lock.ErrLockLost
+	}
+
+	e.expiresAt = time.Now().Add(ttl)
+	return nil
+}
+
+func (l *lease) Release(ctx context.Context) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+
+	e, ok := l.m.locks[l.key]
+	if !ok || e.owner != l.owner {
+		return lock.ErrLockLost
+	}
+
+	delete(l.m.locks, l.key)
+	return nil
+}
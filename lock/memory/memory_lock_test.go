@@ -0,0 +1,47 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duolacloud/microbase/lock"
+	"github.com/duolacloud/microbase/lock/memory"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquire(t *testing.T) {
+	l := memory.NewLocker(lock.WithRetryInterval(10 * time.Millisecond))
+	ctx := context.Background()
+
+	l1, err := l.Acquire(ctx, "job", 300*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), l1.Token())
+
+	_, err = l.TryAcquire(ctx, "job", time.Second)
+	assert.Equal(t, lock.ErrNotAcquired, err)
+
+	// 不同租户互不影响
+	tenantCtx := context.WithValue(ctx, multitenancy.TenantId, "t1")
+	l2, err := l.TryAcquire(tenantCtx, "job", time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, l2.Release(tenantCtx))
+
+	// 自动续期, 超过 ttl 仍然持有
+	time.Sleep(500 * time.Millisecond)
+	_, err = l.TryAcquire(ctx, "job", time.Second)
+	assert.Equal(t, lock.ErrNotAcquired, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(timeoutCtx, "job", time.Second)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	assert.NoError(t, l1.Release(ctx))
+
+	l3, err := l.Acquire(ctx, "job", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, l3.Token() > l1.Token())
+	assert.NoError(t, l3.Release(ctx))
+}
@@ -0,0 +1,41 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+type Options struct {
+	Context context.Context
+	Prefix  string
+	// RetryInterval Acquire 等待锁时的重试间隔
+	RetryInterval time.Duration
+}
+
+type Option func(o *Options)
+
+func WithPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.Prefix = prefix
+	}
+}
+
+func WithRetryInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.RetryInterval = interval
+	}
+}
+
+// NewOptions 创建默认配置并应用 opts
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Context:       context.Background(),
+		RetryInterval: 100 * time.Millisecond,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
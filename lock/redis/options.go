@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/duolacloud/microbase/cache"
+	cacheredis "github.com/duolacloud/microbase/cache/redis"
+	"github.com/duolacloud/microbase/lock"
+)
+
+type cacheOptionsKey struct{}
+type poolKey struct{}
+
+// WithCacheOptions 使用 cache/redis 的连接配置 (地址、模式、超时、TLS 等) 创建连接池
+func WithCacheOptions(opts ...cache.Option) lock.Option {
+	return func(o *lock.Options) {
+		o.Context = context.WithValue(o.Context, cacheOptionsKey{}, opts)
+	}
+}
+
+// WithPool 直接使用已有的连接池
+func WithPool(pool cacheredis.Pool) lock.Option {
+	return func(o *lock.Options) {
+		o.Context = context.WithValue(o.Context, poolKey{}, pool)
+	}
+}
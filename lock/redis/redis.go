@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duolacloud/microbase/cache"
+	cacheredis "github.com/duolacloud/microbase/cache/redis"
+	"github.com/duolacloud/microbase/lock"
+	"github.com/garyburd/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+)
+
+// 获取锁并递增 fencing token, 两个 key 使用相同的 hash tag, 集群模式下位于同一个 slot
+var acquireScript = redis.NewScript(2, `
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+var renewScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type RedisLocker struct {
+	options lock.Options
+	pool    cacheredis.Pool
+}
+
+func NewLocker(opts ...lock.Option) lock.Locker {
+	return &RedisLocker{
+		options: lock.NewOptions(opts...),
+	}
+}
+
+func (m *RedisLocker) Init(opts ...lock.Option) error {
+	for _, o := range opts {
+		o(&m.options)
+	}
+
+	if pool, ok := m.options.Context.Value(poolKey{}).(cacheredis.Pool); ok {
+		m.pool = pool
+		return nil
+	}
+
+	cacheOpts, _ := m.options.Context.Value(cacheOptionsKey{}).([]cache.Option)
+	pool, err := cacheredis.NewPool(cacheOpts...)
+	if err != nil {
+		return err
+	}
+	m.pool = pool
+	return nil
+}
+
+func (m *RedisLocker) Options() lock.Options {
+	return m.options
+}
+
+func (m *RedisLocker) String() string {
+	return "redis"
+}
+
+func (m *RedisLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (lock.Lock, error) {
+	return lock.Wait(ctx, m.options.RetryInterval, func() (lock.Lock, error) {
+		return m.TryAcquire(ctx, name, ttl)
+	})
+}
+
+func (m *RedisLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (lock.Lock, error) {
+	key := fmt.Sprintf("lock:{%s}", lock.Key(ctx, m.options.Prefix, name))
+	owner := uuid.NewV4().String()
+
+	c := m.pool.Get()
+	defer c.Close()
+
+	token, err := redis.Int64(acquireScript.Do(c, key, key+":fence", owner, int64(ttl/time.Millisecond)))
+	if err != nil {
+		return nil, err
+	}
+	if token == 0 {
+		return nil, lock.ErrNotAcquired
+	}
+
+	return lock.NewLock(name, token, ttl, &lease{m.pool, key, owner}), nil
+}
+
+type lease struct {
+	pool  cacheredis.Pool
+	key   string
+	owner string
+}
+
+func (l *lease) Renew(ctx context.Context, ttl time.Duration) error {
+	c := l.pool.Get()
+	defer c.Close()
+
+	ok, err := redis.Int(renewScript.Do(c, l.key, l.owner, int64(ttl/time.Millisecond)))
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return lock.ErrLockLost
+	}
+	return nil
+}
+
+func (l *lease) Release(ctx context.Context) error {
+	c := l.pool.Get()
+	defer c.Close()
+
+	ok, err := redis.Int(releaseScript.Do(c, l.key, l.owner))
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return lock.ErrLockLost
+	}
+	return nil
+}
@@ -36,3 +36,19 @@ func WithContext(ctx context.Context, tenantId string) context.Context {
 	ctx = metadata.NewContext(ctx, md)
 	return ctx
 }
+
+type resourceKey struct {
+	tenancy  Tenancy
+	tenantId string
+}
+
+// WithResource 把租户的资源绑定到 ctx, 之后同一 tenancy 和租户优先使用 ctx 中的资源, 例如事务
+func WithResource(ctx context.Context, tenancy Tenancy, tenantId string, resource Resource) context.Context {
+	return context.WithValue(ctx, resourceKey{tenancy, tenantId}, resource)
+}
+
+// ResourceFromContext 返回 WithResource 绑定的资源
+func ResourceFromContext(ctx context.Context, tenancy Tenancy, tenantId string) (Resource, bool) {
+	resource, ok := ctx.Value(resourceKey{tenancy, tenantId}).(Resource)
+	return resource, ok
+}
@@ -0,0 +1,103 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dsgorm "github.com/duolacloud/microbase/datasource/gorm"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/duolacloud/microbase/outbox"
+	_gorm "github.com/jinzhu/gorm"
+)
+
+// GormStore 每个租户的消息保存在 outbox_messages_{租户} 表, 开启 db.outbox 后表由 datasource/gorm 的 autoMigrate 创建
+type GormStore struct {
+	tenancy multitenancy.Tenancy
+}
+
+// NewStore ctx 中有 dsgorm.TxManager 开启的事务时, Append 在同一个事务中写入
+func NewStore(tenancy multitenancy.Tenancy) outbox.Store {
+	return &GormStore{
+		tenancy: tenancy,
+	}
+}
+
+func (s *GormStore) table(ctx context.Context) (*_gorm.DB, error) {
+	db, err := dsgorm.DBFromContext(s.tenancy, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Table(tableName(ctx)), nil
+}
+
+func tableName(ctx context.Context) string {
+	tenantId, _ := multitenancy.FromContext(ctx)
+	return dsgorm.TableName((&outbox.Message{}).TableName(), tenantId)
+}
+
+func (s *GormStore) Append(ctx context.Context, messages ...*outbox.Message) error {
+	db, err := s.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range messages {
+		if err = db.Create(m).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GormStore) Pending(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	db, err := s.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 排除有消息等待重试的聚合, 同一聚合只有最早的未投递消息会失败
+	waiting := fmt.Sprintf("SELECT aggregate_type, aggregate_id FROM %s WHERE published = ? AND ntime > ?", db.NewScope(nil).Quote(tableName(ctx)))
+
+	var messages []*outbox.Message
+	err = db.Where("published = ?", false).
+		Where(fmt.Sprintf("(aggregate_type, aggregate_id) NOT IN (%s)", waiting), false, time.Now()).
+		Order("id").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func (s *GormStore) MarkPublished(ctx context.Context, m *outbox.Message) error {
+	db, err := s.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"published": true,
+		"ptime":     time.Now(),
+	}).Error
+}
+
+func (s *GormStore) MarkFailed(ctx context.Context, m *outbox.Message, cause error, next time.Time) error {
+	db, err := s.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"attempts":   _gorm.Expr("attempts + 1"),
+		"ntime":      next,
+		"last_error": cause.Error(),
+	}).Error
+}
+
+func (s *GormStore) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	db, err := s.table(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result := db.Where("published = ? AND ptime < ?", true, before).Delete(&outbox.Message{})
+	return result.RowsAffected, result.Error
+}
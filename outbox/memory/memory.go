@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/duolacloud/microbase/outbox"
+	"github.com/micro/go-micro/v2/broker"
+	mbroker "github.com/micro/go-micro/v2/broker/memory"
+)
+
+// MemoryStore 进程内的 outbox, 用于测试, 写入不参与数据库事务
+type MemoryStore struct {
+	mu       sync.Mutex
+	seq      int64
+	messages map[string]map[int64]*outbox.Message
+}
+
+func NewStore() outbox.Store {
+	return &MemoryStore{
+		messages: make(map[string]map[int64]*outbox.Message),
+	}
+}
+
+// NewBroker 已经 Connect 的 go-micro 内存 broker, 订阅者在 Publish 时同步收到消息
+func NewBroker(opts ...broker.Option) (broker.Broker, error) {
+	b := mbroker.NewBroker(opts...)
+	if err := b.Connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *MemoryStore) tenant(ctx context.Context) map[int64]*outbox.Message {
+	tenantId, _ := multitenancy.FromContext(ctx)
+
+	messages, ok := s.messages[tenantId]
+	if !ok {
+		messages = make(map[int64]*outbox.Message)
+		s.messages[tenantId] = messages
+	}
+	return messages
+}
+
+func (s *MemoryStore) Append(ctx context.Context, messages ...*outbox.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenant := s.tenant(ctx)
+	for _, m := range messages {
+		s.seq++
+		m.ID = s.seq
+
+		copied := *m
+		tenant[m.ID] = &copied
+	}
+	return nil
+}
+
+func (s *MemoryStore) Pending(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	blocked := make(map[string]bool)
+	for _, m := range s.tenant(ctx) {
+		if !m.Published && m.NextTime.After(now) {
+			blocked[m.Aggregate()] = true
+		}
+	}
+
+	var messages []*outbox.Message
+	for _, m := range s.tenant(ctx) {
+		if !m.Published && !blocked[m.Aggregate()] {
+			copied := *m
+			messages = append(messages, &copied)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+func (s *MemoryStore) MarkPublished(ctx context.Context, m *outbox.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.tenant(ctx)[m.ID]; ok {
+		now := time.Now()
+		stored.Published = true
+		stored.PublishTime = &now
+	}
+	return nil
+}
+
+func (s *MemoryStore) MarkFailed(ctx context.Context, m *outbox.Message, cause error, next time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.tenant(ctx)[m.ID]; ok {
+		stored.Attempts++
+		stored.NextTime = next
+		stored.LastError = cause.Error()
+	}
+	return nil
+}
+
+func (s *MemoryStore) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	tenant := s.tenant(ctx)
+	for id, m := range tenant {
+		if m.Published && m.PublishTime.Before(before) {
+			delete(tenant, id)
+			n++
+		}
+	}
+	return n, nil
+}
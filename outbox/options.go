@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/duolacloud/microbase/lock"
+)
+
+type Options struct {
+	// Tenants 返回需要投递的租户, 默认只有空租户
+	Tenants func(ctx context.Context) ([]string, error)
+	// Interval 轮询 outbox 表的间隔
+	Interval time.Duration
+	// BatchSize 每个租户每次最多读取的消息数
+	BatchSize int
+	// MinBackoff 和 MaxBackoff 投递失败后按次数指数退避重试的间隔
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention 已投递的消息保留的时间, 之后被清理
+	Retention time.Duration
+	// Locker 多个实例同时运行时, 同一租户同一时间只有一个实例投递, 保证同一聚合的顺序
+	Locker lock.Locker
+}
+
+type Option func(o *Options)
+
+func WithTenants(tenants func(ctx context.Context) ([]string, error)) Option {
+	return func(o *Options) {
+		o.Tenants = tenants
+	}
+}
+
+func WithInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = interval
+	}
+}
+
+func WithBatchSize(size int) Option {
+	return func(o *Options) {
+		o.BatchSize = size
+	}
+}
+
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *Options) {
+		o.MinBackoff = min
+		o.MaxBackoff = max
+	}
+}
+
+func WithRetention(retention time.Duration) Option {
+	return func(o *Options) {
+		o.Retention = retention
+	}
+}
+
+func WithLocker(locker lock.Locker) Option {
+	return func(o *Options) {
+		o.Locker = locker
+	}
+}
+
+// NewOptions 创建默认配置并应用 opts
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Tenants: func(ctx context.Context) ([]string, error) {
+			return []string{""}, nil
+		},
+		Interval:   time.Second,
+		BatchSize:  100,
+		MinBackoff: time.Second,
+		MaxBackoff: 5 * time.Minute,
+		Retention:  7 * 24 * time.Hour,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duolacloud/microbase/multitenancy"
+	uuid "github.com/satori/go.uuid"
+)
+
+// 投递到 broker 的消息头
+const (
+	HeaderMessageId     = "message-id" // 消费者按它去重, 同一条消息可能投递多次
+	HeaderAggregateType = "aggregate-type"
+	HeaderAggregateId   = "aggregate-id"
+	HeaderContentType   = "content-type"
+)
+
+// Event 领域事件, Payload 按 json 序列化
+type Event struct {
+	Topic   string
+	Payload interface{}
+	Header  map[string]string
+}
+
+// EventSource 聚合在写入时产生的事件, gorm 仓储写入实体时在同一个事务中把事件写入 outbox 表
+type EventSource interface {
+	// PopEvents 返回并清空尚未写入的事件
+	PopEvents() []*Event
+}
+
+// Events 嵌入到实体中实现 EventSource, 例如
+//
+//	type Order struct {
+//		ID string `json:"id" gorm:"primary_key"`
+//		outbox.Events `json:"-" gorm:"-" bson:"-"`
+//	}
+//
+// 写入失败时事件已经被取出, 重试写入前需要重新记录事件
+type Events struct {
+	events []*Event
+}
+
+// Record 记录一个事件, 在下一次写入实体时保存
+func (e *Events) Record(topic string, payload interface{}) {
+	e.events = append(e.events, &Event{Topic: topic, Payload: payload})
+}
+
+func (e *Events) PopEvents() []*Event {
+	events := e.events
+	e.events = nil
+	return events
+}
+
+// Header 消息头, 在数据库中按 json 存储为一列
+type Header map[string]string
+
+func (h Header) Value() (driver.Value, error) {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (h *Header) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return errors.New(fmt.Sprintf("unexpected header value %v", src))
+	}
+}
+
+// Message outbox 表中的一条消息, 每个租户一张表, 同一聚合的消息按 ID 顺序投递
+type Message struct {
+	ID            int64      `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	MessageID     string     `json:"messageId" gorm:"size:36;unique_index"`
+	Topic         string     `json:"topic" gorm:"size:255"`
+	AggregateType string     `json:"aggregateType" gorm:"size:64"`
+	AggregateID   string     `json:"aggregateId" gorm:"size:255"`
+	Header        Header     `json:"header" gorm:"type:text"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Published     bool       `json:"published" gorm:"index"`
+	Attempts      int        `json:"attempts"`
+	NextTime      time.Time  `json:"ntime" gorm:"column:ntime"` // 失败后下一次投递的时间
+	LastError     string     `json:"lastError" gorm:"type:text"`
+	CreateTime    time.Time  `json:"ctime" gorm:"column:ctime"`
+	PublishTime   *time.Time `json:"ptime" gorm:"column:ptime;index"`
+}
+
+func (m *Message) TableName() string {
+	return "outbox_messages"
+}
+
+func (m *Message) Unique() interface{} {
+	return map[string]interface{}{
+		"id": m.ID,
+	}
+}
+
+// Aggregate 消息所属的聚合, 同一聚合的消息需要按顺序投递
+func (m *Message) Aggregate() string {
+	return m.AggregateType + ":" + m.AggregateID
+}
+
+// NewMessage 序列化事件, 消息头带上租户, 消费者可以用 multitenancy.WithContext 恢复租户
+func NewMessage(ctx context.Context, aggregateType, aggregateID string, event *Event) (*Message, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	m := &Message{
+		MessageID:     uuid.NewV4().String(),
+		Topic:         event.Topic,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Header:        Header{},
+		Payload:       string(payload),
+		NextTime:      now,
+		CreateTime:    now,
+	}
+
+	for k, v := range event.Header {
+		m.Header[k] = v
+	}
+	if tenantId, _ := multitenancy.FromContext(ctx); tenantId != "" {
+		m.Header[multitenancy.TenantId] = tenantId
+	}
+	m.Header[HeaderMessageId] = m.MessageID
+	m.Header[HeaderAggregateType] = aggregateType
+	m.Header[HeaderAggregateId] = aggregateID
+	m.Header[HeaderContentType] = "application/json"
+
+	return m, nil
+}
+
+// Store outbox 表的读写, 方法按 ctx 中的租户选择表
+type Store interface {
+	// Append 写入消息, ctx 中有事务时在同一个事务中写入
+	Append(ctx context.Context, messages ...*Message) error
+	// Pending 按 ID 顺序返回未投递的消息, 不包括有消息还未到重试时间的聚合,
+	// 否则这些聚合的消息超过 limit 时会挡住其它聚合
+	Pending(ctx context.Context, limit int) ([]*Message, error)
+	MarkPublished(ctx context.Context, m *Message) error
+	// MarkFailed 记录投递失败, next 之前不再投递
+	MarkFailed(ctx context.Context, m *Message, cause error, next time.Time) error
+	// Cleanup 删除 before 之前已经投递的消息, 返回删除的数量
+	Cleanup(ctx context.Context, before time.Time) (int64, error)
+}
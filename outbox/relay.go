@@ -0,0 +1,199 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duolacloud/microbase/lock"
+	"github.com/duolacloud/microbase/logger"
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/micro/go-micro/v2/broker"
+)
+
+// Relay 轮询 outbox 表, 把消息投递到 broker, 投递成功后才标记为已投递, 保证至少投递一次;
+// 同一聚合的消息按顺序投递, 前一条失败时后面的消息等待它重试成功
+type Relay struct {
+	store   Store
+	broker  broker.Broker
+	options Options
+
+	mu       sync.Mutex
+	stop     chan struct{}
+	done     chan struct{}
+	cleaned  map[string]time.Time
+	cleanMux sync.Mutex
+}
+
+// NewRelay broker 需要调用方 Connect, 测试时可以使用 memory.NewBroker
+func NewRelay(store Store, b broker.Broker, opts ...Option) *Relay {
+	return &Relay{
+		store:   store,
+		broker:  b,
+		options: NewOptions(opts...),
+		cleaned: make(map[string]time.Time),
+	}
+}
+
+func (r *Relay) Options() Options {
+	return r.options
+}
+
+// Start 在后台按 Interval 轮询, 重复调用无效
+func (r *Relay) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		return nil
+	}
+
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run(r.stop, r.done)
+	return nil
+}
+
+// Stop 停止轮询, 等待正在进行的投递结束
+func (r *Relay) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop == nil {
+		return nil
+	}
+
+	close(r.stop)
+	<-r.done
+	r.stop, r.done = nil, nil
+	return nil
+}
+
+func (r *Relay) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Relay(context.Background()); err != nil {
+			logger.Errorf("outbox relay error: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Relay 投递所有租户当前待投递的消息, 并清理过期的已投递消息
+func (r *Relay) Relay(ctx context.Context) error {
+	tenants, err := r.options.Tenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tenantId := range tenants {
+		c := ctx
+		if tenantId != "" {
+			c = multitenancy.WithContext(ctx, tenantId)
+		}
+
+		// 一个租户失败不影响其它租户
+		if err := r.relayTenant(c); err != nil {
+			logger.Errorf("outbox relay tenant %s error: %v", tenantId, err)
+		}
+	}
+	return nil
+}
+
+func (r *Relay) relayTenant(ctx context.Context) error {
+	if r.options.Locker != nil {
+		l, err := r.options.Locker.TryAcquire(ctx, "outbox-relay", r.options.Interval*10)
+		if err == lock.ErrNotAcquired {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := l.Release(ctx); err != nil {
+				logger.Warnf("outbox relay release lock error: %v", err)
+			}
+		}()
+	}
+
+	messages, err := r.store.Pending(ctx, r.options.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	blocked := make(map[string]bool)
+	for _, m := range messages {
+		aggregate := m.Aggregate()
+		if blocked[aggregate] {
+			continue
+		}
+
+		// 还没到重试时间的消息挡住同一聚合后面的消息
+		if m.NextTime.After(now) {
+			blocked[aggregate] = true
+			continue
+		}
+
+		if err := r.broker.Publish(m.Topic, &broker.Message{Header: m.Header, Body: []byte(m.Payload)}); err != nil {
+			blocked[aggregate] = true
+			logger.Warnf("outbox publish message %s to %s error: %v", m.MessageID, m.Topic, err)
+
+			if err := r.store.MarkFailed(ctx, m, err, now.Add(r.backoff(m.Attempts+1))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 标记失败时消息会被再次投递, 消费者按 message-id 去重
+		if err := r.store.MarkPublished(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return r.cleanup(ctx, now)
+}
+
+// backoff 第 attempts 次失败后的重试间隔
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.options.MinBackoff
+	for i := 1; i < attempts && d < r.options.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.options.MaxBackoff {
+		d = r.options.MaxBackoff
+	}
+	return d
+}
+
+// cleanup 每个租户最多每小时清理一次
+func (r *Relay) cleanup(ctx context.Context, now time.Time) error {
+	tenantId, _ := multitenancy.FromContext(ctx)
+
+	r.cleanMux.Lock()
+	last, ok := r.cleaned[tenantId]
+	if ok && now.Sub(last) < time.Hour {
+		r.cleanMux.Unlock()
+		return nil
+	}
+	r.cleaned[tenantId] = now
+	r.cleanMux.Unlock()
+
+	n, err := r.store.Cleanup(ctx, now.Add(-r.options.Retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Infof("outbox cleanup %d messages of tenant %s", n, tenantId)
+	}
+	return nil
+}
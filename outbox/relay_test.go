@@ -0,0 +1,136 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duolacloud/microbase/multitenancy"
+	"github.com/duolacloud/microbase/outbox"
+	"github.com/duolacloud/microbase/outbox/memory"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/stretchr/testify/assert"
+)
+
+type Order struct {
+	ID string `json:"id"`
+	outbox.Events
+}
+
+func TestEvents(t *testing.T) {
+	order := &Order{ID: "1"}
+	order.Record("order.created", map[string]string{"id": order.ID})
+
+	events := order.PopEvents()
+	assert.Len(t, events, 1)
+	assert.Empty(t, order.PopEvents())
+
+	ctx := multitenancy.WithContext(context.Background(), "t1")
+	m, err := outbox.NewMessage(ctx, "orders", order.ID, events[0])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"1"}`, m.Payload)
+	assert.Equal(t, "t1", m.Header[multitenancy.TenantId])
+	assert.Equal(t, "orders:1", m.Aggregate())
+}
+
+func TestRelay(t *testing.T) {
+	ctx := multitenancy.WithContext(context.Background(), "t1")
+	store := memory.NewStore()
+
+	b, err := memory.NewBroker()
+	assert.NoError(t, err)
+
+	var received []string
+	failed := false
+	_, err = b.Subscribe("orders", func(e broker.Event) error {
+		id := e.Message().Header[outbox.HeaderAggregateId] + ":" + string(e.Message().Body)
+		// 第一条消息第一次投递失败
+		if id == `1:"a1"` && !failed {
+			failed = true
+			return errors.New("unavailable")
+		}
+
+		assert.Equal(t, "t1", e.Message().Header[multitenancy.TenantId])
+		received = append(received, id)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	for _, e := range []struct{ id, payload string }{{"1", "a1"}, {"1", "a2"}, {"2", "b1"}} {
+		m, err := outbox.NewMessage(ctx, "orders", e.id, &outbox.Event{Topic: "orders", Payload: e.payload})
+		assert.NoError(t, err)
+		assert.NoError(t, store.Append(ctx, m))
+	}
+
+	tenants := outbox.WithTenants(func(ctx context.Context) ([]string, error) {
+		return []string{"t1"}, nil
+	})
+	relay := outbox.NewRelay(store, b, tenants, outbox.WithBackoff(0, 0))
+
+	// 聚合 1 的第一条消息失败, 第二条等待重试, 聚合 2 不受影响
+	assert.NoError(t, relay.Relay(context.Background()))
+	assert.Equal(t, []string{`2:"b1"`}, received)
+
+	pending, err := store.Pending(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, 1, pending[0].Attempts)
+		assert.Equal(t, "unavailable", pending[0].LastError)
+	}
+
+	assert.NoError(t, relay.Relay(context.Background()))
+	assert.Equal(t, []string{`2:"b1"`, `1:"a1"`, `1:"a2"`}, received)
+
+	pending, err = store.Pending(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// 清理已投递的消息
+	cleaner := outbox.NewRelay(store, b, tenants, outbox.WithRetention(-time.Minute))
+	assert.NoError(t, cleaner.Relay(context.Background()))
+
+	n, err := store.Cleanup(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+}
+
+func TestRelayWaitingAggregate(t *testing.T) {
+	ctx := multitenancy.WithContext(context.Background(), "t1")
+	store := memory.NewStore()
+
+	b, err := memory.NewBroker()
+	assert.NoError(t, err)
+
+	var received []string
+	_, err = b.Subscribe("orders", func(e broker.Event) error {
+		id := e.Message().Header[outbox.HeaderAggregateId] + ":" + string(e.Message().Body)
+		if e.Message().Header[outbox.HeaderAggregateId] == "1" {
+			return errors.New("unavailable")
+		}
+
+		received = append(received, id)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// 聚合 1 的消息比 BatchSize 多, 都排在聚合 2 前面
+	for _, e := range []struct{ id, payload string }{{"1", "a1"}, {"1", "a2"}, {"1", "a3"}, {"2", "b1"}} {
+		m, err := outbox.NewMessage(ctx, "orders", e.id, &outbox.Event{Topic: "orders", Payload: e.payload})
+		assert.NoError(t, err)
+		assert.NoError(t, store.Append(ctx, m))
+	}
+
+	tenants := outbox.WithTenants(func(ctx context.Context) ([]string, error) {
+		return []string{"t1"}, nil
+	})
+	relay := outbox.NewRelay(store, b, tenants, outbox.WithBatchSize(2), outbox.WithBackoff(time.Hour, time.Hour))
+
+	// 第一次只读取到聚合 1 的消息, 第一条失败后等待重试
+	assert.NoError(t, relay.Relay(context.Background()))
+	assert.Empty(t, received)
+
+	// 等待重试的聚合不再占用 BatchSize, 聚合 2 的消息被投递
+	assert.NoError(t, relay.Relay(context.Background()))
+	assert.Equal(t, []string{`2:"b1"`}, received)
+}
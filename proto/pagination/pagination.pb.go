@@ -191,6 +191,8 @@ type ConnectionQuery struct {
 	Fields               []string                `protobuf:"bytes,10,rep,name=fields,proto3" json:"fields,omitempty"`
 	Filter               string                  `protobuf:"bytes,11,opt,name=filter,proto3" json:"filter,omitempty"`
 	Orders               []*Order                `protobuf:"bytes,12,rep,name=orders,proto3" json:"orders,omitempty"`
+	FilterQuery          string                  `protobuf:"bytes,13,opt,name=filter_query,json=filterQuery,proto3" json:"filter_query,omitempty"`
+	IncludeDeleted       bool                    `protobuf:"varint,14,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
 	XXX_unrecognized     []byte                  `json:"-"`
 	XXX_sizecache        int32                   `json:"-"`
@@ -276,6 +278,20 @@ func (m *ConnectionQuery) GetOrders() []*Order {
 	return nil
 }
 
+func (m *ConnectionQuery) GetFilterQuery() string {
+	if m != nil {
+		return m.FilterQuery
+	}
+	return ""
+}
+
+func (m *ConnectionQuery) GetIncludeDeleted() bool {
+	if m != nil {
+		return m.IncludeDeleted
+	}
+	return false
+}
+
 type Connection struct {
 	PageInfo             *PageInfo `protobuf:"bytes,1,opt,name=pageInfo,proto3" json:"pageInfo,omitempty"`
 	Total                int64     `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
@@ -382,6 +398,8 @@ type PageQuery struct {
 	Filter               string   `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
 	Orders               []*Order `protobuf:"bytes,4,rep,name=orders,proto3" json:"orders,omitempty"`
 	Fields               []string `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty"`
+	FilterQuery          string   `protobuf:"bytes,6,opt,name=filter_query,json=filterQuery,proto3" json:"filter_query,omitempty"`
+	IncludeDeleted       bool     `protobuf:"varint,7,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -446,6 +464,20 @@ func (m *PageQuery) GetFields() []string {
 	return nil
 }
 
+func (m *PageQuery) GetFilterQuery() string {
+	if m != nil {
+		return m.FilterQuery
+	}
+	return ""
+}
+
+func (m *PageQuery) GetIncludeDeleted() bool {
+	if m != nil {
+		return m.IncludeDeleted
+	}
+	return false
+}
+
 // 游标查询
 type ListQuery struct {
 	Direction            CursorDirection `protobuf:"varint,1,opt,name=direction,proto3,enum=pagination.CursorDirection" json:"direction,omitempty"`
@@ -455,6 +487,8 @@ type ListQuery struct {
 	NeedTotal            bool            `protobuf:"varint,5,opt,name=needTotal,proto3" json:"needTotal,omitempty"`
 	Orders               []*Order        `protobuf:"bytes,6,rep,name=orders,proto3" json:"orders,omitempty"`
 	Fields               []string        `protobuf:"bytes,7,rep,name=fields,proto3" json:"fields,omitempty"`
+	FilterQuery          string          `protobuf:"bytes,8,opt,name=filter_query,json=filterQuery,proto3" json:"filter_query,omitempty"`
+	IncludeDeleted       bool            `protobuf:"varint,9,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -533,6 +567,20 @@ func (m *ListQuery) GetFields() []string {
 	return nil
 }
 
+func (m *ListQuery) GetFilterQuery() string {
+	if m != nil {
+		return m.FilterQuery
+	}
+	return ""
+}
+
+func (m *ListQuery) GetIncludeDeleted() bool {
+	if m != nil {
+		return m.IncludeDeleted
+	}
+	return false
+}
+
 type ListResponse struct {
 	Items                []*anypb.Any `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
 	PageInfo             *PageInfo    `protobuf:"bytes,2,opt,name=pageInfo,proto3" json:"pageInfo,omitempty"`
@@ -597,48 +645,51 @@ func init() {
 }
 
 var fileDescriptor_pagination_a737e02410ffdbad = []byte{
-	// 679 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x6e, 0x13, 0x31,
-	0x10, 0xee, 0x66, 0x7f, 0x92, 0x9d, 0x54, 0x6d, 0xb0, 0x2a, 0x58, 0xda, 0x0a, 0x85, 0x20, 0xa1,
-	0xd0, 0x43, 0x02, 0x29, 0x07, 0x50, 0xc5, 0xa1, 0xa4, 0x95, 0xa8, 0x84, 0x4a, 0xd9, 0x20, 0x90,
-	0xb8, 0x20, 0x27, 0xeb, 0x6c, 0x57, 0xda, 0xda, 0x91, 0xed, 0x05, 0x5a, 0xf1, 0x28, 0x3c, 0x18,
-	0xef, 0xc0, 0x4b, 0x20, 0xdb, 0x9b, 0xae, 0x93, 0x16, 0x35, 0x37, 0xcf, 0xcc, 0x37, 0xe3, 0x99,
-	0x6f, 0x3e, 0x1b, 0x1e, 0xcf, 0x38, 0x93, 0xac, 0x3f, 0xc3, 0x69, 0x46, 0xb1, 0xcc, 0x18, 0xb5,
-	0x8e, 0x3d, 0x1d, 0x43, 0x50, 0x79, 0xb6, 0x1f, 0xa6, 0x8c, 0xa5, 0x39, 0xe9, 0xeb, 0xc8, 0xb8,
-	0x98, 0xf6, 0x31, 0xbd, 0x34, 0xb0, 0xed, 0x47, 0xcb, 0xa1, 0x1f, 0x1c, 0xcf, 0x66, 0x84, 0x0b,
-	0x13, 0xef, 0x7c, 0x01, 0xff, 0x03, 0x4f, 0x08, 0x47, 0x5b, 0xe0, 0x4f, 0x33, 0x92, 0x27, 0x91,
-	0xd3, 0x76, 0xba, 0x61, 0x6c, 0x0c, 0xf4, 0x0a, 0xc2, 0x24, 0xe3, 0x64, 0xa2, 0xae, 0x89, 0x6a,
-	0x6d, 0xa7, 0xbb, 0x31, 0xd8, 0xee, 0x59, 0xbd, 0xe8, 0xdc, 0xa3, 0x39, 0x22, 0xae, 0xc0, 0x9d,
-	0xdf, 0x0e, 0x34, 0xce, 0x70, 0x4a, 0x4e, 0xe8, 0x94, 0xa9, 0xe2, 0x92, 0x49, 0x9c, 0xeb, 0xe2,
-	0x6e, 0x6c, 0x0c, 0x14, 0x41, 0xfd, 0x1c, 0x8b, 0x53, 0xf2, 0x53, 0xea, 0xd2, 0x8d, 0x78, 0x6e,
-	0xa2, 0x36, 0x34, 0xcf, 0xb1, 0x38, 0xe3, 0xe4, 0x7b, 0xc6, 0x0a, 0x11, 0xb9, 0x3a, 0x6a, 0xbb,
-	0x14, 0x42, 0x48, 0xcc, 0xe5, 0xb0, 0xe0, 0x82, 0xf1, 0xc8, 0xd3, 0x4d, 0xdb, 0x2e, 0xb4, 0x0b,
-	0x21, 0xa1, 0x49, 0x19, 0xf7, 0x75, 0xbc, 0x72, 0x74, 0xfe, 0xd4, 0x60, 0x73, 0xc8, 0x28, 0x35,
-	0xdd, 0x7e, 0x2c, 0x08, 0xbf, 0x44, 0x2f, 0x14, 0x05, 0x5c, 0x98, 0x6e, 0x9a, 0x83, 0x9d, 0x9e,
-	0xe1, 0xae, 0x37, 0xe7, 0xae, 0x77, 0x42, 0xe5, 0xfe, 0xe0, 0x33, 0xce, 0x0b, 0x12, 0x1b, 0x24,
-	0xea, 0x83, 0x97, 0x63, 0x21, 0xf5, 0xfd, 0x77, 0x64, 0x68, 0x20, 0x1a, 0x80, 0x8f, 0xa7, 0x92,
-	0xf0, 0x28, 0xd0, 0x19, 0xbb, 0x37, 0x32, 0x46, 0x92, 0x67, 0x34, 0x2d, 0x2f, 0xd1, 0x50, 0xf4,
-	0x12, 0x82, 0x31, 0x99, 0x32, 0x4e, 0xa2, 0xc6, 0x0a, 0x49, 0x25, 0x56, 0xcd, 0x4f, 0x09, 0x49,
-	0x3e, 0x69, 0xde, 0x43, 0xcd, 0x60, 0xe5, 0x40, 0xf7, 0x21, 0xd0, 0x1b, 0x16, 0x11, 0xb4, 0xdd,
-	0x6e, 0x18, 0x97, 0x96, 0xf1, 0xe7, 0xaa, 0xc1, 0xa6, 0xa6, 0xac, 0xb4, 0xd0, 0x33, 0x08, 0x98,
-	0xda, 0xb5, 0x88, 0xd6, 0xdb, 0x6e, 0xb7, 0x39, 0xb8, 0x77, 0x43, 0x05, 0x71, 0x09, 0xe8, 0xfc,
-	0x02, 0xa8, 0x98, 0x45, 0xcf, 0xa1, 0x31, 0x2b, 0x65, 0xa0, 0xb7, 0xdf, 0x1c, 0x6c, 0xd9, 0xa9,
-	0x73, 0x89, 0xc4, 0xd7, 0xa8, 0x4a, 0x2c, 0x35, 0x5b, 0x2c, 0x4f, 0xc1, 0x27, 0x49, 0x4a, 0x94,
-	0x18, 0xd4, 0xfd, 0x2d, 0xbb, 0xc8, 0x71, 0x92, 0x92, 0xd8, 0x84, 0x3b, 0xef, 0xc0, 0x53, 0x26,
-	0xea, 0x82, 0x47, 0x59, 0x42, 0xae, 0xef, 0x5c, 0xa6, 0xec, 0x90, 0x5e, 0xc6, 0x1a, 0xa1, 0x46,
-	0x9e, 0x18, 0x95, 0xd4, 0xcc, 0xc8, 0xc6, 0x52, 0x0a, 0x0e, 0x55, 0x7b, 0x46, 0x1c, 0x0f, 0xa0,
-	0xae, 0x3a, 0xfc, 0x46, 0x59, 0x29, 0xe2, 0x40, 0x99, 0xa7, 0x0c, 0xed, 0x40, 0xa8, 0x03, 0x22,
-	0xbb, 0x22, 0xba, 0x82, 0x6f, 0x66, 0x19, 0x65, 0x57, 0xc4, 0xa2, 0xd3, 0xfd, 0x0f, 0x9d, 0xde,
-	0x1d, 0x74, 0x5a, 0x9b, 0xf2, 0xed, 0x4d, 0x75, 0xfe, 0x3a, 0x10, 0xbe, 0xcf, 0x84, 0x34, 0xed,
-	0xbd, 0xb6, 0x1f, 0xaa, 0xa3, 0x1f, 0xea, 0x8e, 0x5d, 0xd3, 0xc8, 0xfe, 0xb6, 0x97, 0x6a, 0xf5,
-	0x58, 0x5b, 0xe8, 0x11, 0x81, 0xa7, 0x67, 0x72, 0xf5, 0x4c, 0xfa, 0x6c, 0x71, 0xe5, 0xd9, 0x5c,
-	0x2d, 0x8a, 0xcd, 0x5f, 0x16, 0x5b, 0x35, 0x6d, 0xb0, 0xfa, 0xb4, 0xf5, 0x85, 0x69, 0x73, 0x58,
-	0x57, 0xc3, 0xc6, 0x44, 0xcc, 0x18, 0x15, 0x04, 0xed, 0x81, 0x9f, 0x49, 0x72, 0x21, 0x22, 0x47,
-	0x57, 0xbc, 0x7d, 0xbf, 0x06, 0xb2, 0x20, 0xc1, 0xda, 0x2a, 0x12, 0xdc, 0x7b, 0x02, 0x1b, 0x8b,
-	0x3f, 0x1b, 0xaa, 0x83, 0x7b, 0x38, 0x1a, 0xb6, 0xd6, 0x50, 0x03, 0xbc, 0xa3, 0xe3, 0xd1, 0xb0,
-	0xe5, 0xec, 0x75, 0x61, 0x73, 0x89, 0x55, 0x04, 0xf3, 0x97, 0xda, 0x5a, 0x43, 0x61, 0xf9, 0xd2,
-	0x5b, 0xce, 0xdb, 0x37, 0x5f, 0x0f, 0xd2, 0x4c, 0x9e, 0x17, 0xe3, 0xde, 0x84, 0x5d, 0xf4, 0x93,
-	0x82, 0xe5, 0x78, 0x92, 0xb3, 0x22, 0xe9, 0x5f, 0x64, 0x13, 0xce, 0xc6, 0x58, 0x94, 0xff, 0xb3,
-	0xf5, 0xcb, 0x1f, 0x54, 0xc7, 0x71, 0xa0, 0x83, 0xfb, 0xff, 0x02, 0x00, 0x00, 0xff, 0xff, 0x1b,
-	0x0b, 0x7d, 0xe0, 0x16, 0x06, 0x00, 0x00,
+	// 730 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8d, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0xf1, 0x4f, 0xe2, 0x49, 0x49, 0xcb, 0xaa, 0x02, 0xd3, 0x56, 0xa8, 0x0d, 0x12, 0x94,
+	0x1e, 0x12, 0x48, 0x39, 0x80, 0x2a, 0x0e, 0xa5, 0xad, 0x44, 0x25, 0x54, 0x8a, 0x8b, 0x40, 0xe2,
+	0x12, 0x39, 0xf1, 0xc6, 0xb5, 0xe4, 0xee, 0x86, 0xdd, 0x35, 0x50, 0xc4, 0xa3, 0x70, 0xe4, 0x1d,
+	0x78, 0x25, 0x1e, 0x03, 0xef, 0xae, 0x53, 0x6f, 0xd2, 0x96, 0xe6, 0xb6, 0x33, 0xf3, 0xcd, 0x7a,
+	0xe6, 0xfb, 0x66, 0xc7, 0xb0, 0x31, 0x66, 0x54, 0xd0, 0xee, 0x38, 0x4a, 0x52, 0x12, 0x89, 0x94,
+	0x12, 0xe3, 0xd8, 0x51, 0x31, 0x04, 0x95, 0x67, 0xe5, 0x7e, 0x42, 0x69, 0x92, 0xe1, 0xae, 0x8a,
+	0x0c, 0xf2, 0x51, 0x37, 0x22, 0xe7, 0x1a, 0xb6, 0xf2, 0x60, 0x36, 0xf4, 0x8d, 0x45, 0xe3, 0x31,
+	0x66, 0x5c, 0xc7, 0xdb, 0x9f, 0xc0, 0x7d, 0xc7, 0x62, 0xcc, 0xd0, 0x32, 0xb8, 0xa3, 0x14, 0x67,
+	0x71, 0x60, 0xad, 0x5b, 0x9b, 0x7e, 0xa8, 0x0d, 0xf4, 0x02, 0xfc, 0x38, 0x65, 0x78, 0x28, 0x3f,
+	0x13, 0xd4, 0x8a, 0x48, 0xab, 0xb7, 0xd2, 0x31, 0x6a, 0x51, 0xb9, 0xfb, 0x13, 0x44, 0x58, 0x81,
+	0xdb, 0xbf, 0x2c, 0x68, 0x1c, 0x47, 0x09, 0x3e, 0x24, 0x23, 0x2a, 0x2f, 0x17, 0x54, 0x44, 0x99,
+	0xba, 0xdc, 0x0e, 0xb5, 0x81, 0x02, 0xa8, 0x9f, 0x46, 0xfc, 0x08, 0x7f, 0x17, 0xea, 0xea, 0x46,
+	0x38, 0x31, 0xd1, 0x3a, 0x34, 0x8b, 0xe3, 0x31, 0xc3, 0x5f, 0x53, 0x9a, 0xf3, 0xc0, 0x56, 0x51,
+	0xd3, 0x25, 0x11, 0x5c, 0x44, 0x4c, 0xec, 0xe5, 0x8c, 0x53, 0x16, 0x38, 0xaa, 0x68, 0xd3, 0x85,
+	0xd6, 0xc0, 0xc7, 0x24, 0x2e, 0xe3, 0xae, 0x8a, 0x57, 0x8e, 0xf6, 0x6f, 0x1b, 0x16, 0xf7, 0x28,
+	0x21, 0xba, 0xda, 0xf7, 0x39, 0x66, 0xe7, 0xe8, 0x99, 0xa4, 0x80, 0x71, 0x5d, 0x4d, 0xb3, 0xb7,
+	0xda, 0xd1, 0xdc, 0x75, 0x26, 0xdc, 0x75, 0x0e, 0x89, 0xd8, 0xee, 0x7d, 0x8c, 0xb2, 0x1c, 0x87,
+	0x1a, 0x89, 0xba, 0xe0, 0x64, 0x51, 0x91, 0xe1, 0xdc, 0x9c, 0xa1, 0x80, 0xa8, 0x07, 0x6e, 0x34,
+	0x12, 0x98, 0x05, 0x9e, 0xca, 0x58, 0xbb, 0x94, 0x71, 0x22, 0x58, 0x4a, 0x92, 0xf2, 0x23, 0x0a,
+	0x8a, 0x9e, 0x83, 0x37, 0xc0, 0x23, 0xca, 0x70, 0xd0, 0x98, 0x23, 0xa9, 0xc4, 0xca, 0xfe, 0x09,
+	0xc6, 0xf1, 0x07, 0xc5, 0xbb, 0xaf, 0x18, 0xac, 0x1c, 0xe8, 0x2e, 0x78, 0x4a, 0x61, 0x1e, 0xc0,
+	0xba, 0x5d, 0x50, 0x53, 0x5a, 0xda, 0x9f, 0xc9, 0x02, 0x9b, 0x8a, 0xb2, 0xd2, 0x42, 0x4f, 0xc0,
+	0xa3, 0x52, 0x6b, 0x1e, 0x2c, 0x14, 0xf8, 0x66, 0xef, 0xce, 0xa5, 0x29, 0x08, 0x4b, 0x00, 0xda,
+	0x80, 0x05, 0x9d, 0xd4, 0xff, 0x22, 0x69, 0x0d, 0x6e, 0x6b, 0x6d, 0xb4, 0x4f, 0x33, 0xfd, 0x18,
+	0x16, 0x53, 0x32, 0xcc, 0xf2, 0x18, 0xf7, 0x63, 0x9c, 0x61, 0x81, 0xe3, 0xa0, 0xa5, 0x2a, 0x6c,
+	0x95, 0xee, 0x7d, 0xed, 0x6d, 0xff, 0x04, 0xa8, 0x54, 0x42, 0x4f, 0xa1, 0x31, 0x2e, 0x47, 0x4a,
+	0x4d, 0x52, 0xb3, 0xb7, 0x6c, 0x96, 0x31, 0x19, 0xb7, 0xf0, 0x02, 0x55, 0x0d, 0x5e, 0xcd, 0x1c,
+	0xbc, 0x47, 0xe0, 0xe2, 0x38, 0xc1, 0x72, 0xb0, 0x64, 0x2f, 0x4b, 0xe6, 0x25, 0x07, 0x45, 0x20,
+	0xd4, 0xe1, 0xf6, 0x1b, 0x70, 0xa4, 0x89, 0x36, 0xc1, 0x21, 0x34, 0xc6, 0x17, 0xdf, 0x9c, 0xa5,
+	0x7f, 0x97, 0x9c, 0x87, 0x0a, 0x21, 0xe9, 0x1b, 0xea, 0x89, 0xab, 0x69, 0xfa, 0xb4, 0xd5, 0xfe,
+	0x6b, 0x81, 0x2f, 0xcb, 0xd3, 0xed, 0xdf, 0x83, 0xba, 0xac, 0xb0, 0x4f, 0x68, 0xf9, 0x20, 0x3c,
+	0x69, 0x1e, 0x51, 0xb4, 0x0a, 0xbe, 0x0a, 0xf0, 0xf4, 0x07, 0x56, 0x37, 0xb8, 0xba, 0x97, 0x93,
+	0xc2, 0x36, 0xa4, 0xb1, 0xaf, 0x91, 0xc6, 0xb9, 0x49, 0x9a, 0x4a, 0x75, 0x77, 0x4a, 0xf5, 0x59,
+	0xc9, 0xbc, 0xb9, 0x24, 0xab, 0x5f, 0x29, 0xd9, 0x9f, 0x1a, 0xf8, 0x6f, 0x53, 0x2e, 0x74, 0xda,
+	0x4b, 0x73, 0x81, 0x58, 0x6a, 0x81, 0xac, 0x9a, 0xf5, 0xe9, 0xe7, 0x78, 0xd5, 0x06, 0x31, 0xfa,
+	0xad, 0x4d, 0xf5, 0x8b, 0xc0, 0x51, 0xfc, 0xd8, 0x8a, 0x1f, 0x75, 0x36, 0x78, 0x77, 0x4c, 0xde,
+	0xa7, 0x1f, 0x81, 0x3b, 0xfb, 0x08, 0x2a, 0xe6, 0xbc, 0xf9, 0x99, 0xab, 0xff, 0x97, 0xb9, 0xc6,
+	0x5c, 0xcc, 0xf9, 0x57, 0x32, 0x97, 0xc1, 0x82, 0x24, 0x2e, 0xc4, 0x7c, 0x4c, 0x09, 0xc7, 0x68,
+	0x0b, 0xdc, 0x54, 0xe0, 0x33, 0x5e, 0xf0, 0x66, 0x5f, 0x3b, 0x77, 0x1a, 0x32, 0xf5, 0x34, 0x6a,
+	0xf3, 0x3c, 0x8d, 0xad, 0x87, 0xd0, 0x9a, 0xde, 0xde, 0xa8, 0x0e, 0xf6, 0xee, 0xc9, 0xde, 0xd2,
+	0x2d, 0xd4, 0x00, 0x67, 0xff, 0xa0, 0x38, 0x59, 0x5b, 0x9b, 0xc5, 0x96, 0x9c, 0x56, 0x08, 0xc1,
+	0x64, 0x1b, 0x15, 0x40, 0xbf, 0xdc, 0x66, 0x4b, 0xd6, 0xeb, 0x57, 0x9f, 0x77, 0x92, 0x54, 0x9c,
+	0xe6, 0x83, 0xce, 0x90, 0x9e, 0x75, 0xe3, 0x9c, 0x66, 0xd1, 0x30, 0xa3, 0x79, 0xdc, 0x3d, 0x4b,
+	0x87, 0x8c, 0x0e, 0x22, 0x5e, 0xfe, 0x83, 0x8c, 0x3f, 0xd9, 0x4e, 0x75, 0x1c, 0x78, 0x2a, 0xb8,
+	0xfd, 0x0f, 0x2e, 0x34, 0x46, 0x03, 0xfa, 0x06, 0x00, 0x00,
 }
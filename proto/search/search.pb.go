@@ -567,6 +567,8 @@ type Document struct {
 	Index                string   `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
 	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	Fields               string   `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
+	SeqNo                int64    `protobuf:"varint,4,opt,name=seq_no,json=seqNo,proto3" json:"seq_no,omitempty"`
+	PrimaryTerm          int64    `protobuf:"varint,5,opt,name=primary_term,json=primaryTerm,proto3" json:"primary_term,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -617,8 +619,23 @@ func (m *Document) GetFields() string {
 	return ""
 }
 
+func (m *Document) GetSeqNo() int64 {
+	if m != nil {
+		return m.SeqNo
+	}
+	return 0
+}
+
+func (m *Document) GetPrimaryTerm() int64 {
+	if m != nil {
+		return m.PrimaryTerm
+	}
+	return 0
+}
+
 type BatchUpsertDocumentRequest struct {
 	Document             []*Document `protobuf:"bytes,1,rep,name=document,proto3" json:"document,omitempty"`
+	Create               bool        `protobuf:"varint,2,opt,name=create,proto3" json:"create,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
@@ -655,6 +672,13 @@ func (m *BatchUpsertDocumentRequest) GetDocument() []*Document {
 	return nil
 }
 
+func (m *BatchUpsertDocumentRequest) GetCreate() bool {
+	if m != nil {
+		return m.Create
+	}
+	return false
+}
+
 type BatchUpsertDocumentResponse struct {
 	Items                []*UpsertDocumentResponse `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
@@ -695,6 +719,8 @@ func (m *BatchUpsertDocumentResponse) GetItems() []*UpsertDocumentResponse {
 
 type UpsertDocumentResponse struct {
 	Ack                  bool     `protobuf:"varint,1,opt,name=ack,proto3" json:"ack,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Status               int32    `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -731,6 +757,20 @@ func (m *UpsertDocumentResponse) GetAck() bool {
 	return false
 }
 
+func (m *UpsertDocumentResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *UpsertDocumentResponse) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
 type GetDocumentRequest struct {
 	Index                string   `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
 	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
@@ -1017,6 +1057,7 @@ func (m *DeleteDocumentRequest) GetId() string {
 
 type DeleteDocumentResponse struct {
 	Ack                  bool     `protobuf:"varint,1,opt,name=ack,proto3" json:"ack,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1053,6 +1094,621 @@ func (m *DeleteDocumentResponse) GetAck() bool {
 	return false
 }
 
+func (m *DeleteDocumentResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type BatchDeleteDocumentRequest struct {
+	Documents            []*DeleteDocumentRequest `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *BatchDeleteDocumentRequest) Reset()         { *m = BatchDeleteDocumentRequest{} }
+func (m *BatchDeleteDocumentRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchDeleteDocumentRequest) ProtoMessage()    {}
+func (*BatchDeleteDocumentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{22}
+}
+func (m *BatchDeleteDocumentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchDeleteDocumentRequest.Unmarshal(m, b)
+}
+func (m *BatchDeleteDocumentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchDeleteDocumentRequest.Marshal(b, m, deterministic)
+}
+func (dst *BatchDeleteDocumentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchDeleteDocumentRequest.Merge(dst, src)
+}
+func (m *BatchDeleteDocumentRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchDeleteDocumentRequest.Size(m)
+}
+func (m *BatchDeleteDocumentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchDeleteDocumentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchDeleteDocumentRequest proto.InternalMessageInfo
+
+func (m *BatchDeleteDocumentRequest) GetDocuments() []*DeleteDocumentRequest {
+	if m != nil {
+		return m.Documents
+	}
+	return nil
+}
+
+type BatchDeleteDocumentResponse struct {
+	Items                []*DeleteDocumentResponse `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *BatchDeleteDocumentResponse) Reset()         { *m = BatchDeleteDocumentResponse{} }
+func (m *BatchDeleteDocumentResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchDeleteDocumentResponse) ProtoMessage()    {}
+func (*BatchDeleteDocumentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{23}
+}
+func (m *BatchDeleteDocumentResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchDeleteDocumentResponse.Unmarshal(m, b)
+}
+func (m *BatchDeleteDocumentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchDeleteDocumentResponse.Marshal(b, m, deterministic)
+}
+func (dst *BatchDeleteDocumentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchDeleteDocumentResponse.Merge(dst, src)
+}
+func (m *BatchDeleteDocumentResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchDeleteDocumentResponse.Size(m)
+}
+func (m *BatchDeleteDocumentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchDeleteDocumentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchDeleteDocumentResponse proto.InternalMessageInfo
+
+func (m *BatchDeleteDocumentResponse) GetItems() []*DeleteDocumentResponse {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type CountRequest struct {
+	Index                string   `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Filter               string   `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CountRequest) Reset()         { *m = CountRequest{} }
+func (m *CountRequest) String() string { return proto.CompactTextString(m) }
+func (*CountRequest) ProtoMessage()    {}
+func (*CountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{24}
+}
+func (m *CountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CountRequest.Unmarshal(m, b)
+}
+func (m *CountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CountRequest.Marshal(b, m, deterministic)
+}
+func (dst *CountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CountRequest.Merge(dst, src)
+}
+func (m *CountRequest) XXX_Size() int {
+	return xxx_messageInfo_CountRequest.Size(m)
+}
+func (m *CountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CountRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CountRequest proto.InternalMessageInfo
+
+func (m *CountRequest) GetIndex() string {
+	if m != nil {
+		return m.Index
+	}
+	return ""
+}
+
+func (m *CountRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *CountRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+type CountResponse struct {
+	Count                int64    `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CountResponse) Reset()         { *m = CountResponse{} }
+func (m *CountResponse) String() string { return proto.CompactTextString(m) }
+func (*CountResponse) ProtoMessage()    {}
+func (*CountResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{25}
+}
+func (m *CountResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CountResponse.Unmarshal(m, b)
+}
+func (m *CountResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CountResponse.Marshal(b, m, deterministic)
+}
+func (dst *CountResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CountResponse.Merge(dst, src)
+}
+func (m *CountResponse) XXX_Size() int {
+	return xxx_messageInfo_CountResponse.Size(m)
+}
+func (m *CountResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CountResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CountResponse proto.InternalMessageInfo
+
+func (m *CountResponse) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type UpdateByQueryRequest struct {
+	Index                string   `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Filter               string   `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	Fields               string   `protobuf:"bytes,4,opt,name=fields,proto3" json:"fields,omitempty"`
+	Increments           []string `protobuf:"bytes,5,rep,name=increments,proto3" json:"increments,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateByQueryRequest) Reset()         { *m = UpdateByQueryRequest{} }
+func (m *UpdateByQueryRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateByQueryRequest) ProtoMessage()    {}
+func (*UpdateByQueryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{26}
+}
+func (m *UpdateByQueryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateByQueryRequest.Unmarshal(m, b)
+}
+func (m *UpdateByQueryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateByQueryRequest.Marshal(b, m, deterministic)
+}
+func (dst *UpdateByQueryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateByQueryRequest.Merge(dst, src)
+}
+func (m *UpdateByQueryRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateByQueryRequest.Size(m)
+}
+func (m *UpdateByQueryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateByQueryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateByQueryRequest proto.InternalMessageInfo
+
+func (m *UpdateByQueryRequest) GetIndex() string {
+	if m != nil {
+		return m.Index
+	}
+	return ""
+}
+
+func (m *UpdateByQueryRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *UpdateByQueryRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *UpdateByQueryRequest) GetFields() string {
+	if m != nil {
+		return m.Fields
+	}
+	return ""
+}
+
+func (m *UpdateByQueryRequest) GetIncrements() []string {
+	if m != nil {
+		return m.Increments
+	}
+	return nil
+}
+
+type DeleteByQueryRequest struct {
+	Index                string   `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Filter               string   `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteByQueryRequest) Reset()         { *m = DeleteByQueryRequest{} }
+func (m *DeleteByQueryRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteByQueryRequest) ProtoMessage()    {}
+func (*DeleteByQueryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{27}
+}
+func (m *DeleteByQueryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteByQueryRequest.Unmarshal(m, b)
+}
+func (m *DeleteByQueryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteByQueryRequest.Marshal(b, m, deterministic)
+}
+func (dst *DeleteByQueryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteByQueryRequest.Merge(dst, src)
+}
+func (m *DeleteByQueryRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteByQueryRequest.Size(m)
+}
+func (m *DeleteByQueryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteByQueryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteByQueryRequest proto.InternalMessageInfo
+
+func (m *DeleteByQueryRequest) GetIndex() string {
+	if m != nil {
+		return m.Index
+	}
+	return ""
+}
+
+func (m *DeleteByQueryRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *DeleteByQueryRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+type ByQueryResponse struct {
+	Affected             int64    `protobuf:"varint,1,opt,name=affected,proto3" json:"affected,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ByQueryResponse) Reset()         { *m = ByQueryResponse{} }
+func (m *ByQueryResponse) String() string { return proto.CompactTextString(m) }
+func (*ByQueryResponse) ProtoMessage()    {}
+func (*ByQueryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{28}
+}
+func (m *ByQueryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ByQueryResponse.Unmarshal(m, b)
+}
+func (m *ByQueryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ByQueryResponse.Marshal(b, m, deterministic)
+}
+func (dst *ByQueryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ByQueryResponse.Merge(dst, src)
+}
+func (m *ByQueryResponse) XXX_Size() int {
+	return xxx_messageInfo_ByQueryResponse.Size(m)
+}
+func (m *ByQueryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ByQueryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ByQueryResponse proto.InternalMessageInfo
+
+func (m *ByQueryResponse) GetAffected() int64 {
+	if m != nil {
+		return m.Affected
+	}
+	return 0
+}
+
+type Metric struct {
+	Func                 string   `protobuf:"bytes,1,opt,name=func,proto3" json:"func,omitempty"`
+	Field                string   `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Alias                string   `protobuf:"bytes,3,opt,name=alias,proto3" json:"alias,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Metric) Reset()         { *m = Metric{} }
+func (m *Metric) String() string { return proto.CompactTextString(m) }
+func (*Metric) ProtoMessage()    {}
+func (*Metric) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{29}
+}
+func (m *Metric) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Metric.Unmarshal(m, b)
+}
+func (m *Metric) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Metric.Marshal(b, m, deterministic)
+}
+func (dst *Metric) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Metric.Merge(dst, src)
+}
+func (m *Metric) XXX_Size() int {
+	return xxx_messageInfo_Metric.Size(m)
+}
+func (m *Metric) XXX_DiscardUnknown() {
+	xxx_messageInfo_Metric.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Metric proto.InternalMessageInfo
+
+func (m *Metric) GetFunc() string {
+	if m != nil {
+		return m.Func
+	}
+	return ""
+}
+
+func (m *Metric) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *Metric) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+type DateHistogram struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Interval             string   `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DateHistogram) Reset()         { *m = DateHistogram{} }
+func (m *DateHistogram) String() string { return proto.CompactTextString(m) }
+func (*DateHistogram) ProtoMessage()    {}
+func (*DateHistogram) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{30}
+}
+func (m *DateHistogram) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DateHistogram.Unmarshal(m, b)
+}
+func (m *DateHistogram) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DateHistogram.Marshal(b, m, deterministic)
+}
+func (dst *DateHistogram) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DateHistogram.Merge(dst, src)
+}
+func (m *DateHistogram) XXX_Size() int {
+	return xxx_messageInfo_DateHistogram.Size(m)
+}
+func (m *DateHistogram) XXX_DiscardUnknown() {
+	xxx_messageInfo_DateHistogram.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DateHistogram proto.InternalMessageInfo
+
+func (m *DateHistogram) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *DateHistogram) GetInterval() string {
+	if m != nil {
+		return m.Interval
+	}
+	return ""
+}
+
+type AggregateRequest struct {
+	Index                string         `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
+	Type                 string         `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Filter               string         `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	GroupBy              []string       `protobuf:"bytes,4,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Histogram            *DateHistogram `protobuf:"bytes,5,opt,name=histogram,proto3" json:"histogram,omitempty"`
+	Metrics              []*Metric      `protobuf:"bytes,6,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	Size                 int32          `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	FilterQuery          string         `protobuf:"bytes,8,opt,name=filter_query,json=filterQuery,proto3" json:"filter_query,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *AggregateRequest) Reset()         { *m = AggregateRequest{} }
+func (m *AggregateRequest) String() string { return proto.CompactTextString(m) }
+func (*AggregateRequest) ProtoMessage()    {}
+func (*AggregateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{31}
+}
+func (m *AggregateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AggregateRequest.Unmarshal(m, b)
+}
+func (m *AggregateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AggregateRequest.Marshal(b, m, deterministic)
+}
+func (dst *AggregateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AggregateRequest.Merge(dst, src)
+}
+func (m *AggregateRequest) XXX_Size() int {
+	return xxx_messageInfo_AggregateRequest.Size(m)
+}
+func (m *AggregateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AggregateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AggregateRequest proto.InternalMessageInfo
+
+func (m *AggregateRequest) GetIndex() string {
+	if m != nil {
+		return m.Index
+	}
+	return ""
+}
+
+func (m *AggregateRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *AggregateRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *AggregateRequest) GetGroupBy() []string {
+	if m != nil {
+		return m.GroupBy
+	}
+	return nil
+}
+
+func (m *AggregateRequest) GetHistogram() *DateHistogram {
+	if m != nil {
+		return m.Histogram
+	}
+	return nil
+}
+
+func (m *AggregateRequest) GetMetrics() []*Metric {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+func (m *AggregateRequest) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *AggregateRequest) GetFilterQuery() string {
+	if m != nil {
+		return m.FilterQuery
+	}
+	return ""
+}
+
+type AggregateBucket struct {
+	Keys                 string   `protobuf:"bytes,1,opt,name=keys,proto3" json:"keys,omitempty"`
+	Metrics              string   `protobuf:"bytes,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AggregateBucket) Reset()         { *m = AggregateBucket{} }
+func (m *AggregateBucket) String() string { return proto.CompactTextString(m) }
+func (*AggregateBucket) ProtoMessage()    {}
+func (*AggregateBucket) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{32}
+}
+func (m *AggregateBucket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AggregateBucket.Unmarshal(m, b)
+}
+func (m *AggregateBucket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AggregateBucket.Marshal(b, m, deterministic)
+}
+func (dst *AggregateBucket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AggregateBucket.Merge(dst, src)
+}
+func (m *AggregateBucket) XXX_Size() int {
+	return xxx_messageInfo_AggregateBucket.Size(m)
+}
+func (m *AggregateBucket) XXX_DiscardUnknown() {
+	xxx_messageInfo_AggregateBucket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AggregateBucket proto.InternalMessageInfo
+
+func (m *AggregateBucket) GetKeys() string {
+	if m != nil {
+		return m.Keys
+	}
+	return ""
+}
+
+func (m *AggregateBucket) GetMetrics() string {
+	if m != nil {
+		return m.Metrics
+	}
+	return ""
+}
+
+type AggregateResponse struct {
+	Buckets              []*AggregateBucket `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *AggregateResponse) Reset()         { *m = AggregateResponse{} }
+func (m *AggregateResponse) String() string { return proto.CompactTextString(m) }
+func (*AggregateResponse) ProtoMessage()    {}
+func (*AggregateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_search_dbb59cd932b0a9a4, []int{33}
+}
+func (m *AggregateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AggregateResponse.Unmarshal(m, b)
+}
+func (m *AggregateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AggregateResponse.Marshal(b, m, deterministic)
+}
+func (dst *AggregateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AggregateResponse.Merge(dst, src)
+}
+func (m *AggregateResponse) XXX_Size() int {
+	return xxx_messageInfo_AggregateResponse.Size(m)
+}
+func (m *AggregateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AggregateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AggregateResponse proto.InternalMessageInfo
+
+func (m *AggregateResponse) GetBuckets() []*AggregateBucket {
+	if m != nil {
+		return m.Buckets
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*IndexExistsRequest)(nil), "search.IndexExistsRequest")
 	proto.RegisterType((*IndexExistsResponse)(nil), "search.IndexExistsResponse")
@@ -1076,6 +1732,18 @@ func init() {
 	proto.RegisterType((*SearchResponse)(nil), "search.SearchResponse")
 	proto.RegisterType((*DeleteDocumentRequest)(nil), "search.DeleteDocumentRequest")
 	proto.RegisterType((*DeleteDocumentResponse)(nil), "search.DeleteDocumentResponse")
+	proto.RegisterType((*BatchDeleteDocumentRequest)(nil), "search.BatchDeleteDocumentRequest")
+	proto.RegisterType((*BatchDeleteDocumentResponse)(nil), "search.BatchDeleteDocumentResponse")
+	proto.RegisterType((*CountRequest)(nil), "search.CountRequest")
+	proto.RegisterType((*CountResponse)(nil), "search.CountResponse")
+	proto.RegisterType((*UpdateByQueryRequest)(nil), "search.UpdateByQueryRequest")
+	proto.RegisterType((*DeleteByQueryRequest)(nil), "search.DeleteByQueryRequest")
+	proto.RegisterType((*ByQueryResponse)(nil), "search.ByQueryResponse")
+	proto.RegisterType((*Metric)(nil), "search.Metric")
+	proto.RegisterType((*DateHistogram)(nil), "search.DateHistogram")
+	proto.RegisterType((*AggregateRequest)(nil), "search.AggregateRequest")
+	proto.RegisterType((*AggregateBucket)(nil), "search.AggregateBucket")
+	proto.RegisterType((*AggregateResponse)(nil), "search.AggregateResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1097,9 +1765,14 @@ type SearchServiceClient interface {
 	Get(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*Document, error)
 	BatchGet(ctx context.Context, in *BatchGetDocumentRequest, opts ...grpc.CallOption) (*BatchGetDocumentResponse, error)
 	Delete(ctx context.Context, in *DeleteDocumentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteDocumentRequest, opts ...grpc.CallOption) (*BatchDeleteDocumentResponse, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	Page(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*PageResponse, error)
+	Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	UpdateByQuery(ctx context.Context, in *UpdateByQueryRequest, opts ...grpc.CallOption) (*ByQueryResponse, error)
+	DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, opts ...grpc.CallOption) (*ByQueryResponse, error)
+	Aggregate(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (*AggregateResponse, error)
 	// graphql 查询模式查询结果
 	Connection(ctx context.Context, in *ConnectionRequest, opts ...grpc.CallOption) (*pagination.Connection, error)
 	CreateIndex(ctx context.Context, in *CreateIndexRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
@@ -1178,6 +1851,15 @@ func (c *searchServiceClient) Delete(ctx context.Context, in *DeleteDocumentRequ
 	return out, nil
 }
 
+func (c *searchServiceClient) BatchDelete(ctx context.Context, in *BatchDeleteDocumentRequest, opts ...grpc.CallOption) (*BatchDeleteDocumentResponse, error) {
+	out := new(BatchDeleteDocumentResponse)
+	err := c.cc.Invoke(ctx, "/search.SearchService/BatchDelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *searchServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
 	out := new(SearchResponse)
 	err := c.cc.Invoke(ctx, "/search.SearchService/Search", in, out, opts...)
@@ -1205,6 +1887,42 @@ func (c *searchServiceClient) Page(ctx context.Context, in *PageRequest, opts ..
 	return out, nil
 }
 
+func (c *searchServiceClient) Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/search.SearchService/Count", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) UpdateByQuery(ctx context.Context, in *UpdateByQueryRequest, opts ...grpc.CallOption) (*ByQueryResponse, error) {
+	out := new(ByQueryResponse)
+	err := c.cc.Invoke(ctx, "/search.SearchService/UpdateByQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, opts ...grpc.CallOption) (*ByQueryResponse, error) {
+	out := new(ByQueryResponse)
+	err := c.cc.Invoke(ctx, "/search.SearchService/DeleteByQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) Aggregate(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (*AggregateResponse, error) {
+	out := new(AggregateResponse)
+	err := c.cc.Invoke(ctx, "/search.SearchService/Aggregate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *searchServiceClient) Connection(ctx context.Context, in *ConnectionRequest, opts ...grpc.CallOption) (*pagination.Connection, error) {
 	out := new(pagination.Connection)
 	err := c.cc.Invoke(ctx, "/search.SearchService/Connection", in, out, opts...)
@@ -1250,9 +1968,14 @@ type SearchServiceServer interface {
 	Get(context.Context, *GetDocumentRequest) (*Document, error)
 	BatchGet(context.Context, *BatchGetDocumentRequest) (*BatchGetDocumentResponse, error)
 	Delete(context.Context, *DeleteDocumentRequest) (*emptypb.Empty, error)
+	BatchDelete(context.Context, *BatchDeleteDocumentRequest) (*BatchDeleteDocumentResponse, error)
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	List(context.Context, *ListRequest) (*ListResponse, error)
 	Page(context.Context, *PageRequest) (*PageResponse, error)
+	Count(context.Context, *CountRequest) (*CountResponse, error)
+	UpdateByQuery(context.Context, *UpdateByQueryRequest) (*ByQueryResponse, error)
+	DeleteByQuery(context.Context, *DeleteByQueryRequest) (*ByQueryResponse, error)
+	Aggregate(context.Context, *AggregateRequest) (*AggregateResponse, error)
 	// graphql 查询模式查询结果
 	Connection(context.Context, *ConnectionRequest) (*pagination.Connection, error)
 	CreateIndex(context.Context, *CreateIndexRequest) (*emptypb.Empty, error)
@@ -1390,6 +2113,24 @@ func _SearchService_Delete_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SearchService_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.SearchService/BatchDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).BatchDelete(ctx, req.(*BatchDeleteDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SearchService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchRequest)
 	if err := dec(in); err != nil {
@@ -1444,6 +2185,78 @@ func _SearchService_Page_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SearchService_Count_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).Count(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.SearchService/Count",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).Count(ctx, req.(*CountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_UpdateByQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateByQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).UpdateByQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.SearchService/UpdateByQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).UpdateByQuery(ctx, req.(*UpdateByQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_DeleteByQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteByQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).DeleteByQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.SearchService/DeleteByQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).DeleteByQuery(ctx, req.(*DeleteByQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_Aggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).Aggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/search.SearchService/Aggregate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).Aggregate(ctx, req.(*AggregateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SearchService_Connection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ConnectionRequest)
 	if err := dec(in); err != nil {
@@ -1548,6 +2361,10 @@ var _SearchService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _SearchService_Delete_Handler,
 		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _SearchService_BatchDelete_Handler,
+		},
 		{
 			MethodName: "Search",
 			Handler:    _SearchService_Search_Handler,
@@ -1560,6 +2377,22 @@ var _SearchService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Page",
 			Handler:    _SearchService_Page_Handler,
 		},
+		{
+			MethodName: "Count",
+			Handler:    _SearchService_Count_Handler,
+		},
+		{
+			MethodName: "UpdateByQuery",
+			Handler:    _SearchService_UpdateByQuery_Handler,
+		},
+		{
+			MethodName: "DeleteByQuery",
+			Handler:    _SearchService_DeleteByQuery_Handler,
+		},
+		{
+			MethodName: "Aggregate",
+			Handler:    _SearchService_Aggregate_Handler,
+		},
 		{
 			MethodName: "Connection",
 			Handler:    _SearchService_Connection_Handler,
@@ -1584,63 +2417,92 @@ var _SearchService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("proto/search/search.proto", fileDescriptor_search_dbb59cd932b0a9a4) }
 
 var fileDescriptor_search_dbb59cd932b0a9a4 = []byte{
-	// 922 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0x6d, 0x73, 0xd3, 0x46,
-	0x10, 0x4e, 0xec, 0xd8, 0xb1, 0x57, 0x81, 0x49, 0x8f, 0xc4, 0x35, 0x72, 0x5f, 0xd2, 0xe3, 0x0b,
-	0x13, 0x5a, 0x7b, 0x1a, 0xca, 0x74, 0x32, 0xfd, 0x04, 0x86, 0x52, 0x18, 0x86, 0x12, 0xa5, 0xed,
-	0x74, 0xfa, 0x85, 0xb9, 0x48, 0x17, 0xf9, 0x06, 0x5b, 0x27, 0x74, 0x27, 0x1a, 0xe7, 0x67, 0xf4,
-	0x97, 0xf5, 0x27, 0x31, 0xf7, 0x66, 0xc9, 0x91, 0x6c, 0x20, 0xc3, 0x27, 0xdd, 0xed, 0x3d, 0xb7,
-	0x2f, 0xcf, 0xed, 0x6a, 0x17, 0x6e, 0xa7, 0x19, 0x97, 0x7c, 0x24, 0x28, 0xc9, 0xc2, 0x89, 0xfd,
-	0x0c, 0xb5, 0x0c, 0xb5, 0xcd, 0xce, 0x1f, 0xc4, 0x9c, 0xc7, 0x53, 0x3a, 0xd2, 0xd2, 0xb3, 0xfc,
-	0x7c, 0x44, 0x67, 0xa9, 0x9c, 0x1b, 0x90, 0xff, 0x30, 0x66, 0x72, 0x92, 0x9f, 0x0d, 0x43, 0x3e,
-	0x1b, 0x45, 0x39, 0x9f, 0x92, 0x70, 0xca, 0xf3, 0x68, 0x34, 0x63, 0x61, 0xc6, 0xcf, 0x88, 0xb0,
-	0xb7, 0x46, 0x29, 0x89, 0x59, 0x42, 0x24, 0xe3, 0x49, 0x69, 0x69, 0x54, 0xe0, 0x43, 0x40, 0xcf,
-	0x92, 0x88, 0x5e, 0x3c, 0xb9, 0x60, 0x42, 0x8a, 0x80, 0xbe, 0xcd, 0xa9, 0x90, 0x68, 0x0f, 0x5a,
-	0x4c, 0x49, 0xfb, 0x9b, 0x07, 0x9b, 0x77, 0xbb, 0x81, 0xd9, 0xe0, 0x1f, 0xe0, 0xd6, 0x12, 0x56,
-	0xa4, 0x3c, 0x11, 0x14, 0xf5, 0xa0, 0x4d, 0xb5, 0x44, 0xa3, 0x3b, 0x81, 0xdd, 0xe1, 0x08, 0xbc,
-	0x57, 0x24, 0xa6, 0x4e, 0xe7, 0x3d, 0x68, 0xbd, 0xcd, 0x69, 0x36, 0xd7, 0x28, 0xef, 0x68, 0x7f,
-	0x58, 0xf2, 0x45, 0xe1, 0x4e, 0xd4, 0x61, 0x60, 0x30, 0x85, 0x03, 0x8d, 0x92, 0x03, 0x08, 0xc1,
-	0x96, 0x9c, 0xa7, 0xb4, 0xdf, 0xd4, 0x42, 0xbd, 0xc6, 0x7f, 0xc0, 0x8e, 0xb1, 0x62, 0xbd, 0xd9,
-	0x83, 0x96, 0xe4, 0x92, 0x4c, 0xb5, 0x99, 0x66, 0x60, 0x36, 0x68, 0x08, 0xdd, 0x88, 0x87, 0xf9,
-	0x8c, 0x26, 0x52, 0xf4, 0x1b, 0x07, 0xcd, 0xbb, 0xde, 0xd1, 0xee, 0xd0, 0x12, 0xfe, 0xd8, 0x1e,
-	0x04, 0x05, 0x04, 0xa7, 0xf0, 0xc5, 0x98, 0x27, 0x09, 0x0d, 0x95, 0x7b, 0x2e, 0x82, 0x1f, 0x97,
-	0x23, 0x18, 0x94, 0x23, 0x28, 0xd0, 0xd7, 0x8c, 0x23, 0x02, 0xef, 0x05, 0x13, 0xf2, 0x63, 0xd8,
-	0x52, 0xb8, 0x6b, 0x5a, 0xf9, 0x7f, 0x13, 0x76, 0x8c, 0x19, 0x4b, 0xd7, 0x12, 0x31, 0x9b, 0x1f,
-	0x24, 0xa6, 0xa0, 0xb7, 0x51, 0xa6, 0xb7, 0x0f, 0xdb, 0x13, 0x22, 0x5e, 0xd2, 0x0b, 0xa9, 0xad,
-	0x75, 0x02, 0xb7, 0x45, 0x07, 0xe0, 0x4d, 0x88, 0x78, 0x95, 0xd1, 0x77, 0x8c, 0xe7, 0xa2, 0xbf,
-	0xa5, 0x4f, 0xcb, 0x22, 0x85, 0x10, 0x92, 0x64, 0x72, 0x9c, 0x67, 0x82, 0x67, 0xfd, 0x96, 0xf6,
-	0xb6, 0x2c, 0x42, 0x5f, 0x41, 0x97, 0x26, 0x91, 0x3d, 0x6f, 0xeb, 0xf3, 0x42, 0x80, 0x1f, 0x40,
-	0xeb, 0x99, 0x8b, 0x37, 0x21, 0x33, 0x6a, 0x73, 0x56, 0xaf, 0x95, 0x63, 0x33, 0x92, 0xa6, 0x2c,
-	0x89, 0x2d, 0x37, 0x6e, 0x8b, 0x4f, 0xc0, 0xfb, 0x95, 0xd1, 0x69, 0x34, 0xe6, 0xc9, 0x39, 0x8b,
-	0x6b, 0x2f, 0x3b, 0x02, 0x1b, 0x05, 0x81, 0xc8, 0x87, 0x0e, 0x49, 0xc8, 0x74, 0x7e, 0x49, 0x33,
-	0x4b, 0xec, 0x62, 0x8f, 0x8f, 0x01, 0x8d, 0x33, 0x4a, 0x24, 0xd5, 0xfe, 0xb8, 0x97, 0xbc, 0x53,
-	0xae, 0x25, 0xef, 0xe8, 0x86, 0x63, 0xd7, 0x80, 0x6c, 0x69, 0x1d, 0x02, 0x7a, 0x4c, 0xa7, 0xf4,
-	0xca, 0xd5, 0xfa, 0x32, 0x7c, 0x01, 0x1d, 0xf7, 0x32, 0xf5, 0x88, 0x5a, 0xc7, 0x7b, 0xd0, 0x3e,
-	0x57, 0xf1, 0x0a, 0xeb, 0xb6, 0xdd, 0xe1, 0xe7, 0xe0, 0x3f, 0x22, 0x32, 0x9c, 0xfc, 0x99, 0x0a,
-	0x9a, 0xc9, 0xc5, 0x93, 0x5b, 0x0f, 0xbe, 0x87, 0x8e, 0x7b, 0xfb, 0x95, 0xd9, 0xb1, 0x40, 0xe0,
-	0x53, 0x18, 0xd4, 0xea, 0xb2, 0xb9, 0xf6, 0x13, 0xb4, 0x98, 0xa4, 0x33, 0x97, 0x67, 0xdf, 0x38,
-	0x4d, 0xf5, 0xf0, 0xc0, 0x80, 0xf1, 0x21, 0xf4, 0x56, 0xe8, 0xdb, 0x85, 0x26, 0x09, 0xdf, 0xd8,
-	0xbf, 0x8e, 0x5a, 0xe2, 0x97, 0x80, 0x9e, 0xd2, 0x4a, 0x10, 0x1f, 0x4f, 0xd2, 0x4d, 0x68, 0xb0,
-	0xc8, 0x12, 0xd4, 0x60, 0x11, 0xbe, 0x07, 0x5f, 0xea, 0x80, 0x6a, 0x94, 0xee, 0x42, 0x93, 0x45,
-	0x26, 0x94, 0x6e, 0xa0, 0x96, 0xf8, 0x39, 0xf4, 0xab, 0xe0, 0xeb, 0x95, 0x19, 0x66, 0x70, 0xe3,
-	0x54, 0x9f, 0x3a, 0x73, 0x7d, 0xd8, 0x7e, 0x43, 0xe7, 0xff, 0xf2, 0x2c, 0xb2, 0x51, 0xb8, 0x2d,
-	0xfa, 0x0e, 0x76, 0xc2, 0x3c, 0xcb, 0x68, 0x22, 0x5f, 0xa7, 0x24, 0x36, 0xf1, 0xb4, 0x02, 0xcf,
-	0xca, 0xd4, 0xbf, 0x11, 0x0d, 0xa0, 0xab, 0x8e, 0x5e, 0x0b, 0x76, 0x69, 0x7e, 0x07, 0xad, 0xa0,
-	0xa3, 0x04, 0xa7, 0xec, 0x92, 0xe2, 0xbf, 0xe0, 0xa6, 0x33, 0xf5, 0x39, 0xff, 0x09, 0xf8, 0x04,
-	0xf6, 0x4d, 0x4a, 0x7f, 0xbe, 0xe7, 0x38, 0x84, 0xde, 0x55, 0x95, 0xab, 0x52, 0xe1, 0xe8, 0xbf,
-	0x6d, 0x47, 0xe1, 0x29, 0xcd, 0xde, 0xb1, 0x50, 0xa5, 0x5f, 0xdb, 0x94, 0x27, 0xaa, 0x44, 0xe3,
-	0xf7, 0x86, 0xa6, 0xcf, 0x0e, 0x5d, 0x9f, 0x1d, 0x3e, 0x51, 0x7d, 0x16, 0x6f, 0xa8, 0x5b, 0x26,
-	0xfd, 0x3e, 0xfd, 0x56, 0xf4, 0xa9, 0xb6, 0xfe, 0x06, 0xaf, 0x54, 0x3f, 0x08, 0xbb, 0xab, 0xab,
-	0x0b, 0xd4, 0xbf, 0xb3, 0x16, 0x63, 0xd8, 0xc1, 0x1b, 0xe8, 0x01, 0x34, 0x9f, 0x52, 0x89, 0x7c,
-	0x87, 0xae, 0x26, 0xb4, 0x5f, 0x71, 0x14, 0x6f, 0xa0, 0xdf, 0xa1, 0xe3, 0x52, 0x1a, 0x7d, 0xbb,
-	0x64, 0xa9, 0x46, 0xc1, 0xc1, 0x6a, 0xc0, 0xc2, 0x8f, 0x87, 0xd0, 0x36, 0x2f, 0x88, 0xbe, 0x5e,
-	0x98, 0xab, 0x4b, 0x92, 0x35, 0x24, 0x1d, 0x43, 0xdb, 0xbc, 0x2b, 0xda, 0x77, 0x2a, 0x96, 0x4a,
-	0xc5, 0xef, 0x5d, 0x15, 0x2f, 0xac, 0xdf, 0x87, 0x2d, 0xd5, 0xfc, 0xd0, 0x2d, 0x87, 0x28, 0x75,
-	0x5c, 0x7f, 0x6f, 0x59, 0x58, 0xbe, 0xa4, 0x8b, 0x68, 0x71, 0xa9, 0x34, 0xd4, 0x14, 0x97, 0xca,
-	0x33, 0x88, 0x8e, 0x13, 0x8a, 0x89, 0x00, 0xdd, 0x76, 0xa8, 0xca, 0x4c, 0xe1, 0xf7, 0xea, 0x87,
-	0x08, 0xbc, 0x81, 0xc6, 0xe0, 0x95, 0xba, 0x49, 0xf1, 0x74, 0xd5, 0x16, 0xb3, 0x86, 0xac, 0x31,
-	0x78, 0xa5, 0xbe, 0x52, 0x28, 0xa9, 0x36, 0x9b, 0x35, 0x4a, 0x7e, 0x03, 0xaf, 0x34, 0xf7, 0x15,
-	0x4a, 0xaa, 0x83, 0xa3, 0x3f, 0xa8, 0x3d, 0x73, 0xb4, 0x3c, 0x3a, 0xfe, 0xe7, 0xe7, 0x62, 0x4e,
-	0xfd, 0xd0, 0xd8, 0x6a, 0x34, 0xfd, 0x62, 0x3e, 0x67, 0x6d, 0x2d, 0xbc, 0xff, 0x3e, 0x00, 0x00,
-	0xff, 0xff, 0xce, 0xba, 0xd5, 0x93, 0x34, 0x0b, 0x00, 0x00,
+	// 1382 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xad, 0x58, 0xdd, 0x53, 0xdb, 0x46,
+	0x10, 0xaf, 0x6d, 0x6c, 0xec, 0x35, 0x10, 0x72, 0x01, 0x62, 0x4c, 0x9a, 0xd2, 0x63, 0x3a, 0x93,
+	0x69, 0x5a, 0x7b, 0x02, 0x4d, 0x3b, 0x4c, 0x1e, 0x5a, 0x0c, 0x49, 0x49, 0xa7, 0xa5, 0x44, 0x24,
+	0x9d, 0xb4, 0x2f, 0x1e, 0x59, 0x3a, 0x1b, 0x0d, 0xb6, 0xe4, 0xe8, 0x23, 0xc5, 0x79, 0x6f, 0x9f,
+	0xfb, 0xa7, 0xf5, 0x0f, 0xea, 0x43, 0xf7, 0xbe, 0x24, 0xd9, 0x96, 0x1d, 0x60, 0x78, 0xd2, 0xed,
+	0xde, 0xee, 0xde, 0xef, 0x76, 0x6f, 0x77, 0xef, 0x04, 0x9b, 0x43, 0xdf, 0x0b, 0xbd, 0x66, 0xc0,
+	0x4c, 0xdf, 0x3a, 0x57, 0x9f, 0x86, 0xe0, 0x91, 0x92, 0xa4, 0xea, 0x5b, 0x3d, 0xcf, 0xeb, 0xf5,
+	0x59, 0x53, 0x70, 0x3b, 0x51, 0xb7, 0xc9, 0x06, 0xc3, 0x70, 0x24, 0x85, 0xea, 0x07, 0x3d, 0x27,
+	0x3c, 0x8f, 0x3a, 0x0d, 0xcb, 0x1b, 0x34, 0xed, 0xc8, 0xeb, 0x9b, 0x56, 0xdf, 0x8b, 0xec, 0xe6,
+	0xc0, 0xb1, 0x7c, 0xaf, 0x63, 0x06, 0x4a, 0xab, 0x39, 0x34, 0x7b, 0x8e, 0x6b, 0x86, 0x8e, 0xe7,
+	0xa6, 0x86, 0xd2, 0x04, 0xfd, 0x12, 0xc8, 0x4b, 0xd7, 0x66, 0x97, 0xcf, 0x2f, 0x9d, 0x20, 0x0c,
+	0x0c, 0xf6, 0x2e, 0x62, 0x41, 0x48, 0xd6, 0xa0, 0xe8, 0x70, 0x6e, 0x2d, 0xb7, 0x9d, 0x7b, 0x54,
+	0x31, 0x24, 0x41, 0xbf, 0x86, 0x7b, 0x63, 0xb2, 0xc1, 0xd0, 0x73, 0x03, 0x46, 0x36, 0xa0, 0xc4,
+	0x04, 0x47, 0x48, 0x97, 0x0d, 0x45, 0x51, 0x1b, 0xaa, 0xa7, 0x66, 0x8f, 0x69, 0x9b, 0x8f, 0xa1,
+	0x88, 0x03, 0x7f, 0x24, 0xa4, 0xaa, 0xbb, 0xeb, 0x8d, 0x14, 0x16, 0x2e, 0xf7, 0x8a, 0x4f, 0x1a,
+	0x52, 0x26, 0x01, 0x90, 0x4f, 0x01, 0x20, 0x04, 0x16, 0xc2, 0xd1, 0x90, 0xd5, 0x0a, 0x82, 0x29,
+	0xc6, 0xf4, 0x35, 0x2c, 0xc9, 0x55, 0x14, 0x1a, 0xd4, 0x0c, 0xbd, 0xd0, 0xec, 0x8b, 0x65, 0x0a,
+	0x86, 0x24, 0x48, 0x03, 0x2a, 0xb6, 0x67, 0x45, 0x03, 0xe6, 0x22, 0xcc, 0xfc, 0x76, 0x01, 0x01,
+	0xac, 0x36, 0x94, 0xc3, 0x8f, 0xd4, 0x84, 0x91, 0x88, 0xd0, 0x21, 0xdc, 0x3d, 0xf4, 0x5c, 0x97,
+	0x59, 0x1c, 0x9e, 0xde, 0xc1, 0x93, 0xf1, 0x1d, 0x6c, 0xa5, 0x77, 0x90, 0x48, 0xdf, 0x70, 0x1f,
+	0xe8, 0xad, 0x9f, 0xd1, 0x6d, 0x57, 0xf1, 0x16, 0x97, 0xbb, 0xe1, 0x2a, 0xff, 0xe6, 0x60, 0x49,
+	0x2e, 0xa3, 0xdc, 0x35, 0xe6, 0x98, 0xdc, 0x47, 0x1d, 0x93, 0xb8, 0x37, 0x9f, 0x76, 0x6f, 0x0d,
+	0x16, 0xcf, 0xcd, 0xe0, 0x84, 0x5d, 0x86, 0x62, 0xb5, 0xb2, 0xa1, 0x49, 0xb2, 0x0d, 0x55, 0x1c,
+	0x9e, 0xfa, 0xec, 0xbd, 0xe3, 0x45, 0x41, 0x6d, 0x41, 0xcc, 0xa6, 0x59, 0x5c, 0x22, 0x08, 0x4d,
+	0x3f, 0x3c, 0x8c, 0xfc, 0xc0, 0xf3, 0x6b, 0x45, 0x81, 0x36, 0xcd, 0x22, 0x0f, 0xa0, 0xc2, 0x5c,
+	0x5b, 0xcd, 0x97, 0xc4, 0x7c, 0xc2, 0xa0, 0x4f, 0xa1, 0xf8, 0x52, 0xef, 0xd7, 0x35, 0x07, 0x4c,
+	0x9d, 0x59, 0x31, 0xe6, 0xc0, 0x06, 0xe6, 0x70, 0xe8, 0xb8, 0x3d, 0xe5, 0x1b, 0x4d, 0xd2, 0x57,
+	0x50, 0x7d, 0xe1, 0xb0, 0xbe, 0x8d, 0x81, 0xeb, 0x3a, 0xbd, 0x4c, 0x65, 0xed, 0xc0, 0x7c, 0xe2,
+	0x40, 0x52, 0x87, 0xb2, 0xe9, 0x9a, 0xfd, 0xd1, 0x07, 0xe6, 0x2b, 0xc7, 0xc6, 0x34, 0xdd, 0x07,
+	0x72, 0xe8, 0x33, 0x33, 0x64, 0x02, 0x8f, 0x8e, 0xe4, 0x4e, 0x3a, 0x97, 0xaa, 0xbb, 0xcb, 0xda,
+	0xbb, 0x52, 0x48, 0xa5, 0x16, 0xa6, 0xe1, 0x11, 0xeb, 0xb3, 0x09, 0xd5, 0xec, 0x34, 0xfc, 0x3b,
+	0x07, 0x65, 0x1d, 0x9a, 0x6c, 0x91, 0x4c, 0xe4, 0x98, 0xa6, 0x5d, 0xbe, 0xe1, 0x40, 0xe1, 0x56,
+	0x14, 0x59, 0x07, 0xac, 0x35, 0xef, 0xda, 0xae, 0x27, 0x82, 0x83, 0x21, 0x45, 0xea, 0xc4, 0x23,
+	0x9f, 0xc3, 0xd2, 0xd0, 0x77, 0x06, 0xa6, 0x3f, 0x6a, 0x87, 0xcc, 0x1f, 0x88, 0xb8, 0x14, 0x8c,
+	0xaa, 0xe2, 0xbd, 0x46, 0x16, 0xed, 0x40, 0xbd, 0x65, 0x86, 0xd6, 0xf9, 0x9b, 0x61, 0xc0, 0xfc,
+	0x30, 0x3e, 0x2d, 0x0a, 0xfc, 0x57, 0x50, 0xd6, 0xc7, 0x66, 0xe6, 0xc1, 0x8a, 0x25, 0x38, 0x3a,
+	0x4b, 0xf8, 0x4e, 0x60, 0xc6, 0x22, 0x22, 0x29, 0x7a, 0x06, 0x5b, 0x99, 0x6b, 0xa8, 0xe3, 0xfb,
+	0x0d, 0x6e, 0x3f, 0x64, 0x03, 0x7d, 0x74, 0x1f, 0xea, 0x15, 0xb2, 0xc5, 0x0d, 0x29, 0x4c, 0xdf,
+	0xc2, 0xc6, 0x0c, 0x7b, 0xab, 0x50, 0x30, 0xad, 0x0b, 0x55, 0xc8, 0xf8, 0x90, 0x3b, 0x98, 0xf9,
+	0x3e, 0x1e, 0x3c, 0x95, 0x5b, 0x82, 0xe0, 0x70, 0xf1, 0x84, 0x86, 0x91, 0x74, 0x66, 0xd1, 0x50,
+	0x14, 0x3d, 0x01, 0xf2, 0x23, 0x9b, 0x72, 0xc5, 0xd5, 0x83, 0xb4, 0x02, 0x79, 0xc7, 0x56, 0x01,
+	0xc2, 0x11, 0x7d, 0x0c, 0xf7, 0xc5, 0xf6, 0x33, 0x8c, 0x22, 0x54, 0xc7, 0x96, 0x1b, 0xaf, 0x18,
+	0x7c, 0x48, 0x7f, 0x82, 0xda, 0xb4, 0xf0, 0xcd, 0xf2, 0x9c, 0x3a, 0xb0, 0x7c, 0x26, 0x66, 0xf5,
+	0x72, 0x98, 0x49, 0x17, 0x6c, 0xf4, 0xa7, 0xe7, 0xdb, 0x6a, 0x17, 0x9a, 0xe4, 0x27, 0xc5, 0x8a,
+	0x7c, 0x1f, 0xd5, 0xda, 0x58, 0xa4, 0xe4, 0x7e, 0x8a, 0x46, 0x55, 0xf1, 0x78, 0x71, 0x26, 0x5b,
+	0x50, 0xe1, 0x53, 0xed, 0xc0, 0xf9, 0xc0, 0x94, 0xc7, 0xca, 0x9c, 0x71, 0x86, 0x34, 0xfd, 0x0d,
+	0x56, 0xf4, 0x52, 0xb7, 0x59, 0x94, 0x30, 0xc3, 0xd7, 0x65, 0x4e, 0xdd, 0x5e, 0x38, 0x7e, 0x80,
+	0x8d, 0x49, 0x93, 0xd7, 0x3b, 0x38, 0xf4, 0x77, 0x95, 0x33, 0xd9, 0xc8, 0x9e, 0x4d, 0x6f, 0xfc,
+	0xd3, 0x78, 0xe3, 0x59, 0x1a, 0xe9, 0x90, 0xe9, 0x54, 0x99, 0x81, 0x70, 0x56, 0xaa, 0x64, 0x8b,
+	0xeb, 0x54, 0x39, 0x85, 0xa5, 0x43, 0x2f, 0xba, 0x89, 0xef, 0x44, 0xbd, 0xe9, 0x87, 0x71, 0x9d,
+	0x54, 0x14, 0xfd, 0x02, 0x96, 0x95, 0xc5, 0xa4, 0x63, 0x5b, 0x9c, 0xa1, 0x3b, 0xb6, 0x20, 0xe8,
+	0x3f, 0x39, 0x58, 0x7b, 0x33, 0xb4, 0xb1, 0x06, 0xb4, 0x46, 0xb2, 0xd9, 0xdd, 0x16, 0x82, 0x54,
+	0x25, 0x5c, 0x18, 0xab, 0x84, 0x0f, 0x01, 0x1c, 0x17, 0xeb, 0x8e, 0x74, 0x7f, 0x51, 0x24, 0x56,
+	0x8a, 0x83, 0x65, 0x63, 0x4d, 0x3a, 0xeb, 0xb6, 0x11, 0xe1, 0xcd, 0xea, 0x4e, 0x6c, 0x53, 0x79,
+	0x85, 0x37, 0x9a, 0x6e, 0x17, 0xaf, 0x14, 0xcc, 0x56, 0x8e, 0x89, 0x69, 0x7a, 0x0c, 0xa5, 0x5f,
+	0x58, 0xe8, 0x3b, 0x16, 0x5f, 0xa4, 0x1b, 0xb9, 0x96, 0x6e, 0x5b, 0x7c, 0xcc, 0xe1, 0x88, 0x0d,
+	0xe9, 0x83, 0x27, 0x08, 0xce, 0x35, 0xfb, 0x8e, 0xa9, 0xab, 0xbf, 0x24, 0xe8, 0x01, 0x2c, 0x1f,
+	0xa1, 0x8b, 0x8f, 0xf1, 0x4a, 0xe0, 0xf5, 0x7c, 0x73, 0x90, 0x28, 0xe7, 0xd2, 0xca, 0x08, 0xc6,
+	0x71, 0x11, 0xe8, 0x7b, 0x95, 0x63, 0xd8, 0xf5, 0x34, 0x4d, 0xff, 0xca, 0xc3, 0xea, 0x41, 0xaf,
+	0xe7, 0xb3, 0x1e, 0x1a, 0xba, 0xbd, 0x20, 0x6d, 0x42, 0xb9, 0xe7, 0x7b, 0xd1, 0xb0, 0xdd, 0x19,
+	0x61, 0x98, 0x78, 0x28, 0x16, 0x05, 0xdd, 0x1a, 0x91, 0x3d, 0xa8, 0x9c, 0x6b, 0xc0, 0xa2, 0x2f,
+	0xf1, 0xfb, 0x91, 0x3e, 0xcd, 0xe9, 0xdd, 0x18, 0x89, 0x1c, 0x79, 0x84, 0x37, 0x01, 0xe1, 0xb3,
+	0x00, 0xaf, 0x10, 0x3c, 0x01, 0x56, 0xb4, 0x8a, 0x74, 0xa5, 0xa1, 0xa7, 0x39, 0x4a, 0x51, 0xa7,
+	0x16, 0x45, 0x9d, 0x12, 0x63, 0x5e, 0xe3, 0x24, 0xae, 0xb6, 0xbc, 0x95, 0x95, 0xe5, 0x2d, 0x45,
+	0xf2, 0x44, 0xe0, 0xe8, 0xf7, 0x70, 0x27, 0x76, 0x43, 0x2b, 0xb2, 0x2e, 0x58, 0xc8, 0x2d, 0x61,
+	0x91, 0x0c, 0x74, 0x74, 0xf8, 0x58, 0xdc, 0x48, 0x14, 0x0e, 0x7d, 0x23, 0x91, 0x24, 0x7d, 0x01,
+	0x77, 0x53, 0x7e, 0x54, 0xc7, 0xe0, 0x09, 0x2c, 0x76, 0x84, 0x31, 0x9d, 0xb7, 0xf7, 0x35, 0xec,
+	0x89, 0xc5, 0x0c, 0x2d, 0xb7, 0xfb, 0x5f, 0x45, 0xd7, 0xee, 0x33, 0x8c, 0x90, 0x63, 0xf1, 0xd4,
+	0x2f, 0xc9, 0x8b, 0x09, 0x99, 0x2a, 0xa3, 0xf5, 0x8d, 0x86, 0x7c, 0x61, 0x34, 0xf4, 0x0b, 0xa3,
+	0xf1, 0x9c, 0xbf, 0x30, 0xe8, 0x27, 0x5c, 0x4b, 0x76, 0xc9, 0xeb, 0x6b, 0xd9, 0xd7, 0x5d, 0xeb,
+	0x2d, 0x54, 0x53, 0x6d, 0x9e, 0x50, 0xad, 0x3a, 0xfb, 0x7e, 0x51, 0xdf, 0x99, 0x2b, 0x23, 0xdd,
+	0x87, 0x96, 0x9f, 0x42, 0x01, 0xfb, 0x21, 0xa9, 0x6b, 0xe9, 0xe9, 0x4e, 0x5a, 0x9f, 0x02, 0x8a,
+	0x6a, 0xbf, 0x42, 0x59, 0xf7, 0x52, 0xf2, 0xd9, 0xd8, 0x4a, 0x19, 0x06, 0xb6, 0x67, 0x0b, 0xc4,
+	0x38, 0x0e, 0xa0, 0x24, 0x8b, 0x07, 0x99, 0x5f, 0xd1, 0xaf, 0xe0, 0x24, 0x65, 0x67, 0xdc, 0x49,
+	0xd9, 0xc6, 0x76, 0xe6, 0xca, 0xc4, 0xe0, 0xf6, 0xa1, 0x24, 0x4f, 0x0c, 0x89, 0x13, 0x69, 0xac,
+	0xfb, 0x23, 0xa8, 0x09, 0x76, 0xac, 0xba, 0x07, 0x0b, 0xfc, 0x41, 0x41, 0xee, 0x69, 0x89, 0xd4,
+	0x2b, 0xa6, 0xbe, 0x36, 0xce, 0x4c, 0x2b, 0x89, 0x7b, 0x41, 0xac, 0x94, 0x7a, 0x28, 0x26, 0x4a,
+	0xe9, 0x77, 0x1d, 0x2a, 0x7d, 0x0b, 0x45, 0xd1, 0x38, 0x48, 0x2c, 0x90, 0xee, 0x4c, 0xf5, 0xf5,
+	0x09, 0x6e, 0xac, 0x77, 0x0c, 0xcb, 0x63, 0x8d, 0x84, 0x3c, 0x48, 0x6e, 0x89, 0xd3, 0xfd, 0xa5,
+	0x1e, 0x27, 0xd8, 0x44, 0x45, 0x96, 0x96, 0xc6, 0x1a, 0x40, 0x62, 0x29, 0xab, 0x2f, 0xcc, 0xb3,
+	0xd4, 0x82, 0x4a, 0x9c, 0xbf, 0xa4, 0x36, 0x95, 0xd2, 0xda, 0xc2, 0x66, 0xc6, 0x4c, 0xea, 0x44,
+	0x41, 0xf2, 0xea, 0x24, 0x9b, 0xc9, 0xf6, 0x27, 0xde, 0xad, 0x18, 0xbc, 0xcc, 0x87, 0x2a, 0x9a,
+	0x38, 0x84, 0x6a, 0xea, 0xc5, 0x92, 0x24, 0xc9, 0xf4, 0x33, 0x66, 0xce, 0xb1, 0x44, 0x23, 0xa9,
+	0xb7, 0x4b, 0x62, 0x64, 0xfa, 0x41, 0x33, 0xc7, 0xc8, 0x31, 0x54, 0x53, 0xff, 0x16, 0x12, 0x23,
+	0xd3, 0x3f, 0x27, 0xea, 0x5b, 0x99, 0x73, 0xda, 0x2d, 0xad, 0xfd, 0x3f, 0xbe, 0x4b, 0xfe, 0x85,
+	0x7c, 0xec, 0xd7, 0x88, 0xb4, 0xf4, 0x4c, 0x7e, 0x3a, 0x25, 0xc1, 0xdc, 0xfb, 0x1f, 0xf7, 0xcc,
+	0xd3, 0xb5, 0x98, 0x11, 0x00, 0x00,
 }
@@ -51,9 +51,14 @@ type SearchService interface {
 	Get(ctx context.Context, in *GetDocumentRequest, opts ...client.CallOption) (*Document, error)
 	BatchGet(ctx context.Context, in *BatchGetDocumentRequest, opts ...client.CallOption) (*BatchGetDocumentResponse, error)
 	Delete(ctx context.Context, in *DeleteDocumentRequest, opts ...client.CallOption) (*emptypb.Empty, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteDocumentRequest, opts ...client.CallOption) (*BatchDeleteDocumentResponse, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...client.CallOption) (*SearchResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...client.CallOption) (*ListResponse, error)
 	Page(ctx context.Context, in *PageRequest, opts ...client.CallOption) (*PageResponse, error)
+	Count(ctx context.Context, in *CountRequest, opts ...client.CallOption) (*CountResponse, error)
+	UpdateByQuery(ctx context.Context, in *UpdateByQueryRequest, opts ...client.CallOption) (*ByQueryResponse, error)
+	DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, opts ...client.CallOption) (*ByQueryResponse, error)
+	Aggregate(ctx context.Context, in *AggregateRequest, opts ...client.CallOption) (*AggregateResponse, error)
 	// graphql 查询模式查询结果
 	Connection(ctx context.Context, in *ConnectionRequest, opts ...client.CallOption) (*pagination.Connection, error)
 	CreateIndex(ctx context.Context, in *CreateIndexRequest, opts ...client.CallOption) (*emptypb.Empty, error)
@@ -143,6 +148,16 @@ func (c *searchService) Delete(ctx context.Context, in *DeleteDocumentRequest, o
 	return out, nil
 }
 
+func (c *searchService) BatchDelete(ctx context.Context, in *BatchDeleteDocumentRequest, opts ...client.CallOption) (*BatchDeleteDocumentResponse, error) {
+	req := c.c.NewRequest(c.name, "SearchService.BatchDelete", in)
+	out := new(BatchDeleteDocumentResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *searchService) Search(ctx context.Context, in *SearchRequest, opts ...client.CallOption) (*SearchResponse, error) {
 	req := c.c.NewRequest(c.name, "SearchService.Search", in)
 	out := new(SearchResponse)
@@ -173,6 +188,46 @@ func (c *searchService) Page(ctx context.Context, in *PageRequest, opts ...clien
 	return out, nil
 }
 
+func (c *searchService) Count(ctx context.Context, in *CountRequest, opts ...client.CallOption) (*CountResponse, error) {
+	req := c.c.NewRequest(c.name, "SearchService.Count", in)
+	out := new(CountResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchService) UpdateByQuery(ctx context.Context, in *UpdateByQueryRequest, opts ...client.CallOption) (*ByQueryResponse, error) {
+	req := c.c.NewRequest(c.name, "SearchService.UpdateByQuery", in)
+	out := new(ByQueryResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchService) DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, opts ...client.CallOption) (*ByQueryResponse, error) {
+	req := c.c.NewRequest(c.name, "SearchService.DeleteByQuery", in)
+	out := new(ByQueryResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchService) Aggregate(ctx context.Context, in *AggregateRequest, opts ...client.CallOption) (*AggregateResponse, error) {
+	req := c.c.NewRequest(c.name, "SearchService.Aggregate", in)
+	out := new(AggregateResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *searchService) Connection(ctx context.Context, in *ConnectionRequest, opts ...client.CallOption) (*pagination.Connection, error) {
 	req := c.c.NewRequest(c.name, "SearchService.Connection", in)
 	out := new(pagination.Connection)
@@ -223,9 +278,14 @@ type SearchServiceHandler interface {
 	Get(context.Context, *GetDocumentRequest, *Document) error
 	BatchGet(context.Context, *BatchGetDocumentRequest, *BatchGetDocumentResponse) error
 	Delete(context.Context, *DeleteDocumentRequest, *emptypb.Empty) error
+	BatchDelete(context.Context, *BatchDeleteDocumentRequest, *BatchDeleteDocumentResponse) error
 	Search(context.Context, *SearchRequest, *SearchResponse) error
 	List(context.Context, *ListRequest, *ListResponse) error
 	Page(context.Context, *PageRequest, *PageResponse) error
+	Count(context.Context, *CountRequest, *CountResponse) error
+	UpdateByQuery(context.Context, *UpdateByQueryRequest, *ByQueryResponse) error
+	DeleteByQuery(context.Context, *DeleteByQueryRequest, *ByQueryResponse) error
+	Aggregate(context.Context, *AggregateRequest, *AggregateResponse) error
 	// graphql 查询模式查询结果
 	Connection(context.Context, *ConnectionRequest, *pagination.Connection) error
 	CreateIndex(context.Context, *CreateIndexRequest, *emptypb.Empty) error
@@ -242,9 +302,14 @@ func RegisterSearchServiceHandler(s server.Server, hdlr SearchServiceHandler, op
 		Get(ctx context.Context, in *GetDocumentRequest, out *Document) error
 		BatchGet(ctx context.Context, in *BatchGetDocumentRequest, out *BatchGetDocumentResponse) error
 		Delete(ctx context.Context, in *DeleteDocumentRequest, out *emptypb.Empty) error
+		BatchDelete(ctx context.Context, in *BatchDeleteDocumentRequest, out *BatchDeleteDocumentResponse) error
 		Search(ctx context.Context, in *SearchRequest, out *SearchResponse) error
 		List(ctx context.Context, in *ListRequest, out *ListResponse) error
 		Page(ctx context.Context, in *PageRequest, out *PageResponse) error
+		Count(ctx context.Context, in *CountRequest, out *CountResponse) error
+		UpdateByQuery(ctx context.Context, in *UpdateByQueryRequest, out *ByQueryResponse) error
+		DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, out *ByQueryResponse) error
+		Aggregate(ctx context.Context, in *AggregateRequest, out *AggregateResponse) error
 		Connection(ctx context.Context, in *ConnectionRequest, out *pagination.Connection) error
 		CreateIndex(ctx context.Context, in *CreateIndexRequest, out *emptypb.Empty) error
 		DeleteIndex(ctx context.Context, in *DeleteIndexRequest, out *emptypb.Empty) error
@@ -289,6 +354,10 @@ func (h *searchServiceHandler) Delete(ctx context.Context, in *DeleteDocumentReq
 	return h.SearchServiceHandler.Delete(ctx, in, out)
 }
 
+func (h *searchServiceHandler) BatchDelete(ctx context.Context, in *BatchDeleteDocumentRequest, out *BatchDeleteDocumentResponse) error {
+	return h.SearchServiceHandler.BatchDelete(ctx, in, out)
+}
+
 func (h *searchServiceHandler) Search(ctx context.Context, in *SearchRequest, out *SearchResponse) error {
 	return h.SearchServiceHandler.Search(ctx, in, out)
 }
@@ -301,6 +370,22 @@ func (h *searchServiceHandler) Page(ctx context.Context, in *PageRequest, out *P
 	return h.SearchServiceHandler.Page(ctx, in, out)
 }
 
+func (h *searchServiceHandler) Count(ctx context.Context, in *CountRequest, out *CountResponse) error {
+	return h.SearchServiceHandler.Count(ctx, in, out)
+}
+
+func (h *searchServiceHandler) UpdateByQuery(ctx context.Context, in *UpdateByQueryRequest, out *ByQueryResponse) error {
+	return h.SearchServiceHandler.UpdateByQuery(ctx, in, out)
+}
+
+func (h *searchServiceHandler) DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, out *ByQueryResponse) error {
+	return h.SearchServiceHandler.DeleteByQuery(ctx, in, out)
+}
+
+func (h *searchServiceHandler) Aggregate(ctx context.Context, in *AggregateRequest, out *AggregateResponse) error {
+	return h.SearchServiceHandler.Aggregate(ctx, in, out)
+}
+
 func (h *searchServiceHandler) Connection(ctx context.Context, in *ConnectionRequest, out *pagination.Connection) error {
 	return h.SearchServiceHandler.Connection(ctx, in, out)
 }
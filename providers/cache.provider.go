@@ -1,24 +1,95 @@
 package providers
 
 import (
+	"crypto/tls"
+	"time"
+
 	"github.com/duolacloud/microbase/cache"
+	"github.com/duolacloud/microbase/cache/codec"
 	"github.com/duolacloud/microbase/cache/memory"
 	"github.com/duolacloud/microbase/cache/redis"
+	"github.com/duolacloud/microbase/cache/twolevel"
+	"github.com/duolacloud/microbase/logger"
 	"github.com/micro/go-micro/v2/config"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func NewCacheProvider(config config.Config) cache.Cache {
-	driver := config.Get("cache", "addrs").String("redis")
+	driver := config.Get("cache", "driver").String("redis")
 	prefix := config.Get("cache", "prefix").String("")
 
+	var valueCodec cache.Codec
+	switch config.Get("cache", "codec").String("json") {
+	case "msgpack":
+		valueCodec = codec.NewMsgpackCodec()
+	case "gob":
+		valueCodec = codec.NewGobCodec()
+	case "proto":
+		valueCodec = codec.NewProtoCodec()
+	default:
+		valueCodec = codec.NewJSONCodec()
+	}
+
+	opts := []cache.Option{
+		cache.WithPrefix(prefix),
+		cache.WithCodec(valueCodec),
+		memory.WithMaxEntries(config.Get("cache", "max_entries").Int(0)),
+		memory.WithMaxBytes(int64(config.Get("cache", "max_bytes").Int(0))),
+		memory.WithEviction(config.Get("cache", "eviction").String(memory.EvictionLRU)),
+		memory.WithDefaultExpiry(config.Get("cache", "default_expiry").Duration(24 * time.Hour)),
+		memory.WithCleanupInterval(config.Get("cache", "cleanup_interval").Duration(30 * time.Second)),
+	}
+	if config.Get("cache", "tenant_scoped").Bool(false) {
+		opts = append(opts, cache.WithTenantScope())
+	}
+
 	var c cache.Cache
 	switch driver {
 	case "redis":
-		addrs := config.Get("cache", "addrs").StringSlice([]string{":6379"})
-		c = redis.NewCache(cache.WithPrefix(prefix), redis.WithAddrs(addrs...))
+		opts = append(opts, redisOptions(config)...)
+		c = redis.NewCache(opts...)
+	case "twolevel":
+		l1Expiry := config.Get("cache", "l1_expiry").Duration(time.Minute)
+		channel := config.Get("cache", "channel").String("")
+		opts = append(opts, redisOptions(config)...)
+		opts = append(opts, twolevel.WithL1Expiry(l1Expiry), twolevel.WithChannel(channel))
+		c = twolevel.NewCache(opts...)
 	default:
-		c = memory.NewCache(cache.WithPrefix(prefix))
+		c = memory.NewCache(opts...)
+	}
+
+	// 进程内缓存的命中率等指标通过 StartPrometheus 启动的 /metrics 导出
+	if reporter, ok := c.(memory.StatsReporter); ok {
+		if err := prometheus.Register(memory.NewCollector(driver, reporter)); err != nil {
+			logger.Warnf("register cache metrics error: %v", err)
+		}
 	}
 
 	return c
 }
+
+// redisOptions 读取 cache 下的 redis 连接配置
+func redisOptions(config config.Config) []cache.Option {
+	opts := []cache.Option{
+		redis.WithAddrs(config.Get("cache", "addrs").StringSlice([]string{":6379"})...),
+		redis.WithPassword(config.Get("cache", "password").String("")),
+		redis.WithMode(config.Get("cache", "mode").String(redis.ModeStandalone)),
+		redis.WithMasterName(config.Get("cache", "master_name").String("")),
+		redis.WithSentinelPassword(config.Get("cache", "sentinel_password").String("")),
+		redis.WithDB(config.Get("cache", "db").Int(0)),
+		redis.WithPoolSize(config.Get("cache", "pool_size").Int(0)),
+		redis.WithMaxIdle(config.Get("cache", "max_idle").Int(3)),
+		redis.WithIdleTimeout(config.Get("cache", "idle_timeout").Duration(240 * time.Second)),
+		redis.WithDialTimeout(config.Get("cache", "dial_timeout").Duration(5 * time.Second)),
+		redis.WithReadTimeout(config.Get("cache", "read_timeout").Duration(0)),
+		redis.WithWriteTimeout(config.Get("cache", "write_timeout").Duration(0)),
+	}
+
+	if config.Get("cache", "tls").Bool(false) {
+		opts = append(opts, redis.WithTLSConfig(&tls.Config{
+			InsecureSkipVerify: config.Get("cache", "tls_skip_verify").Bool(false),
+		}))
+	}
+
+	return opts
+}
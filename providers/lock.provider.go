@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"github.com/duolacloud/microbase/lock"
+	"github.com/duolacloud/microbase/lock/memory"
+	"github.com/duolacloud/microbase/lock/redis"
+	"github.com/micro/go-micro/v2/config"
+	"go.uber.org/fx"
+)
+
+// Lock 提供 lock.Locker, redis 驱动复用 cache 下的连接配置
+var Lock = fx.Provide(
+	NewLockProvider,
+)
+
+func NewLockProvider(config config.Config) (lock.Locker, error) {
+	driver := config.Get("lock", "driver").String("redis")
+	prefix := config.Get("lock", "prefix").String("")
+
+	var l lock.Locker
+	switch driver {
+	case "redis":
+		l = redis.NewLocker(lock.WithPrefix(prefix), redis.WithCacheOptions(redisOptions(config)...))
+	default:
+		l = memory.NewLocker(lock.WithPrefix(prefix))
+	}
+
+	if err := l.Init(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"net/http"
 
 	"github.com/duolacloud/microbase/client/search"
 	"github.com/duolacloud/microbase/domain/entity"
@@ -12,6 +13,7 @@ import (
 	pb "github.com/duolacloud/microbase/proto/search"
 	"github.com/duolacloud/microbase/service/search/repositories"
 	"github.com/golang/protobuf/ptypes"
+	merrors "github.com/micro/go-micro/v2/errors"
 	"github.com/thoas/go-funk"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -37,11 +39,17 @@ func (h *searchServiceHandler) Create(c context.Context, req *pb.Document, rsp *
 
 	log.Printf("Create fields: %v", fields)
 
-	return h.documentRepository.Create(c, &search.Document{
-		Index:  req.Index,
-		Type:   req.Type,
-		Fields: fields,
+	err = h.documentRepository.Create(c, &search.Document{
+		Index:       req.Index,
+		Type:        req.Type,
+		Fields:      fields,
+		SeqNo:       req.SeqNo,
+		PrimaryTerm: req.PrimaryTerm,
 	})
+	if err == search.ErrConflict {
+		return merrors.Conflict("search", "document version conflict")
+	}
+	return err
 }
 
 func (h *searchServiceHandler) Upsert(c context.Context, req *pb.Document, rsp *emptypb.Empty) error {
@@ -51,11 +59,17 @@ func (h *searchServiceHandler) Upsert(c context.Context, req *pb.Document, rsp *
 		return err
 	}
 
-	return h.documentRepository.Upsert(c, &search.Document{
-		Index:  req.Index,
-		Type:   req.Type,
-		Fields: fields,
+	err = h.documentRepository.Upsert(c, &search.Document{
+		Index:       req.Index,
+		Type:        req.Type,
+		Fields:      fields,
+		SeqNo:       req.SeqNo,
+		PrimaryTerm: req.PrimaryTerm,
 	})
+	if err == search.ErrConflict {
+		return merrors.Conflict("search", "document version conflict")
+	}
+	return err
 }
 
 func (h *searchServiceHandler) Update(c context.Context, req *pb.Document, rsp *emptypb.Empty) error {
@@ -65,11 +79,17 @@ func (h *searchServiceHandler) Update(c context.Context, req *pb.Document, rsp *
 		return err
 	}
 
-	return h.documentRepository.Update(c, &search.Document{
-		Index:  req.Index,
-		Type:   req.Type,
-		Fields: fields,
+	err = h.documentRepository.Update(c, &search.Document{
+		Index:       req.Index,
+		Type:        req.Type,
+		Fields:      fields,
+		SeqNo:       req.SeqNo,
+		PrimaryTerm: req.PrimaryTerm,
 	})
+	if err == search.ErrConflict {
+		return merrors.Conflict("search", "document version conflict")
+	}
+	return err
 }
 
 func (h *searchServiceHandler) Delete(c context.Context, req *pb.DeleteDocumentRequest, rsp *emptypb.Empty) error {
@@ -77,12 +97,66 @@ func (h *searchServiceHandler) Delete(c context.Context, req *pb.DeleteDocumentR
 }
 
 func (h *searchServiceHandler) BatchUpsert(c context.Context, req *pb.BatchUpsertDocumentRequest, rsp *pb.BatchUpsertDocumentResponse) error {
-	/* TODO
-	r, err := h.documentRepository.BatchUpsert(c, req.Index, req.Type, req.Id)
+	docs := make([]*search.Document, len(req.Document))
+	for i, d := range req.Document {
+		var fields map[string]interface{}
+		err := json.Unmarshal([]byte(d.Fields), &fields)
+		if err != nil {
+			return err
+		}
+
+		docs[i] = &search.Document{
+			Index:  d.Index,
+			Type:   d.Type,
+			Fields: fields,
+		}
+	}
+
+	batch := h.documentRepository.BatchUpsert
+	if req.Create {
+		batch = h.documentRepository.BatchCreate
+	}
+
+	errs, err := batch(c, docs)
 	if err != nil {
 		return err
 	}
-	*/
+
+	rsp.Items = make([]*pb.UpsertDocumentResponse, len(errs))
+	for i, err := range errs {
+		rsp.Items[i] = &pb.UpsertDocumentResponse{Ack: err == nil}
+		if err != nil {
+			rsp.Items[i].Error = err.Error()
+		}
+		if err == search.ErrExists || err == search.ErrConflict {
+			rsp.Items[i].Status = http.StatusConflict
+		}
+	}
+	return nil
+}
+
+func (h *searchServiceHandler) BatchDelete(c context.Context, req *pb.BatchDeleteDocumentRequest, rsp *pb.BatchDeleteDocumentResponse) error {
+	keys := make([]*search.DocumentKey, len(req.Documents))
+	for i, d := range req.Documents {
+		keys[i] = &search.DocumentKey{
+			Index: d.Index,
+			Type:  d.Type,
+			Id:    d.Id,
+		}
+	}
+
+	errs, err := h.documentRepository.BatchDelete(c, keys)
+	if err != nil {
+		return err
+	}
+
+	rsp.Items = make([]*pb.DeleteDocumentResponse, len(errs))
+	for i, err := range errs {
+		rsp.Items[i] = &pb.DeleteDocumentResponse{Ack: err == nil}
+		if err != nil {
+			rsp.Items[i].Error = err.Error()
+		}
+	}
 	return nil
 }
 
@@ -92,6 +166,9 @@ func (h *searchServiceHandler) Get(c context.Context, req *pb.GetDocumentRequest
 	if err != nil {
 		return err
 	}
+	if doc == nil {
+		return merrors.NotFound("search", "document %s not found", req.Id)
+	}
 
 	fieldsB, err := json.Marshal(doc.Fields)
 	if err != nil {
@@ -101,6 +178,8 @@ func (h *searchServiceHandler) Get(c context.Context, req *pb.GetDocumentRequest
 	rsp.Index = doc.Index
 	rsp.Type = doc.Type
 	rsp.Fields = string(fieldsB)
+	rsp.SeqNo = doc.SeqNo
+	rsp.PrimaryTerm = doc.PrimaryTerm
 	return nil
 }
 
@@ -120,7 +199,9 @@ func (h *searchServiceHandler) Search(c context.Context, req *pb.SearchRequest,
 
 func (h *searchServiceHandler) List(c context.Context, req *pb.ListRequest, rsp *pb.ListResponse) error {
 	var query entity.CursorQuery
-	query.FromPB(req.Query)
+	if err := query.FromPBWithError(req.Query); err != nil {
+		return err
+	}
 
 	docs, extra, err := h.documentRepository.List(c, &query, req.Index, req.Type)
 	if err != nil {
@@ -147,7 +228,12 @@ func (h *searchServiceHandler) List(c context.Context, req *pb.ListRequest, rsp
 }
 
 func (h *searchServiceHandler) Page(c context.Context, req *pb.PageRequest, rsp *pb.PageResponse) error {
-	docs, total, err := h.documentRepository.Page(c, &entity.PageQuery{}, req.Index, req.Type)
+	var query entity.PageQuery
+	if err := query.FromPBWithError(req.Query); err != nil {
+		return err
+	}
+
+	docs, total, err := h.documentRepository.Page(c, &query, req.Index, req.Type)
 	if err != nil {
 		return err
 	}
@@ -166,8 +252,120 @@ func (h *searchServiceHandler) Page(c context.Context, req *pb.PageRequest, rsp
 	return nil
 }
 
+func (h *searchServiceHandler) Count(c context.Context, req *pb.CountRequest, rsp *pb.CountResponse) error {
+	var filter map[string]interface{}
+	if req.Filter != "" {
+		if err := json.Unmarshal([]byte(req.Filter), &filter); err != nil {
+			return err
+		}
+	}
+
+	count, err := h.documentRepository.Count(c, filter, req.Index, req.Type)
+	if err != nil {
+		return err
+	}
+
+	rsp.Count = count
+	return nil
+}
+
+func (h *searchServiceHandler) UpdateByQuery(c context.Context, req *pb.UpdateByQueryRequest, rsp *pb.ByQueryResponse) error {
+	var filter map[string]interface{}
+	if req.Filter != "" {
+		if err := json.Unmarshal([]byte(req.Filter), &filter); err != nil {
+			return err
+		}
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(req.Fields), &fields); err != nil {
+		return err
+	}
+
+	affected, err := h.documentRepository.UpdateByQuery(c, filter, fields, req.Index, req.Type, req.Increments...)
+	if err != nil {
+		return err
+	}
+
+	rsp.Affected = affected
+	return nil
+}
+
+func (h *searchServiceHandler) DeleteByQuery(c context.Context, req *pb.DeleteByQueryRequest, rsp *pb.ByQueryResponse) error {
+	var filter map[string]interface{}
+	if req.Filter != "" {
+		if err := json.Unmarshal([]byte(req.Filter), &filter); err != nil {
+			return err
+		}
+	}
+
+	affected, err := h.documentRepository.DeleteByQuery(c, filter, req.Index, req.Type)
+	if err != nil {
+		return err
+	}
+
+	rsp.Affected = affected
+	return nil
+}
+
+func (h *searchServiceHandler) Aggregate(c context.Context, req *pb.AggregateRequest, rsp *pb.AggregateResponse) error {
+	filter, err := entity.DecodeFilter(req.Filter, req.FilterQuery)
+	if err != nil {
+		return err
+	}
+
+	query := &entity.AggregateQuery{
+		Filter:  filter,
+		GroupBy: req.GroupBy,
+		Size:    int(req.Size),
+	}
+
+	if req.Histogram != nil {
+		query.Histogram = &entity.DateHistogram{
+			Field:    req.Histogram.Field,
+			Interval: entity.DateInterval(req.Histogram.Interval),
+		}
+	}
+
+	for _, metric := range req.Metrics {
+		query.Metrics = append(query.Metrics, &entity.Metric{
+			Func:  entity.AggregateFunc(metric.Func),
+			Field: metric.Field,
+			Alias: metric.Alias,
+		})
+	}
+
+	buckets, err := h.documentRepository.Aggregate(c, query, req.Index, req.Type)
+	if err != nil {
+		return err
+	}
+
+	rsp.Buckets = make([]*pb.AggregateBucket, len(buckets))
+	for i, bucket := range buckets {
+		keys, err := json.Marshal(bucket.Keys)
+		if err != nil {
+			return err
+		}
+
+		metrics, err := json.Marshal(bucket.Metrics)
+		if err != nil {
+			return err
+		}
+
+		rsp.Buckets[i] = &pb.AggregateBucket{
+			Keys:    string(keys),
+			Metrics: string(metrics),
+		}
+	}
+	return nil
+}
+
 func (h *searchServiceHandler) Connection(c context.Context, req *pb.ConnectionRequest, rsp *pagination.Connection) error {
-	query := entity.ConnectionQueryFromPB(req.Query)
+	query, err := entity.ConnectionQueryFromPBWithError(req.Query)
+	if err != nil {
+		return err
+	}
+
 	conn, err := h.documentRepository.Connection(c, query, req.Index, req.Type)
 	if err != nil {
 		return err
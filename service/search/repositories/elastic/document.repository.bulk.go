@@ -0,0 +1,138 @@
+package elastic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/duolacloud/microbase/client/search"
+
+	"github.com/olivere/elastic/v6"
+)
+
+// BatchCreate 使用 bulk 接口以 create 方式写入, 已经存在的文档不覆盖, 返回 search.ErrExists
+func (r *DocumentRepository) BatchCreate(c context.Context, docs []*search.Document) ([]error, error) {
+	errs := make([]error, len(docs))
+	if len(docs) == 0 {
+		return errs, nil
+	}
+
+	client, err := r.client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []int
+	bulk := client.Bulk()
+	for i, doc := range docs {
+		id, ok := doc.Fields["id"].(string)
+		if !ok {
+			errs[i] = errors.New("create need id")
+			continue
+		}
+
+		positions = append(positions, i)
+		bulk.Add(elastic.NewBulkIndexRequest().
+			OpType("create").
+			Index(r.DataSourceProvider.ProvideTable(c, doc.Index)).
+			Type(doc.Type).
+			Id(id).
+			Doc(doc.Fields))
+	}
+
+	return errs, r.doBulk(c, bulk, positions, errs, search.ErrExists)
+}
+
+// BatchUpsert 使用 bulk 接口写入, 和 Upsert 一样以 fields 中的 id 作为文档 id
+func (r *DocumentRepository) BatchUpsert(c context.Context, docs []*search.Document) ([]error, error) {
+	errs := make([]error, len(docs))
+	if len(docs) == 0 {
+		return errs, nil
+	}
+
+	client, err := r.client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []int
+	bulk := client.Bulk()
+	for i, doc := range docs {
+		id, ok := doc.Fields["id"].(string)
+		if !ok {
+			errs[i] = errors.New("upsert need id")
+			continue
+		}
+
+		positions = append(positions, i)
+		bulk.Add(elastic.NewBulkUpdateRequest().
+			Index(r.DataSourceProvider.ProvideTable(c, doc.Index)).
+			Type(doc.Type).
+			Id(id).
+			Doc(doc.Fields).
+			DocAsUpsert(true))
+	}
+
+	return errs, r.doBulk(c, bulk, positions, errs, search.ErrConflict)
+}
+
+// BatchDelete 使用 bulk 接口删除, 文档不存在不算失败
+func (r *DocumentRepository) BatchDelete(c context.Context, keys []*search.DocumentKey) ([]error, error) {
+	errs := make([]error, len(keys))
+	if len(keys) == 0 {
+		return errs, nil
+	}
+
+	client, err := r.client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []int
+	bulk := client.Bulk()
+	for i, key := range keys {
+		if key.Id == "" {
+			errs[i] = errors.New("delete need id")
+			continue
+		}
+
+		positions = append(positions, i)
+		bulk.Add(elastic.NewBulkDeleteRequest().
+			Index(r.DataSourceProvider.ProvideTable(c, key.Index)).
+			Type(key.Type).
+			Id(key.Id))
+	}
+
+	return errs, r.doBulk(c, bulk, positions, errs, search.ErrConflict)
+}
+
+// doBulk 执行 bulk 请求, 把每个请求的错误写回 errs 中对应的位置, 409 转换为 conflict
+func (r *DocumentRepository) doBulk(c context.Context, bulk *elastic.BulkService, positions []int, errs []error, conflict error) error {
+	if bulk.NumberOfActions() == 0 {
+		return nil
+	}
+
+	res, err := bulk.Do(c)
+	if err != nil {
+		return err
+	}
+
+	for j, item := range res.Items {
+		if j >= len(positions) {
+			break
+		}
+
+		for _, result := range item {
+			if result.Status == http.StatusConflict {
+				errs[positions[j]] = conflict
+			} else if result.Error != nil {
+				errs[positions[j]] = errors.New(fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason))
+			} else if result.Status >= 300 && result.Status != http.StatusNotFound {
+				errs[positions[j]] = errors.New(fmt.Sprintf("bulk status %d", result.Status))
+			}
+		}
+	}
+
+	return nil
+}
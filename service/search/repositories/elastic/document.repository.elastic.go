@@ -43,14 +43,14 @@ func (r *DocumentRepository) Create(c context.Context, doc *search.Document) err
 
 	index := r.DataSourceProvider.ProvideTable(c, doc.Index)
 
-	_, err = client.Update().
+	_, err = ifVersion(client.Update().
 		Index(index).
 		Type(doc.Type).
 		Id(doc.Fields["id"].(string)).
 		Doc(doc.Fields).
-		DocAsUpsert(true).
+		DocAsUpsert(true), doc).
 		Do(c)
-	return err
+	return versionConflict(err)
 }
 
 func (r *DocumentRepository) Upsert(c context.Context, doc *search.Document) error {
@@ -60,15 +60,15 @@ func (r *DocumentRepository) Upsert(c context.Context, doc *search.Document) err
 	}
 	index := r.DataSourceProvider.ProvideTable(c, doc.Index)
 
-	_, err = client.Update().
+	_, err = ifVersion(client.Update().
 		Index(index).
 		Type(doc.Type).
 		Id(doc.Fields["id"].(string)).
 		DocAsUpsert(true).
-		Doc(doc.Fields).
+		Doc(doc.Fields), doc).
 		Do(c)
 
-	return err
+	return versionConflict(err)
 }
 
 func (r *DocumentRepository) Update(c context.Context, doc *search.Document) error {
@@ -86,12 +86,27 @@ func (r *DocumentRepository) Update(c context.Context, doc *search.Document) err
 		return errors.New("update need id")
 	}
 
-	_, err = client.Update().
+	_, err = ifVersion(client.Update().
 		Index(index).
 		Type(doc.Type).
 		Id(id).
-		Doc(doc.Fields).
+		Doc(doc.Fields), doc).
 		Do(c)
+	return versionConflict(err)
+}
+
+// ifVersion 文档带有 seq_no 和 primary_term 时, 只有文档没有被修改才写入
+func ifVersion(update *elastic.UpdateService, doc *search.Document) *elastic.UpdateService {
+	if doc.PrimaryTerm == 0 {
+		return update
+	}
+	return update.IfSeqNo(doc.SeqNo).IfPrimaryTerm(doc.PrimaryTerm)
+}
+
+func versionConflict(err error) error {
+	if elastic.IsConflict(err) {
+		return search.ErrConflict
+	}
 	return err
 }
 
@@ -120,6 +135,10 @@ func (r *DocumentRepository) Get(c context.Context, index, typ, id string) (*sea
 		Index: index,
 		Type:  typ,
 	}
+	if res.SeqNo != nil && res.PrimaryTerm != nil {
+		doc.SeqNo = *res.SeqNo
+		doc.PrimaryTerm = *res.PrimaryTerm
+	}
 	err = json.Unmarshal(*res.Source, &doc.Fields)
 	log.Printf("DocumentRepository Get, index: %s, type: %s, id: %s, res: %v", index, typ, id, doc.Fields)
 
@@ -0,0 +1,48 @@
+package elastic
+
+import (
+	"context"
+
+	"github.com/duolacloud/microbase/domain/entity"
+	"github.com/duolacloud/microbase/domain/repository/elasticsearch"
+)
+
+func (r *DocumentRepository) Count(c context.Context, filter map[string]interface{}, index, typ string) (int64, error) {
+	client, err := r.client(c)
+	if err != nil {
+		return 0, err
+	}
+
+	querier := elasticsearch.NewQuerier(client)
+	return querier.Count(c, filter, r.DataSourceProvider.ProvideTable(c, index), typ)
+}
+
+func (r *DocumentRepository) UpdateByQuery(c context.Context, filter map[string]interface{}, fields map[string]interface{}, index, typ string, increments ...string) (int64, error) {
+	client, err := r.client(c)
+	if err != nil {
+		return 0, err
+	}
+
+	querier := elasticsearch.NewQuerier(client)
+	return querier.UpdateByQuery(c, filter, fields, r.DataSourceProvider.ProvideTable(c, index), typ, increments...)
+}
+
+func (r *DocumentRepository) DeleteByQuery(c context.Context, filter map[string]interface{}, index, typ string) (int64, error) {
+	client, err := r.client(c)
+	if err != nil {
+		return 0, err
+	}
+
+	querier := elasticsearch.NewQuerier(client)
+	return querier.DeleteByQuery(c, filter, r.DataSourceProvider.ProvideTable(c, index), typ)
+}
+
+func (r *DocumentRepository) Aggregate(c context.Context, query *entity.AggregateQuery, index, typ string) ([]*entity.AggregateBucket, error) {
+	client, err := r.client(c)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregator := elasticsearch.NewAggregator(client)
+	return aggregator.Aggregate(c, query, r.DataSourceProvider.ProvideTable(c, index), typ)
+}
@@ -31,6 +31,23 @@ type DocumentRepository interface {
 	// m	数据对象
 	Delete(c context.Context, index, typ, id string) error
 
+	// 批量写入和删除, 返回与入参一一对应的错误, BatchCreate 中已经存在的文档返回 search.ErrExists
+	BatchCreate(c context.Context, docs []*search.Document) ([]error, error)
+
+	BatchUpsert(c context.Context, docs []*search.Document) ([]error, error)
+
+	BatchDelete(c context.Context, keys []*search.DocumentKey) ([]error, error)
+
+	// 按过滤条件统计、批量更新和删除, 过滤条件和游标查询一致
+	Count(c context.Context, filter map[string]interface{}, index, typ string) (int64, error)
+
+	UpdateByQuery(c context.Context, filter map[string]interface{}, fields map[string]interface{}, index, typ string, increments ...string) (int64, error)
+
+	DeleteByQuery(c context.Context, filter map[string]interface{}, index, typ string) (int64, error)
+
+	// 分组统计
+	Aggregate(c context.Context, query *entity.AggregateQuery, index, typ string) ([]*entity.AggregateBucket, error)
+
 	// 游标查询
 	// @c	上下文
 	// @query	查询条件